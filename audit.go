@@ -0,0 +1,83 @@
+package password
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	EventResetRequested   = "ResetRequested"
+	EventResetCodeFailed  = "ResetCodeFailed"
+	EventResetCompleted   = "ResetCompleted"
+	EventChangeChallenged = "ChangeChallenged"
+	EventChangeFailed     = "ChangeFailed"
+	EventChangeCompleted  = "ChangeCompleted"
+	EventPolicyRejected   = "PolicyRejected"
+	EventChangeDenied     = "ChangeDenied"
+	EventLockedOut        = "LockedOut"
+	EventNoticeFailed     = "NoticeFailed"
+)
+
+type eventTypeKey struct{}
+
+// WithEventType adds the type of the event of the update of the password to the context, for the repositories which write the event in the same transaction, such as the outbox of the sql repository.
+func WithEventType(ctx context.Context, eventType string) context.Context {
+	return context.WithValue(ctx, eventTypeKey{}, eventType)
+}
+
+// GetEventType returns the type of the event of the context, or the default type.
+func GetEventType(ctx context.Context, defaultType string) string {
+	if eventType, ok := ctx.Value(eventTypeKey{}).(string); ok && len(eventType) > 0 {
+		return eventType
+	}
+	return defaultType
+}
+
+type AuditEvent struct {
+	Type      string    `mapstructure:"type" json:"type,omitempty" gorm:"column:type" bson:"type,omitempty" dynamodbav:"type,omitempty" firestore:"type,omitempty"`
+	UserId    string    `mapstructure:"user_id" json:"userId,omitempty" gorm:"column:userid" bson:"userId,omitempty" dynamodbav:"userId,omitempty" firestore:"userId,omitempty"`
+	Actor     string    `mapstructure:"actor" json:"actor,omitempty" gorm:"column:actor" bson:"actor,omitempty" dynamodbav:"actor,omitempty" firestore:"actor,omitempty"`
+	ClientIP  string    `mapstructure:"client_ip" json:"clientIp,omitempty" gorm:"column:clientip" bson:"clientIp,omitempty" dynamodbav:"clientIp,omitempty" firestore:"clientIp,omitempty"`
+	UserAgent string    `mapstructure:"user_agent" json:"userAgent,omitempty" gorm:"column:useragent" bson:"userAgent,omitempty" dynamodbav:"userAgent,omitempty" firestore:"userAgent,omitempty"`
+	Reason    string    `mapstructure:"reason" json:"reason,omitempty" gorm:"column:reason" bson:"reason,omitempty" dynamodbav:"reason,omitempty" firestore:"reason,omitempty"`
+	Time      time.Time `mapstructure:"time" json:"time" gorm:"column:time" bson:"time" dynamodbav:"time" firestore:"time"`
+}
+
+type AuditSink interface {
+	Write(ctx context.Context, event AuditEvent) error
+}
+
+// NewAuditEvent builds the event with the actor from the context key, or the user if there is no actor, and the client of the request.
+func NewAuditEvent(ctx context.Context, eventType string, key string, userId string, reason string) AuditEvent {
+	actor := getString(ctx, key)
+	if len(actor) == 0 {
+		actor = userId
+	}
+	ip, userAgent := GetClient(ctx)
+	return AuditEvent{Type: eventType, UserId: userId, Actor: actor, ClientIP: ip, UserAgent: userAgent, Reason: reason, Time: time.Now()}
+}
+
+func (s PasswordUseCase) audit(ctx context.Context, eventType string, userId string, reason string) {
+	if s.AuditSink == nil {
+		return
+	}
+	if err := s.AuditSink.Write(ctx, NewAuditEvent(ctx, eventType, s.Key, userId, reason)); err != nil {
+		log.Println(err)
+	}
+}
+
+func getString(ctx context.Context, key string) string {
+	if len(key) > 0 {
+		u := ctx.Value(key)
+		if u != nil {
+			s, ok := u.(string)
+			if ok {
+				return s
+			} else {
+				return ""
+			}
+		}
+	}
+	return ""
+}
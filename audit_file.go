@@ -0,0 +1,39 @@
+package password
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// FileAuditSink appends the events to a file in the JSON Lines format.
+type FileAuditSink struct {
+	File  *os.File
+	mutex sync.Mutex
+}
+
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, err
+	}
+	return &FileAuditSink{File: file}, nil
+}
+
+func (s *FileAuditSink) Write(ctx context.Context, event AuditEvent) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err = s.File.Write(append(b, '\n'))
+	return err
+}
+
+func (s *FileAuditSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.File.Close()
+}
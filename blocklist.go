@@ -0,0 +1,87 @@
+package password
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// Blocklist rejects the new passwords which are commonly used, expected or compromised.
+type Blocklist interface {
+	Contains(password string) bool
+}
+
+// CommonPasswords is a short list of the most common passwords, used by the presets; load a larger list by BlocklistFile.
+var CommonPasswords = []string{
+	"123456", "12345678", "123456789", "1234567890", "111111", "000000", "123123", "654321",
+	"password", "password1", "password123", "passw0rd", "qwerty", "qwerty123", "qwertyuiop", "abc123",
+	"iloveyou", "letmein", "welcome", "welcome1", "admin", "admin123", "monkey", "dragon",
+	"football", "baseball", "sunshine", "princess", "master", "login", "changeme", "secret",
+}
+
+// blocklists rejects the passwords which any of the blocklists contains.
+type blocklists []Blocklist
+
+func (b blocklists) Contains(password string) bool {
+	for _, blocklist := range b {
+		if blocklist.Contains(password) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinBlocklists returns the blocklist which contains the passwords of both blocklists; a nil blocklist is ignored.
+func joinBlocklists(a Blocklist, b Blocklist) Blocklist {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	return blocklists{a, b}
+}
+
+// WordBlocklist is a case-insensitive Blocklist of words.
+type WordBlocklist struct {
+	words map[string]bool
+}
+
+func NewBlocklist(words ...string) *WordBlocklist {
+	b := &WordBlocklist{words: make(map[string]bool)}
+	b.Add(words...)
+	return b
+}
+
+// LoadBlocklist loads the words of the file, one word per line; the empty lines and the lines starting with # are skipped.
+func LoadBlocklist(path string, words ...string) (*WordBlocklist, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	b := NewBlocklist(words...)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) > 0 && !strings.HasPrefix(line, "#") {
+			b.Add(line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *WordBlocklist) Add(words ...string) {
+	for _, word := range words {
+		if len(word) > 0 {
+			b.words[strings.ToLower(word)] = true
+		}
+	}
+}
+
+func (b *WordBlocklist) Contains(password string) bool {
+	return b.words[strings.ToLower(password)]
+}
@@ -17,6 +17,8 @@ type PasswordRepository struct {
 	PasswordTableName string
 	HistoryTableName  string
 	Key               string // User Id from context
+	TenantKey         string // Tenant Id from context
+	TenantName        string // the tenant column; when it is set, the rows are filtered by the tenant of the context
 	IdName            string
 	PasswordName      string
 	ToAddressName     string
@@ -57,6 +59,7 @@ func NewPasswordRepository(session *gocql.Session, userTableName, passwordTableN
 		ChangedByName:     strings.ToLower(changedByName),
 		HistoryName:       strings.ToLower(historyName),
 		TimestampName:     strings.ToLower(timestampName),
+		BuildParam:        buildParam,
 	}
 }
 
@@ -70,131 +73,79 @@ func NewDefaultPasswordRepository(session *gocql.Session, userTableName, passwor
 
 func (r *PasswordRepository) GetUserId(ctx context.Context, userName string) (string, error) {
 	var userId string
-	query := fmt.Sprintf("select %s from %s where %s = ? ALLOW FILTERING", r.IdName, r.UserTableName, r.Username)
-	session := r.Session
-	rows := session.Query(query, userName)
-	for _, _ = range rows.Iter().Columns() {
-		row := make(map[string]interface{})
-		if !rows.Iter().MapScan(row) {
-			return "", rows.Exec()
-		}
-		if id, ok := row[r.IdName]; ok {
-			userId = id.(string)
-		}
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName)
+	query := fmt.Sprintf("select %s from %s where %s = ?%s ALLOW FILTERING", r.IdName, r.UserTableName, r.Username, cond)
+	iter := r.Session.Query(query, append([]interface{}{userName}, tenant...)...).WithContext(ctx).Iter()
+	iter.Scan(&userId)
+	if err := iter.Close(); err != nil {
+		return "", err
 	}
-	defer session.Close()
 	return userId, nil
 }
 
 func (r *PasswordRepository) GetUser(ctx context.Context, userNameOrEmail string) (string, string, string, string, error) {
-	query1 := `SELECT * FROM %s WHERE %s = ? ALLOW FILTERING`
-	queryUserName := fmt.Sprintf(query1, r.UserTableName, r.Username)
-	rowsUserName := r.Session.Query(queryUserName, userNameOrEmail).Iter()
-	var userId string
-	var userName string
-	var email string
-	var password string
-	for {
-		// New map each iteration
+	var userId, userName, email, password string
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName)
+	query1 := `SELECT * FROM %s WHERE %s = ?%s ALLOW FILTERING`
+	for _, name := range []string{r.Username, r.ToAddressName} {
+		iter := r.Session.Query(fmt.Sprintf(query1, r.UserTableName, name, cond), append([]interface{}{userNameOrEmail}, tenant...)...).WithContext(ctx).Iter()
 		row := make(map[string]interface{})
-		if !rowsUserName.MapScan(row) {
-			break
-		} else {
-			if id, ok := row[r.IdName]; ok {
-				userId = id.(string)
-			}
-			if username, ok := row[r.Username]; ok {
-				userName = username.(string)
-			}
-			if emailRow, ok := row[r.ToAddressName]; ok {
-				email = emailRow.(string)
-			}
-			if password, ok := row[r.PasswordName]; ok {
-				password = password.(string)
-			}
+		found := iter.MapScan(row)
+		if err := iter.Close(); err != nil {
+			return "", "", "", "", err
 		}
-	}
-
-	queryEmail := fmt.Sprintf(query1, r.UserTableName, r.ToAddressName)
-	rowsEmail := r.Session.Query(queryEmail, userNameOrEmail).Iter()
-	for {
-		// New map each iteration
-		row1 := make(map[string]interface{})
-		if !rowsEmail.MapScan(row1) {
-			break
-		} else {
-			if id, ok := row1[r.IdName]; ok {
-				userId = id.(string)
-			}
-			if username, ok := row1[r.Username]; ok {
-				userName = username.(string)
-			}
-			if emailRow, ok := row1[r.ToAddressName]; ok {
-				email = emailRow.(string)
-			}
-			if password, ok := row1[r.PasswordName]; ok {
-				password = password.(string)
-			}
+		if !found {
+			continue
 		}
+		userId, _ = row[r.IdName].(string)
+		userName, _ = row[r.Username].(string)
+		email, _ = row[r.ToAddressName].(string)
+		password, _ = row[r.PasswordName].(string)
+		break
+	}
+	if len(userId) == 0 {
+		return "", "", "", "", nil
 	}
-	if password == "" {
-		query2 := `SELECT %s FROM %s WHERE %s = ? ALLOW FILTERING`
-		queryPassword := fmt.Sprintf(query2, r.PasswordName, r.PasswordTableName, r.IdName)
-		err2 := r.Session.Query(queryPassword, userId).Scan(&password)
-		if err2 != nil {
+	if len(password) == 0 && r.PasswordTableName != r.UserTableName {
+		query2 := `SELECT %s FROM %s WHERE %s = ?%s ALLOW FILTERING`
+		queryPassword := fmt.Sprintf(query2, r.PasswordName, r.PasswordTableName, r.IdName, cond)
+		err2 := r.Session.Query(queryPassword, append([]interface{}{userId}, tenant...)...).WithContext(ctx).Scan(&password)
+		if err2 != nil && err2 != gocql.ErrNotFound {
 			return "", "", "", "", err2
 		}
 	}
-	defer r.Session.Close()
 	return userId, userName, email, password, nil
 }
 
+// Update saves the password with an UPDATE, which inserts the row if there is none in Cassandra.
 func (r *PasswordRepository) Update(ctx context.Context, userId string, newPassword string) (int64, error) {
-	session := r.Session
-	pass := make(map[string]interface{})
-	pass[r.IdName] = userId
-	pass[r.PasswordName] = newPassword
-	if len(r.ChangedTimeName) > 0 {
-		pass[r.ChangedTimeName] = time.Now()
-	}
-	if len(r.FailCountName) > 0 {
-		pass[r.FailCountName] = 0
-	}
-	if len(r.ChangedByName) > 0 {
-		uid := getString(ctx, r.Key)
-		if len(uid) > 0 {
-			pass[r.ChangedByName] = uid
-		} else {
-			pass[r.ChangedByName] = userId
-		}
-	}
-	var count int
-	query := fmt.Sprintf("select count(*) from %s where %s = %s", r.PasswordTableName, r.IdName, "?")
-	err1 := session.Query(query, userId).Scan(&count)
-	if err1 != nil {
-		return 0, err1
-	}
-	if count > 0 {
-		query, values := BuildSave(pass, r.PasswordTableName, userId, r.IdName, r.BuildParam)
-		result := session.Query(query, values...)
-		if result.Exec() != nil {
-			return 0, result.Exec()
-		}
-		r0 := int64(result.Attempts())
-		return r0, nil
+	query, values := r.buildUpdate(ctx, userId, newPassword)
+	if err := r.Session.Query(query, values...).WithContext(ctx).Exec(); err != nil {
+		return 0, err
 	}
-
-	query1, values1 := BuildInsert1(pass, r.PasswordTableName, r.BuildParam)
-	result1 := session.Query(query1, values1...)
-	r1 := int64(result1.Attempts())
-	defer session.Close()
-	return r1, result1.Exec()
+	return 1, nil
 }
 
+// UpdateWithCurrentPassword saves the password and prepends the replaced one to the history, in a logged batch.
+// The history column is a list of a user-defined type with the fields PasswordName and TimestampName, such as
+// "create type passwordhistory (password text, timestamp timestamp)" and "history list<frozen<passwordhistory>>".
 func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, userId string, currentPassword, newPassword string) (int64, error) {
-	session := r.Session
+	query, values := r.buildUpdate(ctx, userId, newPassword)
+	batch := r.Session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	batch.Query(query, values...)
+	history := []map[string]interface{}{{r.PasswordName: currentPassword, r.TimestampName: time.Now()}}
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName)
+	queryHistory := fmt.Sprintf("UPDATE %s SET %s = ? + %s WHERE %s = ?%s", r.HistoryTableName, r.HistoryName, r.HistoryName, r.IdName, cond)
+	batch.Query(queryHistory, append([]interface{}{history, userId}, tenant...)...)
+	if err := r.Session.ExecuteBatch(batch); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// buildUpdate builds the update of the password, the changed time, the fail count and the user who changed it.
+func (r *PasswordRepository) buildUpdate(ctx context.Context, userId string, newPassword string) (string, []interface{}) {
 	pass := make(map[string]interface{})
-	pass[r.IdName] = userId
 	pass[r.PasswordName] = newPassword
 	if len(r.ChangedTimeName) > 0 {
 		pass[r.ChangedTimeName] = time.Now()
@@ -210,80 +161,43 @@ func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, user
 			pass[r.ChangedByName] = userId
 		}
 	}
-	history := make(map[string]interface{})
-	history[r.IdName] = userId
-	history[r.PasswordName] = currentPassword
-	history[r.TimestampName] = time.Now().Format("2006-01-02 15:04:05")
-	if r.HistoryTableName == r.PasswordTableName {
-		queryHistoryTable := fmt.Sprintf("UPDATE %s SET %s = '%s' , %s = %s + [('%s', '%s')] WHERE %s = ?", r.HistoryTableName, r.PasswordName, newPassword, r.HistoryName, r.HistoryName, history[r.PasswordName], history[r.TimestampName], r.IdName)
-		result2 := session.Query(queryHistoryTable, userId)
-		if result2.Exec() != nil {
-			return 0, result2.Exec()
-		}
-		r2 := result2.Latency()
-		return r2, nil
-	} else {
-		queryHistoryTable := fmt.Sprintf("UPDATE %s SET %s = %s + [('%s', '%s')] WHERE %s = ?", r.HistoryTableName, r.HistoryName, r.HistoryName, history[r.PasswordName], history[r.TimestampName], r.IdName)
-		result2 := session.Query(queryHistoryTable, userId)
-		if result2.Exec() != nil {
-			return 0, result2.Exec()
-		}
-		queryPasswordTable := fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s = ?", r.PasswordTableName, r.PasswordName, newPassword, r.IdName)
-		result3 := session.Query(queryPasswordTable, userId)
-		if result3.Exec() != nil {
-			queryDelete := fmt.Sprintf("UPDATE %s SET %s = %s - [('%s', '%s')] WHERE %s = ?", r.HistoryTableName, r.HistoryName, r.HistoryName, history[r.PasswordName], history[r.TimestampName], r.IdName)
-			result4 := session.Query(queryDelete, userId)
-			if result4.Exec() != nil {
-				return 0, result4.Exec()
-			}
-			return 0, result3.Exec()
-		}
-		r3 := result3.Latency()
-		return r3, nil
-	}
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName)
+	query, values := BuildSave(pass, r.PasswordTableName, userId, r.IdName, r.BuildParam)
+	return query + cond, append(values, tenant...)
 }
 
+// GetHistory returns the replaced passwords, the latest first, at most max.
 func (r *PasswordRepository) GetHistory(ctx context.Context, userId string, max int) ([]string, error) {
 	history := make([]string, 0)
-
-	// arr := make(map[string]interface{})
-	session := r.Session
-	query := `SELECT %s FROM %s WHERE %s = %s`
-	query = fmt.Sprintf(query, r.HistoryName, r.HistoryTableName, r.IdName, "?")
-	rows := session.Query(query, userId)
-	col := rows.Iter().Columns()
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName)
+	query := `SELECT %s FROM %s WHERE %s = ?%s`
+	query = fmt.Sprintf(query, r.HistoryName, r.HistoryTableName, r.IdName, cond)
+	var rows []map[string]interface{}
+	iter := r.Session.Query(query, append([]interface{}{userId}, tenant...)...).WithContext(ctx).Iter()
+	iter.Scan(&rows)
+	if err := iter.Close(); err != nil {
+		return history, err
+	}
 	type historyStruct struct {
 		password  string
 		timestamp time.Time
 	}
-	row := make(map[string]interface{})
-	date_sorted_reviews := make([]historyStruct, 0)
-	for _, _ = range col {
-		if !rows.Iter().MapScan(row) {
-			break
-		}
-	}
-	for _, v := range row[r.HistoryName].([]map[string]interface{}) {
-		var tam historyStruct
-		for k1, v1 := range v {
-			if k1 == "password" {
-				tam.password = v1.(string)
-			}
-			if k1 == "timestamp" {
-				tam.timestamp = v1.(time.Time)
-			}
-		}
-		date_sorted_reviews = append(date_sorted_reviews, tam)
+	sorted := make([]historyStruct, 0)
+	for _, v := range rows {
+		var h historyStruct
+		h.password, _ = v[r.PasswordName].(string)
+		h.timestamp, _ = v[r.TimestampName].(time.Time)
+		sorted = append(sorted, h)
 	}
-	defer rows.Iter().Close()
-	sort.Slice(date_sorted_reviews, func(i, j int) bool {
-		return date_sorted_reviews[i].timestamp.After(date_sorted_reviews[j].timestamp)
+	// the list is kept the latest first; the sort keeps the histories which were appended by the former versions in the same order
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].timestamp.After(sorted[j].timestamp)
 	})
-	for k, v := range date_sorted_reviews {
-		history = append(history, v.password)
-		if k >= 5 {
+	for _, v := range sorted {
+		if max > 0 && len(history) >= max {
 			break
 		}
+		history = append(history, v.password)
 	}
 	return history, nil
 }
@@ -354,3 +268,7 @@ func buildParametersFrom(i int, numCol int, buildParam func(int) string) string
 	}
 	return strings.Join(arrValue, ",")
 }
+
+func buildParam(i int) string {
+	return "?"
+}
@@ -0,0 +1,77 @@
+//go:build integration
+
+package cassandra
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/testkit"
+	"github.com/gocql/gocql"
+)
+
+// openSession connects to CASSANDRA_HOSTS, creates the keyspace and the tables of the test, and truncates them before each case.
+// Run with: CASSANDRA_HOSTS=localhost go test -tags integration ./cassandra
+func openSession(t *testing.T) *gocql.Session {
+	hosts := os.Getenv("CASSANDRA_HOSTS")
+	if len(hosts) == 0 {
+		t.Skip("CASSANDRA_HOSTS is not set")
+	}
+	cluster := gocql.NewCluster(strings.Split(hosts, ",")...)
+	session, err := cluster.CreateSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(session.Close)
+	for _, stmt := range []string{
+		"create keyspace if not exists password_test with replication = {'class': 'SimpleStrategy', 'replication_factor': 1}",
+		"create type if not exists password_test.passwordhistory (password text, timestamp timestamp)",
+		"create table if not exists password_test.users (userid text primary key, username text, email text)",
+		"create table if not exists password_test.passwords (userid text primary key, password text, failcount int, changedby text, changedtime timestamp, history list<frozen<passwordhistory>>)",
+		"create table if not exists password_test.codes (id text primary key, passcode text, expiredat timestamp)",
+		"truncate password_test.users",
+		"truncate password_test.passwords",
+		"truncate password_test.codes",
+	} {
+		if err := session.Query(stmt).Exec(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return session
+}
+
+func TestPasswordRepositoryContract(t *testing.T) {
+	testkit.RunPasswordRepositoryContract(t, testkit.PasswordRepositoryContract{
+		Key: "userId",
+		New: func(t *testing.T, users []testkit.User) p.PasswordRepository {
+			session := openSession(t)
+			for _, u := range users {
+				if err := session.Query("insert into password_test.users (userid, username, email) values (?, ?, ?)", u.Id, u.Username, u.Email).Exec(); err != nil {
+					t.Fatal(err)
+				}
+				if len(u.Password) > 0 {
+					if err := session.Query("insert into password_test.passwords (userid, password, failcount) values (?, ?, ?)", u.Id, u.Password, u.FailCount).Exec(); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			return NewPasswordRepository(session, "password_test.users", "password_test.passwords", "password_test.passwords", "userId", "userid", "password", "email", "username", "changedtime", "failcount", "changedby", "history", "timestamp")
+		},
+		Inspect: func(t *testing.T, repository p.PasswordRepository, id string) (string, int) {
+			var changedBy string
+			var failCount int
+			if err := repository.(*PasswordRepository).Session.Query("select changedby, failcount from password_test.passwords where userid = ?", id).Scan(&changedBy, &failCount); err != nil {
+				t.Fatal(err)
+			}
+			return changedBy, failCount
+		},
+	})
+}
+
+func TestVerificationCodeRepositoryContract(t *testing.T) {
+	testkit.RunVerificationCodeRepositoryContract(t, func(t *testing.T) p.VerificationCodeRepository {
+		return NewDefaultVerificationCodeRepository(openSession(t), "password_test.codes")
+	})
+}
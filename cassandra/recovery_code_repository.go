@@ -0,0 +1,83 @@
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gocql/gocql"
+)
+
+type RecoveryCodeRepository struct {
+	Session    *gocql.Session
+	TableName  string
+	IdName     string
+	CodeName   string
+	TenantKey  string // Tenant Id from context
+	TenantName string // the tenant column, in the primary key; when it is set, the codes are kept by the tenant of the context
+}
+
+func NewDefaultRecoveryCodeRepository(session *gocql.Session, tableName string) *RecoveryCodeRepository {
+	return NewRecoveryCodeRepository(session, tableName, "userid", "code")
+}
+
+// NewRecoveryCodeRepository expects a table with the primary key (idName, codeName).
+func NewRecoveryCodeRepository(session *gocql.Session, tableName, idName, codeName string) *RecoveryCodeRepository {
+	if len(idName) == 0 {
+		idName = "userid"
+	}
+	if len(codeName) == 0 {
+		codeName = "code"
+	}
+	return &RecoveryCodeRepository{
+		Session:   session,
+		TableName: strings.ToLower(tableName),
+		IdName:    strings.ToLower(idName),
+		CodeName:  strings.ToLower(codeName),
+	}
+}
+
+func (r *RecoveryCodeRepository) Save(ctx context.Context, id string, codes []string) (int64, error) {
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName)
+	query := fmt.Sprintf("delete from %s where %s = ?%s", r.TableName, r.IdName, cond)
+	if err := r.Session.Query(query, append([]interface{}{id}, tenant...)...).WithContext(ctx).Exec(); err != nil {
+		return 0, err
+	}
+	if len(codes) == 0 {
+		return 0, nil
+	}
+	batch := r.Session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	insert := fmt.Sprintf("insert into %s (%s, %s) values (?, ?)", r.TableName, r.IdName, r.CodeName)
+	if len(r.TenantName) > 0 {
+		insert = fmt.Sprintf("insert into %s (%s, %s, %s) values (?, ?, ?)", r.TableName, r.IdName, r.CodeName, r.TenantName)
+	}
+	for _, code := range codes {
+		batch.Query(insert, append([]interface{}{id, code}, tenant...)...)
+	}
+	if err := r.Session.ExecuteBatch(batch); err != nil {
+		return 0, err
+	}
+	return int64(len(codes)), nil
+}
+
+func (r *RecoveryCodeRepository) Load(ctx context.Context, id string) ([]string, error) {
+	codes := make([]string, 0)
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName)
+	query := fmt.Sprintf("select %s from %s where %s = ?%s", r.CodeName, r.TableName, r.IdName, cond)
+	iter := r.Session.Query(query, append([]interface{}{id}, tenant...)...).WithContext(ctx).Iter()
+	var code string
+	for iter.Scan(&code) {
+		codes = append(codes, code)
+	}
+	return codes, iter.Close()
+}
+
+func (r *RecoveryCodeRepository) Delete(ctx context.Context, id string, code string) (int64, error) {
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName)
+	query := fmt.Sprintf("delete from %s where %s = ? and %s = ?%s if exists", r.TableName, r.IdName, r.CodeName, cond)
+	applied, err := r.Session.Query(query, append([]interface{}{id, code}, tenant...)...).WithContext(ctx).MapScanCAS(make(map[string]interface{}))
+	if err != nil || !applied {
+		return 0, err
+	}
+	return 1, nil
+}
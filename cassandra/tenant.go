@@ -0,0 +1,15 @@
+package cassandra
+
+import (
+	"context"
+	"fmt"
+)
+
+// tenantCondition returns the condition of the tenant column and the tenant of the context.
+// It returns an empty condition when tenantName is empty, which means the table is not multi-tenant.
+func tenantCondition(ctx context.Context, tenantKey string, tenantName string) (string, []interface{}) {
+	if len(tenantName) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf(" and %s = ?", tenantName), []interface{}{getString(ctx, tenantKey)}
+}
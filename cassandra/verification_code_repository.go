@@ -0,0 +1,89 @@
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	p "github.com/core-go/password"
+	"github.com/gocql/gocql"
+)
+
+type VerificationCodeRepository struct {
+	Session       *gocql.Session
+	TableName     string
+	IdName        string
+	PasscodeName  string
+	ExpiredAtName string
+	TenantKey     string // Tenant Id from context
+	TenantName    string // the tenant column, in the primary key; when it is set, the codes are kept by the tenant of the context
+}
+
+func NewVerificationCodeRepositoryByConfig(session *gocql.Session, tableName string, c p.VerificationCodeSchemaConfig) *VerificationCodeRepository {
+	return NewVerificationCodeRepository(session, tableName, c.Id, c.Passcode, c.ExpiredAt)
+}
+
+func NewDefaultVerificationCodeRepository(session *gocql.Session, tableName string) *VerificationCodeRepository {
+	return NewVerificationCodeRepository(session, tableName, "id", "passcode", "expiredat")
+}
+
+// NewVerificationCodeRepository expects a table with the primary key idName and a timestamp column expiredAtName.
+func NewVerificationCodeRepository(session *gocql.Session, tableName, idName, passcodeName, expiredAtName string) *VerificationCodeRepository {
+	if len(idName) == 0 {
+		idName = "id"
+	}
+	if len(passcodeName) == 0 {
+		passcodeName = "passcode"
+	}
+	if len(expiredAtName) == 0 {
+		expiredAtName = "expiredat"
+	}
+	return &VerificationCodeRepository{
+		Session:       session,
+		TableName:     strings.ToLower(tableName),
+		IdName:        strings.ToLower(idName),
+		PasscodeName:  strings.ToLower(passcodeName),
+		ExpiredAtName: strings.ToLower(expiredAtName),
+	}
+}
+
+// Save inserts the code with a TTL, so that Cassandra removes the row when the code expires.
+func (r *VerificationCodeRepository) Save(ctx context.Context, id string, passcode string, expireAt time.Time) (int64, error) {
+	ttl := int(time.Until(expireAt).Seconds())
+	if ttl < 1 {
+		ttl = 1
+	}
+	query := fmt.Sprintf("insert into %s (%s, %s, %s) values (?, ?, ?) using ttl ?", r.TableName, r.IdName, r.PasscodeName, r.ExpiredAtName)
+	values := []interface{}{id, passcode, expireAt, ttl}
+	if len(r.TenantName) > 0 {
+		query = fmt.Sprintf("insert into %s (%s, %s, %s, %s) values (?, ?, ?, ?) using ttl ?", r.TableName, r.IdName, r.PasscodeName, r.ExpiredAtName, r.TenantName)
+		values = []interface{}{id, passcode, expireAt, getString(ctx, r.TenantKey), ttl}
+	}
+	if err := r.Session.Query(query, values...).WithContext(ctx).Exec(); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (r *VerificationCodeRepository) Load(ctx context.Context, id string) (string, time.Time, error) {
+	var passcode string
+	var expiredAt time.Time
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName)
+	query := fmt.Sprintf("select %s, %s from %s where %s = ?%s", r.PasscodeName, r.ExpiredAtName, r.TableName, r.IdName, cond)
+	err := r.Session.Query(query, append([]interface{}{id}, tenant...)...).WithContext(ctx).Scan(&passcode, &expiredAt)
+	if err == gocql.ErrNotFound {
+		return "", time.Time{}, nil
+	}
+	return passcode, expiredAt, err
+}
+
+func (r *VerificationCodeRepository) Delete(ctx context.Context, id string) (int64, error) {
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName)
+	query := fmt.Sprintf("delete from %s where %s = ?%s if exists", r.TableName, r.IdName, cond)
+	applied, err := r.Session.Query(query, append([]interface{}{id}, tenant...)...).WithContext(ctx).MapScanCAS(make(map[string]interface{}))
+	if err != nil || !applied {
+		return 0, err
+	}
+	return 1, nil
+}
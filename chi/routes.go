@@ -0,0 +1,16 @@
+package chi
+
+import (
+	p "github.com/core-go/password"
+	"github.com/go-chi/chi/v5"
+)
+
+// RegisterRoutes registers the actions of the net/http handler under the prefix, such as "/password":
+// POST change, GET forgot/{contact}, POST forgot, POST reset and POST deny.
+func RegisterRoutes(r chi.Router, prefix string, h *p.PasswordHandler) {
+	r.Post(prefix+"/change", h.ChangePassword)
+	r.Get(prefix+"/forgot/{contact}", h.ForgotPassword)
+	r.Post(prefix+"/forgot", h.ForgotPassword)
+	r.Post(prefix+"/reset", h.ResetPassword)
+	r.Post(prefix+"/deny", h.DenyChange)
+}
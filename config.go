@@ -10,4 +10,5 @@ type PasswordConfig struct {
 	Exp5          string               `mapstructure:"exp5" json:"exp5,omitempty" gorm:"column:exp5" bson:"exp5,omitempty" dynamodbav:"exp5,omitempty" firestore:"exp5,omitempty"`
 	Exp6          string               `mapstructure:"exp6" json:"exp6,omitempty" gorm:"column:exp6" bson:"exp6,omitempty" dynamodbav:"exp6,omitempty" firestore:"exp6,omitempty"`
 	Schema        PasswordSchemaConfig `mapstructure:"schema" json:"schema,omitempty" gorm:"column:schema" bson:"schema,omitempty" dynamodbav:"schema,omitempty" firestore:"schema,omitempty"`
+	Policy        PolicyConfig         `mapstructure:"policy" json:"policy,omitempty" gorm:"column:policy" bson:"policy,omitempty" dynamodbav:"policy,omitempty" firestore:"policy,omitempty"`
 }
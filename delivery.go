@@ -0,0 +1,166 @@
+package password
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelVoice = "voice"
+	ChannelPush  = "push"
+
+	PurposeReset  = "reset"
+	PurposeChange = "change"
+	PurposeNotice = "notice" // the notice that the password was changed, with the "this wasn't me" link
+)
+
+type DeliveryMessage struct {
+	UserId      string    `mapstructure:"user_id" json:"userId,omitempty" gorm:"column:userid" bson:"userId,omitempty" dynamodbav:"userId,omitempty" firestore:"userId,omitempty"`
+	Username    string    `mapstructure:"username" json:"username,omitempty" gorm:"column:username" bson:"username,omitempty" dynamodbav:"username,omitempty" firestore:"username,omitempty"`
+	Channel     string    `mapstructure:"channel" json:"channel,omitempty" gorm:"column:channel" bson:"channel,omitempty" dynamodbav:"channel,omitempty" firestore:"channel,omitempty"`
+	Destination string    `mapstructure:"destination" json:"destination,omitempty" gorm:"column:destination" bson:"destination,omitempty" dynamodbav:"destination,omitempty" firestore:"destination,omitempty"`
+	Code        string    `mapstructure:"code" json:"code,omitempty" gorm:"column:code" bson:"code,omitempty" dynamodbav:"code,omitempty" firestore:"code,omitempty"`
+	ExpireAt    time.Time `mapstructure:"expire_at" json:"expireAt,omitempty" gorm:"column:expireat" bson:"expireAt,omitempty" dynamodbav:"expireAt,omitempty" firestore:"expireAt,omitempty"`
+	Locale      string    `mapstructure:"locale" json:"locale,omitempty" gorm:"column:locale" bson:"locale,omitempty" dynamodbav:"locale,omitempty" firestore:"locale,omitempty"`
+	Purpose     string    `mapstructure:"purpose" json:"purpose,omitempty" gorm:"column:purpose" bson:"purpose,omitempty" dynamodbav:"purpose,omitempty" firestore:"purpose,omitempty"`
+	Link        string    `mapstructure:"link" json:"link,omitempty" gorm:"column:link" bson:"link,omitempty" dynamodbav:"link,omitempty" firestore:"link,omitempty"`
+}
+
+type Contact struct {
+	Channel     string `mapstructure:"channel" json:"channel,omitempty" gorm:"column:channel" bson:"channel,omitempty" dynamodbav:"channel,omitempty" firestore:"channel,omitempty"`
+	Destination string `mapstructure:"destination" json:"destination,omitempty" gorm:"column:destination" bson:"destination,omitempty" dynamodbav:"destination,omitempty" firestore:"destination,omitempty"`
+}
+
+type DeliveryChannel interface {
+	Send(ctx context.Context, message DeliveryMessage) error
+}
+
+// Deliverer sends the message, and returns the message which was actually sent, with the channel and the destination used.
+type Deliverer interface {
+	Deliver(ctx context.Context, message DeliveryMessage) (DeliveryMessage, error)
+}
+
+type strictChannelKey struct{}
+
+// WithStrictChannel asks the Deliverer to send the message by its channel only, without falling back to the other channels of the user,
+// such as for the code of the factor which the user selected for a change.
+func WithStrictChannel(ctx context.Context) context.Context {
+	return context.WithValue(ctx, strictChannelKey{}, true)
+}
+
+// IsStrictChannel returns true if the message must be sent by its channel only.
+func IsStrictChannel(ctx context.Context) bool {
+	strict, _ := ctx.Value(strictChannelKey{}).(bool)
+	return strict
+}
+
+type DeliveryRouter struct {
+	Channels map[string]DeliveryChannel
+	// Order is the default preference of the channels, when Preferences is nil or returns nothing.
+	Order []string
+	// Contacts returns the destinations of the user, such as the phone number for SMS and the device token for push.
+	Contacts    func(ctx context.Context, userId string) ([]Contact, error)
+	Preferences func(ctx context.Context, userId string) ([]string, error)
+}
+
+func NewDeliveryRouter(channels map[string]DeliveryChannel, contacts func(context.Context, string) ([]Contact, error), order ...string) *DeliveryRouter {
+	if len(order) == 0 {
+		order = []string{ChannelEmail, ChannelSMS, ChannelPush, ChannelVoice}
+	}
+	return &DeliveryRouter{Channels: channels, Contacts: contacts, Order: order}
+}
+
+// Deliver tries the requested channel first, then the other contacts of the user by preference, until one of the channels succeeds.
+// A message with a destination but no channel is treated as an email from the user record. With WithStrictChannel, only the requested channel is tried.
+func (r *DeliveryRouter) Deliver(ctx context.Context, message DeliveryMessage) (DeliveryMessage, error) {
+	candidates, err := r.candidates(ctx, message)
+	if err != nil {
+		return message, err
+	}
+	errs := make([]string, 0)
+	for _, contact := range candidates {
+		channel, ok := r.Channels[contact.Channel]
+		if !ok || channel == nil {
+			continue
+		}
+		m := message
+		m.Channel = contact.Channel
+		m.Destination = contact.Destination
+		if er1 := channel.Send(ctx, m); er1 != nil {
+			errs = append(errs, contact.Channel+": "+er1.Error())
+			continue
+		}
+		return m, nil
+	}
+	if len(errs) == 0 {
+		return message, fmt.Errorf("no channel to deliver the %s code to user %s", message.Purpose, message.UserId)
+	}
+	return message, fmt.Errorf("cannot deliver the %s code to user %s: %s", message.Purpose, message.UserId, strings.Join(errs, "; "))
+}
+
+func (r *DeliveryRouter) candidates(ctx context.Context, message DeliveryMessage) ([]Contact, error) {
+	contacts := make([]Contact, 0)
+	if r.Contacts != nil {
+		c, err := r.Contacts(ctx, message.UserId)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, c...)
+	}
+	if len(message.Channel) == 0 && len(message.Destination) > 0 {
+		contacts = append(contacts, Contact{Channel: ChannelEmail, Destination: message.Destination})
+	}
+	order := r.Order
+	if r.Preferences != nil {
+		preferences, err := r.Preferences(ctx, message.UserId)
+		if err != nil {
+			return nil, err
+		}
+		if len(preferences) > 0 {
+			order = preferences
+		}
+	}
+	candidates := make([]Contact, 0)
+	if len(message.Channel) > 0 {
+		if len(message.Destination) > 0 {
+			candidates = append(candidates, Contact{Channel: message.Channel, Destination: message.Destination})
+		} else {
+			candidates = append(candidates, findContacts(contacts, message.Channel)...)
+		}
+		if IsStrictChannel(ctx) {
+			return distinctContacts(candidates), nil
+		}
+	}
+	for _, channel := range order {
+		candidates = append(candidates, findContacts(contacts, channel)...)
+	}
+	candidates = append(candidates, contacts...)
+	return distinctContacts(candidates), nil
+}
+
+func findContacts(contacts []Contact, channel string) []Contact {
+	result := make([]Contact, 0)
+	for _, contact := range contacts {
+		if contact.Channel == channel {
+			result = append(result, contact)
+		}
+	}
+	return result
+}
+
+func distinctContacts(contacts []Contact) []Contact {
+	result := make([]Contact, 0)
+	seen := make(map[Contact]bool)
+	for _, contact := range contacts {
+		if len(contact.Destination) == 0 || seen[contact] {
+			continue
+		}
+		seen[contact] = true
+		result = append(result, contact)
+	}
+	return result
+}
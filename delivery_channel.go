@@ -0,0 +1,87 @@
+package password
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// SendCodeChannel adapts a legacy SendResetCode or SendChangeCode function, which receives the destination as params.
+type SendCodeChannel func(ctx context.Context, to string, code string, expireAt time.Time, params interface{}) error
+
+func (f SendCodeChannel) Send(ctx context.Context, message DeliveryMessage) error {
+	return f(ctx, message.Username, message.Code, message.ExpireAt, message.Destination)
+}
+
+// MemoryChannel keeps the sent messages in memory, for testing and local development.
+// When Error is set, Send fails with it, to test the fallback to the other channels.
+type MemoryChannel struct {
+	Error    error
+	mutex    sync.Mutex
+	messages []DeliveryMessage
+}
+
+func NewMemoryChannel() *MemoryChannel {
+	return &MemoryChannel{}
+}
+
+func (c *MemoryChannel) Send(ctx context.Context, message DeliveryMessage) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.Error != nil {
+		return c.Error
+	}
+	c.messages = append(c.messages, message)
+	return nil
+}
+
+func (c *MemoryChannel) Messages() []DeliveryMessage {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	messages := make([]DeliveryMessage, len(c.messages))
+	copy(messages, c.messages)
+	return messages
+}
+
+// Last returns the last message sent to the destination, to read the code in tests.
+func (c *MemoryChannel) Last(destination string) (DeliveryMessage, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for i := len(c.messages) - 1; i >= 0; i-- {
+		if c.messages[i].Destination == destination {
+			return c.messages[i], true
+		}
+	}
+	return DeliveryMessage{}, false
+}
+
+func (c *MemoryChannel) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.messages = nil
+}
+
+// LogChannel writes the messages, including the codes, to the log. It must be used for local development only.
+type LogChannel struct {
+	Logger *log.Logger
+}
+
+func NewLogChannel(options ...*log.Logger) *LogChannel {
+	var logger *log.Logger
+	if len(options) >= 1 {
+		logger = options[0]
+	}
+	return &LogChannel{Logger: logger}
+}
+
+func (c *LogChannel) Send(ctx context.Context, message DeliveryMessage) error {
+	format := "%s code for %s via %s to %s: %s (expires at %s)"
+	args := []interface{}{message.Purpose, message.Username, message.Channel, message.Destination, message.Code, message.ExpireAt.Format(time.RFC3339)}
+	if c.Logger != nil {
+		c.Logger.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+	return nil
+}
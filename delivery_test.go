@@ -0,0 +1,155 @@
+package password_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	p "github.com/core-go/password"
+)
+
+func TestDeliveryRouter(t *testing.T) {
+	contacts := func(ctx context.Context, userId string) ([]p.Contact, error) {
+		return []p.Contact{{Channel: p.ChannelSMS, Destination: "+15551234567"}, {Channel: p.ChannelPush, Destination: "device-1"}}, nil
+	}
+	tests := []struct {
+		name        string
+		message     p.DeliveryMessage
+		failing     []string
+		preferences []string
+		strict      bool
+		channel     string
+		destination string
+		err         string
+	}{
+		{"EmailOfUserRecord", p.DeliveryMessage{Destination: "alice@example.com"}, nil, nil, false, p.ChannelEmail, "alice@example.com", ""},
+		{"FallbackToSMS", p.DeliveryMessage{Destination: "alice@example.com"}, []string{p.ChannelEmail}, nil, false, p.ChannelSMS, "+15551234567", ""},
+		{"FallbackToPush", p.DeliveryMessage{Destination: "alice@example.com"}, []string{p.ChannelEmail, p.ChannelSMS}, nil, false, p.ChannelPush, "device-1", ""},
+		{"RequestedChannel", p.DeliveryMessage{Channel: p.ChannelSMS}, nil, nil, false, p.ChannelSMS, "+15551234567", ""},
+		{"RequestedDestination", p.DeliveryMessage{Channel: p.ChannelSMS, Destination: "+15550000000"}, nil, nil, false, p.ChannelSMS, "+15550000000", ""},
+		{"Preferences", p.DeliveryMessage{Destination: "alice@example.com"}, nil, []string{p.ChannelPush}, false, p.ChannelPush, "device-1", ""},
+		{"Strict", p.DeliveryMessage{Channel: p.ChannelSMS}, []string{p.ChannelSMS}, nil, true, "", "", "sms: unavailable"},
+		{"AllFail", p.DeliveryMessage{Destination: "alice@example.com"}, []string{p.ChannelEmail, p.ChannelSMS, p.ChannelPush}, nil, false, "", "", "email: unavailable; sms: unavailable; push: unavailable"},
+		{"NoChannel", p.DeliveryMessage{Channel: p.ChannelVoice}, nil, nil, true, "", "", "no channel"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channels := map[string]*p.MemoryChannel{p.ChannelEmail: p.NewMemoryChannel(), p.ChannelSMS: p.NewMemoryChannel(), p.ChannelPush: p.NewMemoryChannel()}
+			for _, channel := range tt.failing {
+				channels[channel].Error = errors.New("unavailable")
+			}
+			router := p.NewDeliveryRouter(map[string]p.DeliveryChannel{p.ChannelEmail: channels[p.ChannelEmail], p.ChannelSMS: channels[p.ChannelSMS], p.ChannelPush: channels[p.ChannelPush]}, contacts)
+			if tt.preferences != nil {
+				router.Preferences = func(ctx context.Context, userId string) ([]string, error) {
+					return tt.preferences, nil
+				}
+			}
+			ctx := context.Background()
+			if tt.strict {
+				ctx = p.WithStrictChannel(ctx)
+			}
+			message := tt.message
+			message.UserId, message.Code, message.Purpose = "u1", "123456", p.PurposeReset
+			sent, err := router.Deliver(ctx, message)
+			if len(tt.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Deliver() error = %v; want %q", err, tt.err)
+				}
+			} else if err != nil || sent.Channel != tt.channel || sent.Destination != tt.destination {
+				t.Fatalf("Deliver() = %s %s, %v; want %s %s", sent.Channel, sent.Destination, err, tt.channel, tt.destination)
+			}
+			for name, channel := range channels {
+				want := 0
+				if name == tt.channel {
+					want = 1
+				}
+				if messages := channel.Messages(); len(messages) != want {
+					t.Errorf("%s: %d messages; want %d", name, len(messages), want)
+				} else if want == 1 && messages[0].Code != "123456" {
+					t.Errorf("%s: code = %q; want 123456", name, messages[0].Code)
+				}
+			}
+		})
+	}
+}
+
+func TestSendCodeChannel(t *testing.T) {
+	box := &outbox{}
+	if err := p.SendCodeChannel(box.send).Send(context.Background(), p.DeliveryMessage{Username: "alice", Destination: "alice@example.com", Code: "123456"}); err != nil {
+		t.Fatal(err)
+	}
+	if sent := box.codes(); len(sent) != 1 || sent[0].to != "alice" || sent[0].params != "alice@example.com" {
+		t.Errorf("sent %v; want the code to alice with the destination as params", sent)
+	}
+}
+
+// TestChallengeStrictChannel checks that the code of the selected factor is not sent by another channel when its channel fails,
+// while the reset code falls back to the other channels.
+func TestChallengeStrictChannel(t *testing.T) {
+	ctx := context.Background()
+	email, sms := p.NewMemoryChannel(), p.NewMemoryChannel()
+	sms.Error = errors.New("unavailable")
+	router := p.NewDeliveryRouter(map[string]p.DeliveryChannel{p.ChannelEmail: email, p.ChannelSMS: sms}, func(ctx context.Context, userId string) ([]p.Contact, error) {
+		return []p.Contact{{Channel: p.ChannelSMS, Destination: "+15551234567"}, {Channel: p.ChannelEmail, Destination: "alice@example.com"}}, nil
+	}, p.ChannelSMS, p.ChannelEmail)
+	changeCodes := newCodes()
+	s := p.PasswordUseCase{
+		PasswordComparator:       plain{},
+		PasswordRepository:       newUsers(user{id: "u1", username: "alice", email: "alice@example.com", password: "h:p0"}),
+		PasswordResetExpires:     600,
+		ResetPasscodeRepository:  newCodes(),
+		PasswordChangeExpires:    300,
+		ChangePasscodeRepository: changeCodes,
+		Delivery:                 router,
+		ResolveFactors: func(ctx context.Context, id string) ([]p.Factor, error) {
+			return []p.Factor{{Type: p.FactorSMS, Destination: "+15551234567"}, {Type: p.FactorEmail, Destination: "alice@example.com"}}, nil
+		},
+	}
+	result, err := s.ChangePasswordWithResult(ctx, p.PasswordChange{Username: "alice", CurrentPassword: "p0", Password: "p1", Factor: p.FactorSMS})
+	if err == nil || result.Status != 0 {
+		t.Fatalf("ChangePasswordWithResult() = %+v, %v; want an error", result, err)
+	}
+	if messages := email.Messages(); len(messages) != 0 {
+		t.Errorf("email messages = %v; want no fallback for the code of the factor", messages)
+	}
+	if !changeCodes.deleted("u1") {
+		t.Error("the change code is kept; want it deleted when it cannot be sent")
+	}
+
+	if sent, err := s.ForgotPassword(ctx, "alice@example.com"); !sent || err != nil {
+		t.Fatalf("ForgotPassword() = %v, %v", sent, err)
+	}
+	if _, ok := email.Last("alice@example.com"); !ok {
+		t.Error("the reset code is not sent by email; want the fallback from sms")
+	}
+}
+
+// emailDeliverer ignores WithStrictChannel, and always delivers by email.
+type emailDeliverer struct{}
+
+func (emailDeliverer) Deliver(ctx context.Context, message p.DeliveryMessage) (p.DeliveryMessage, error) {
+	message.Channel, message.Destination = p.ChannelEmail, "alice@example.com"
+	return message, nil
+}
+
+func TestChallengeDeliveredByAnotherChannel(t *testing.T) {
+	changeCodes := newCodes()
+	s := p.PasswordUseCase{
+		PasswordComparator:       plain{},
+		PasswordRepository:       newUsers(user{id: "u1", username: "alice", email: "alice@example.com", password: "h:p0"}),
+		PasswordChangeExpires:    300,
+		ChangePasscodeRepository: changeCodes,
+		Delivery:                 emailDeliverer{},
+		ResolveFactors: func(ctx context.Context, id string) ([]p.Factor, error) {
+			return []p.Factor{{Type: p.FactorSMS, Destination: "+15551234567"}}, nil
+		},
+	}
+	result, err := s.ChangePasswordWithResult(context.Background(), p.PasswordChange{Username: "alice", CurrentPassword: "p0", Password: "p1"})
+	if err == nil || result.Status != 0 {
+		t.Fatalf("ChangePasswordWithResult() = %+v, %v; want an error for the code delivered by email instead of sms", result, err)
+	}
+	if !changeCodes.deleted("u1") {
+		t.Error("the change code is kept; want it deleted")
+	}
+}
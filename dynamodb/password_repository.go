@@ -2,7 +2,6 @@ package dynamodb
 
 import (
 	"context"
-	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
@@ -19,6 +18,7 @@ type PasswordRepository struct {
 	PasswordTableName string
 	HistoryTableName  string
 	Key               string // User Id from context
+	TenantKey         string // Tenant Id from context; when it is set, the keys are prefixed by the tenant, such as "tenant#userid"
 	PasswordName      string
 	ToAddressName     string
 	ChangedTimeName   string
@@ -39,6 +39,9 @@ func NewPasswordRepository(dynamoDB *dynamodb.DynamoDB, userTableName, passwordT
 	if len(userName) == 0 {
 		userName = "username"
 	}
+	if len(timestampName) == 0 {
+		timestampName = "timestamp"
+	}
 	return &PasswordRepository{
 		DB:                dynamoDB,
 		UserTableName:     userTableName,
@@ -67,6 +70,10 @@ func NewPasswordRepositoryByConfig(dynamoDB *dynamodb.DynamoDB, userTableName, p
 func (r *PasswordRepository) GetUserId(ctx context.Context, userName string) (string, error) {
 	projection := expression.NamesList(expression.Name("_id"))
 	filter := expression.Equal(expression.Name(r.UserName), expression.Value(userName))
+	prefix := tenantPrefix(ctx, r.TenantKey)
+	if len(prefix) > 0 {
+		filter = filter.And(expression.BeginsWith(expression.Name("_id"), prefix))
+	}
 	expr, _ := expression.NewBuilder().WithProjection(projection).WithFilter(filter).Build()
 	query := &dynamodb.ScanInput{
 		TableName:                 aws.String(r.UserTableName),
@@ -87,7 +94,7 @@ func (r *PasswordRepository) GetUserId(ctx context.Context, userName string) (st
 	if err != nil {
 		return "", err
 	}
-	return result["_id"], err
+	return strings.TrimPrefix(result["_id"], prefix), err
 }
 
 func (r *PasswordRepository) GetUser(ctx context.Context, userNameOrEmail string) (string, string, string, string, error) {
@@ -95,6 +102,10 @@ func (r *PasswordRepository) GetUser(ctx context.Context, userNameOrEmail string
 	userNameFilter := expression.Equal(expression.Name(r.UserName), expression.Value(userNameOrEmail))
 	emailFilter := expression.Equal(expression.Name(r.ToAddressName), expression.Value(userNameOrEmail))
 	filter := expression.Or(userNameFilter, emailFilter)
+	prefix := tenantPrefix(ctx, r.TenantKey)
+	if len(prefix) > 0 {
+		filter = filter.And(expression.BeginsWith(expression.Name("_id"), prefix))
+	}
 	expr, _ := expression.NewBuilder().WithProjection(projection).WithFilter(filter).Build()
 	query := &dynamodb.ScanInput{
 		TableName:                 aws.String(r.UserTableName),
@@ -114,12 +125,12 @@ func (r *PasswordRepository) GetUser(ctx context.Context, userNameOrEmail string
 		return "", "", "", "", err
 	}
 
-	userId := userResult["_id"]
+	userId := strings.TrimPrefix(userResult["_id"], prefix)
 	userName := userResult[r.UserName]
 	email := userResult[r.ToAddressName]
 
 	keyMap := map[string]*dynamodb.AttributeValue{}
-	keyMap["_id"] = &dynamodb.AttributeValue{S: aws.String(userId)}
+	keyMap["_id"] = &dynamodb.AttributeValue{S: aws.String(prefix + userId)}
 	input := &dynamodb.GetItemInput{
 		TableName: aws.String(r.PasswordTableName),
 		Key:       keyMap,
@@ -134,41 +145,39 @@ func (r *PasswordRepository) GetUser(ctx context.Context, userNameOrEmail string
 	return userId, userName, email, passResult[r.PasswordName], err
 }
 
+// Update sets the password with UpdateItem, which creates the item if there is none, and keeps the other attributes.
 func (r *PasswordRepository) Update(ctx context.Context, userId string, newPassword string) (int64, error) {
-	pass := make(map[string]*dynamodb.AttributeValue)
-	pass["_id"] = &dynamodb.AttributeValue{S: aws.String(userId)}
-	pass[r.PasswordName] = &dynamodb.AttributeValue{S: aws.String(newPassword)}
+	update := expression.Set(expression.Name(r.PasswordName), expression.Value(newPassword))
 	if len(r.ChangedTimeName) > 0 {
-		pass[r.ChangedTimeName] = &dynamodb.AttributeValue{S: aws.String(time.Now().Format(time.RFC3339))}
+		update = update.Set(expression.Name(r.ChangedTimeName), expression.Value(time.Now().Format(time.RFC3339)))
 	}
 	if len(r.FailCountName) > 0 {
-		pass[r.FailCountName] = &dynamodb.AttributeValue{N: aws.String("0")}
+		update = update.Set(expression.Name(r.FailCountName), expression.Value(0))
 	}
 	if len(r.ChangedByName) > 0 {
 		uid := getString(ctx, r.Key)
 		if len(uid) > 0 {
-			pass[r.ChangedByName] = &dynamodb.AttributeValue{S: aws.String(uid)}
+			update = update.Set(expression.Name(r.ChangedByName), expression.Value(uid))
 		} else {
-			pass[r.ChangedByName] = &dynamodb.AttributeValue{S: aws.String(userId)}
+			update = update.Set(expression.Name(r.ChangedByName), expression.Value(userId))
 		}
 	}
-	expected := make(map[string]*dynamodb.ExpectedAttributeValue)
-	expected["_id"] = &dynamodb.ExpectedAttributeValue{Value: &dynamodb.AttributeValue{S: aws.String(userId)}, Exists: aws.Bool(true)}
-
-	params := &dynamodb.PutItemInput{
-		TableName:              aws.String(r.PasswordTableName),
-		Expected:               expected,
-		Item:                   pass,
-		ReturnConsumedCapacity: aws.String(dynamodb.ReturnConsumedCapacityTotal),
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		return 0, err
+	}
+	params := &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.PasswordTableName),
+		Key:                       map[string]*dynamodb.AttributeValue{"_id": {S: aws.String(tenantPrefix(ctx, r.TenantKey) + userId)}},
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
 	}
-	output, err := r.DB.PutItemWithContext(ctx, params)
+	_, err = r.DB.UpdateItemWithContext(ctx, params)
 	if err != nil {
-		if strings.Index(err.Error(), "ConditionalCheckFailedException:") >= 0 {
-			return 0, fmt.Errorf("object not found")
-		}
 		return 0, err
 	}
-	return int64(aws.Float64Value(output.ConsumedCapacity.CapacityUnits)), nil
+	return 1, nil
 }
 
 func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, userId string, currentPassword, newPassword string) (int64, error) {
@@ -178,9 +187,9 @@ func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, user
 	}
 
 	history := make(map[string]*dynamodb.AttributeValue)
-	history["_id"] = &dynamodb.AttributeValue{S: aws.String(userId)}
-	history[r.PasswordName] = &dynamodb.AttributeValue{S: aws.String(newPassword)}
-	history[r.TimestampName] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(time.Now().Second()))}
+	history["_id"] = &dynamodb.AttributeValue{S: aws.String(tenantPrefix(ctx, r.TenantKey) + userId)}
+	history[r.PasswordName] = &dynamodb.AttributeValue{S: aws.String(currentPassword)}
+	history[r.TimestampName] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(time.Now().UnixNano(), 10))}
 	params := &dynamodb.PutItemInput{
 		TableName:              aws.String(r.HistoryTableName),
 		Item:                   history,
@@ -193,13 +202,18 @@ func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, user
 	return k1 + int64(aws.Float64Value(output.ConsumedCapacity.CapacityUnits)), nil
 }
 
+// GetHistory queries the history table, whose partition key is _id and sort key is the timestamp, the latest first.
+// It does not query when max is not positive, since DynamoDB rejects a Limit of 0.
 func (r *PasswordRepository) GetHistory(ctx context.Context, userId string, max int) ([]string, error) {
 	history := make([]string, 0)
+	if max <= 0 {
+		return history, nil
+	}
 	projection := expression.NamesList(expression.Name(r.PasswordName))
-	keyCondition := expression.KeyEqual(expression.Key("_id"), expression.Value(userId))
+	keyCondition := expression.KeyEqual(expression.Key("_id"), expression.Value(tenantPrefix(ctx, r.TenantKey)+userId))
 	expr, _ := expression.NewBuilder().WithProjection(projection).WithKeyCondition(keyCondition).Build()
 	query := &dynamodb.QueryInput{
-		TableName:                 aws.String(r.UserTableName),
+		TableName:                 aws.String(r.HistoryTableName),
 		ProjectionExpression:      expr.Projection(),
 		KeyConditionExpression:    expr.KeyCondition(),
 		ExpressionAttributeNames:  expr.Names(),
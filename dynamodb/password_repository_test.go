@@ -0,0 +1,131 @@
+//go:build integration
+
+package dynamodb
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	p "github.com/core-go/password"
+	"github.com/core-go/password/testkit"
+)
+
+// openDB connects to DYNAMODB_ENDPOINT, such as DynamoDB Local, and creates the tables of each case, with a suffix of their own, and deletes them after the case.
+// Run with: DYNAMODB_ENDPOINT=http://localhost:8000 go test -tags integration ./dynamodb
+func openDB(t *testing.T) (*dynamodb.DynamoDB, string) {
+	endpoint := os.Getenv("DYNAMODB_ENDPOINT")
+	if len(endpoint) == 0 {
+		t.Skip("DYNAMODB_ENDPOINT is not set")
+	}
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:    aws.String(endpoint),
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("test", "test", ""),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dynamodb.New(sess), strconv.FormatInt(time.Now().UnixNano(), 10)
+}
+
+// createTable creates a table whose partition key is _id, and whose sort key is sortKey, a number, when it is not empty.
+func createTable(t *testing.T, db *dynamodb.DynamoDB, name string, sortKey string) {
+	ctx := context.Background()
+	input := &dynamodb.CreateTableInput{
+		TableName:            aws.String(name),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{{AttributeName: aws.String("_id"), AttributeType: aws.String("S")}},
+		KeySchema:            []*dynamodb.KeySchemaElement{{AttributeName: aws.String("_id"), KeyType: aws.String("HASH")}},
+		BillingMode:          aws.String(dynamodb.BillingModePayPerRequest),
+	}
+	if len(sortKey) > 0 {
+		input.AttributeDefinitions = append(input.AttributeDefinitions, &dynamodb.AttributeDefinition{AttributeName: aws.String(sortKey), AttributeType: aws.String("N")})
+		input.KeySchema = append(input.KeySchema, &dynamodb.KeySchemaElement{AttributeName: aws.String(sortKey), KeyType: aws.String("RANGE")})
+	}
+	if _, err := db.CreateTableWithContext(ctx, input); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.DeleteTableWithContext(ctx, &dynamodb.DeleteTableInput{TableName: aws.String(name)})
+	})
+	if err := db.WaitUntilTableExistsWithContext(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(name)}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func putItem(t *testing.T, db *dynamodb.DynamoDB, table string, item map[string]*dynamodb.AttributeValue) {
+	if _, err := db.PutItemWithContext(context.Background(), &dynamodb.PutItemInput{TableName: aws.String(table), Item: item}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPasswordRepositoryContract(t *testing.T) {
+	testkit.RunPasswordRepositoryContract(t, testkit.PasswordRepositoryContract{
+		Key: "userId",
+		New: func(t *testing.T, users []testkit.User) p.PasswordRepository {
+			db, suffix := openDB(t)
+			userTable, passwordTable, historyTable := "users"+suffix, "passwords"+suffix, "histories"+suffix
+			createTable(t, db, userTable, "")
+			createTable(t, db, passwordTable, "")
+			createTable(t, db, historyTable, "timestamp")
+			for _, u := range users {
+				putItem(t, db, userTable, map[string]*dynamodb.AttributeValue{
+					"_id":      {S: aws.String(u.Id)},
+					"username": {S: aws.String(u.Username)},
+					"email":    {S: aws.String(u.Email)},
+				})
+				if len(u.Password) > 0 {
+					putItem(t, db, passwordTable, map[string]*dynamodb.AttributeValue{
+						"_id":       {S: aws.String(u.Id)},
+						"password":  {S: aws.String(u.Password)},
+						"failCount": {N: aws.String(strconv.Itoa(u.FailCount))},
+					})
+				}
+			}
+			return NewPasswordRepository(db, userTable, passwordTable, historyTable, "userId", "password", "email", "username", "changedTime", "failCount", "changedBy", "history", "timestamp")
+		},
+		Inspect: func(t *testing.T, repository p.PasswordRepository, id string) (string, int) {
+			r := repository.(*PasswordRepository)
+			resp, err := r.DB.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{
+				TableName: aws.String(r.PasswordTableName),
+				Key:       map[string]*dynamodb.AttributeValue{"_id": {S: aws.String(id)}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var changedBy string
+			var failCount int
+			if v, ok := resp.Item["changedBy"]; ok {
+				changedBy = aws.StringValue(v.S)
+			}
+			if v, ok := resp.Item["failCount"]; ok {
+				failCount, _ = strconv.Atoi(aws.StringValue(v.N))
+			}
+			return changedBy, failCount
+		},
+	})
+}
+
+func TestGetHistoryWithoutMax(t *testing.T) {
+	db, suffix := openDB(t)
+	// there is no history table, so that the case fails if GetHistory queries it
+	r := NewDefaultPasswordRepository(db, "users"+suffix, "passwords"+suffix, "histories"+suffix, "userId", "changedTime", "failCount")
+	history, err := r.GetHistory(context.Background(), "u1", 0)
+	if err != nil || len(history) > 0 {
+		t.Errorf("GetHistory(u1, 0) = %v, %v; want no history and no query", history, err)
+	}
+}
+
+func TestVerificationCodeRepositoryContract(t *testing.T) {
+	testkit.RunVerificationCodeRepositoryContract(t, func(t *testing.T) p.VerificationCodeRepository {
+		db, suffix := openDB(t)
+		createTable(t, db, "codes"+suffix, "")
+		return NewDefaultVerificationCodeRepository(db, "codes"+suffix)
+	})
+}
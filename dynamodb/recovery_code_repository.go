@@ -0,0 +1,84 @@
+package dynamodb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+type RecoveryCodeRepository struct {
+	DB        *dynamodb.DynamoDB
+	TableName string
+	CodeName  string
+	TenantKey string // Tenant Id from context; when it is set, the keys are prefixed by the tenant, such as "tenant#userid"
+}
+
+func NewDefaultRecoveryCodeRepository(dynamoDB *dynamodb.DynamoDB, tableName string) *RecoveryCodeRepository {
+	return NewRecoveryCodeRepository(dynamoDB, tableName, "codes")
+}
+
+func NewRecoveryCodeRepository(dynamoDB *dynamodb.DynamoDB, tableName, codeName string) *RecoveryCodeRepository {
+	if len(codeName) == 0 {
+		codeName = "codes"
+	}
+	return &RecoveryCodeRepository{DB: dynamoDB, TableName: tableName, CodeName: codeName}
+}
+
+func (r *RecoveryCodeRepository) Save(ctx context.Context, id string, codes []string) (int64, error) {
+	key := map[string]*dynamodb.AttributeValue{"_id": {S: aws.String(tenantPrefix(ctx, r.TenantKey) + id)}}
+	if len(codes) == 0 {
+		_, err := r.DB.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{TableName: aws.String(r.TableName), Key: key})
+		return 0, err
+	}
+	item := map[string]*dynamodb.AttributeValue{
+		"_id":      {S: aws.String(tenantPrefix(ctx, r.TenantKey) + id)},
+		r.CodeName: {SS: aws.StringSlice(codes)},
+	}
+	_, err := r.DB.PutItemWithContext(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.TableName), Item: item})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(codes)), nil
+}
+
+func (r *RecoveryCodeRepository) Load(ctx context.Context, id string) ([]string, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(r.TableName),
+		Key:       map[string]*dynamodb.AttributeValue{"_id": {S: aws.String(tenantPrefix(ctx, r.TenantKey) + id)}},
+	}
+	resp, err := r.DB.GetItemWithContext(ctx, input)
+	if err != nil || len(resp.Item) == 0 {
+		return make([]string, 0), err
+	}
+	codes, ok := resp.Item[r.CodeName]
+	if !ok {
+		return make([]string, 0), nil
+	}
+	return aws.StringValueSlice(codes.SS), nil
+}
+
+func (r *RecoveryCodeRepository) Delete(ctx context.Context, id string, code string) (int64, error) {
+	input := &dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.TableName),
+		Key:                 map[string]*dynamodb.AttributeValue{"_id": {S: aws.String(tenantPrefix(ctx, r.TenantKey) + id)}},
+		UpdateExpression:    aws.String("DELETE #codes :codes"),
+		ConditionExpression: aws.String("contains(#codes, :code)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#codes": aws.String(r.CodeName),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":codes": {SS: aws.StringSlice([]string{code})},
+			":code":  {S: aws.String(code)},
+		},
+	}
+	_, err := r.DB.UpdateItemWithContext(ctx, input)
+	if err != nil {
+		if strings.Index(err.Error(), "ConditionalCheckFailedException") >= 0 {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return 1, nil
+}
@@ -0,0 +1,12 @@
+package dynamodb
+
+import "context"
+
+// tenantPrefix returns the prefix of the keys of the tenant of the context, such as "tenant#".
+// It returns an empty string when tenantKey is empty, which means the table is not multi-tenant.
+func tenantPrefix(ctx context.Context, tenantKey string) string {
+	if len(tenantKey) == 0 {
+		return ""
+	}
+	return getString(ctx, tenantKey) + "#"
+}
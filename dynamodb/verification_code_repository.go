@@ -0,0 +1,93 @@
+package dynamodb
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	p "github.com/core-go/password"
+)
+
+type VerificationCodeRepository struct {
+	DB            *dynamodb.DynamoDB
+	TableName     string
+	IdName        string
+	PasscodeName  string
+	ExpiredAtName string
+	TenantKey     string // Tenant Id from context; when it is set, the keys are prefixed by the tenant, such as "tenant#userid"
+}
+
+func NewVerificationCodeRepositoryByConfig(dynamoDB *dynamodb.DynamoDB, tableName string, c p.VerificationCodeSchemaConfig) *VerificationCodeRepository {
+	return NewVerificationCodeRepository(dynamoDB, tableName, c.Id, c.Passcode, c.ExpiredAt)
+}
+
+func NewDefaultVerificationCodeRepository(dynamoDB *dynamodb.DynamoDB, tableName string) *VerificationCodeRepository {
+	return NewVerificationCodeRepository(dynamoDB, tableName, "_id", "passcode", "expiredAt")
+}
+
+// NewVerificationCodeRepository stores expiredAtName as epoch seconds, so that it can be the TTL attribute of the table.
+func NewVerificationCodeRepository(dynamoDB *dynamodb.DynamoDB, tableName, idName, passcodeName, expiredAtName string) *VerificationCodeRepository {
+	if len(idName) == 0 {
+		idName = "_id"
+	}
+	if len(passcodeName) == 0 {
+		passcodeName = "passcode"
+	}
+	if len(expiredAtName) == 0 {
+		expiredAtName = "expiredAt"
+	}
+	return &VerificationCodeRepository{DB: dynamoDB, TableName: tableName, IdName: idName, PasscodeName: passcodeName, ExpiredAtName: expiredAtName}
+}
+
+func (r *VerificationCodeRepository) Save(ctx context.Context, id string, passcode string, expireAt time.Time) (int64, error) {
+	item := map[string]*dynamodb.AttributeValue{
+		r.IdName:        {S: aws.String(tenantPrefix(ctx, r.TenantKey) + id)},
+		r.PasscodeName:  {S: aws.String(passcode)},
+		r.ExpiredAtName: {N: aws.String(strconv.FormatInt(expireAt.Unix(), 10))},
+	}
+	_, err := r.DB.PutItemWithContext(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.TableName), Item: item})
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (r *VerificationCodeRepository) Load(ctx context.Context, id string) (string, time.Time, error) {
+	input := &dynamodb.GetItemInput{
+		TableName:      aws.String(r.TableName),
+		Key:            map[string]*dynamodb.AttributeValue{r.IdName: {S: aws.String(tenantPrefix(ctx, r.TenantKey) + id)}},
+		ConsistentRead: aws.Bool(true),
+	}
+	resp, err := r.DB.GetItemWithContext(ctx, input)
+	if err != nil || len(resp.Item) == 0 {
+		return "", time.Time{}, err
+	}
+	var passcode string
+	var expiredAt time.Time
+	if v, ok := resp.Item[r.PasscodeName]; ok {
+		passcode = aws.StringValue(v.S)
+	}
+	if v, ok := resp.Item[r.ExpiredAtName]; ok {
+		seconds, er1 := strconv.ParseInt(aws.StringValue(v.N), 10, 64)
+		if er1 != nil {
+			return "", time.Time{}, er1
+		}
+		expiredAt = time.Unix(seconds, 0)
+	}
+	return passcode, expiredAt, nil
+}
+
+func (r *VerificationCodeRepository) Delete(ctx context.Context, id string) (int64, error) {
+	input := &dynamodb.DeleteItemInput{
+		TableName:    aws.String(r.TableName),
+		Key:          map[string]*dynamodb.AttributeValue{r.IdName: {S: aws.String(tenantPrefix(ctx, r.TenantKey) + id)}},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllOld),
+	}
+	resp, err := r.DB.DeleteItemWithContext(ctx, input)
+	if err != nil || len(resp.Attributes) == 0 {
+		return 0, err
+	}
+	return 1, nil
+}
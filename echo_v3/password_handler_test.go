@@ -0,0 +1,24 @@
+package echo
+
+import (
+	"testing"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/testkit"
+	"github.com/labstack/echo"
+)
+
+func TestHandlerContract(t *testing.T) {
+	testkit.RunHandlerContract(t, testkit.HandlerContract{
+		New: func(t *testing.T, service p.PasswordService, options ...p.HandlerOption) testkit.Handle {
+			h := NewHandler(service, options...)
+			e := echo.New()
+			e.POST("/password/change", h.ChangePassword)
+			e.GET("/password/forgot/:contact", h.ForgotPassword)
+			e.POST("/password/forgot", h.ForgotPassword)
+			e.POST("/password/reset", h.ResetPassword)
+			e.POST("/password/deny", h.DenyChange)
+			return testkit.ServeHTTP(e)
+		},
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/elastic/go-elasticsearch/v7"
 	"github.com/elastic/go-elasticsearch/v7/esapi"
 	"github.com/elastic/go-elasticsearch/v7/esutil"
+	"net/http"
 	"time"
 )
 
@@ -17,44 +18,67 @@ type PasswordRepository struct {
 	UserIndexName     string
 	PasswordIndexName string
 	Key               string // User Id from context
+	TenantKey         string // Tenant Id from context; when it is set, each tenant has its own indices, such as "users-tenant"
 	PasswordName      string
 	ToAddressName     string
 	ChangedTimeName   string
 	FailCountName     string
 	UserName          string
 	ChangedByName     string
+	HistoryIndexName  string // the history is not kept when it is empty
+	IdName            string // the field of the user id in the history index
+	TimestampName     string
 }
 
-func NewPasswordRepositoryByConfig(db *elasticsearch.Client, userIndexName string, passwordIndexName string, key string, c p.PasswordSchemaConfig) *PasswordRepository {
-	return NewPasswordRepository(db, userIndexName, passwordIndexName, key, c.Password, c.ToAddress, c.ChangedTime, c.FailCount, c.UserName, c.ChangedBy)
+// NewPasswordRepositoryByConfig receives the name of the history index as the option.
+func NewPasswordRepositoryByConfig(db *elasticsearch.Client, userIndexName string, passwordIndexName string, key string, c p.PasswordSchemaConfig, options ...string) *PasswordRepository {
+	r := NewPasswordRepository(db, userIndexName, passwordIndexName, key, c.Password, c.ToAddress, c.Username, c.ChangedTime, c.FailCount, c.ChangedBy, options...)
+	if len(r.HistoryIndexName) > 0 {
+		if len(c.UserId) > 0 {
+			r.IdName = c.UserId
+		}
+		if len(c.Timestamp) > 0 {
+			r.TimestampName = c.Timestamp
+		}
+	}
+	return r
 }
 
-func NewPasswordRepository(db *elasticsearch.Client, userIndexName string, passwordIndexName string, key string, passwordName, emailName, userName, passwordModifiedTimeName, failCountName, changedByName string) *PasswordRepository {
+// NewPasswordRepository receives the name of the history index as the option.
+func NewPasswordRepository(db *elasticsearch.Client, userIndexName string, passwordIndexName string, key string, passwordName, emailName, userName, passwordModifiedTimeName, failCountName, changedByName string, options ...string) *PasswordRepository {
+	var historyIndexName string
+	if len(options) >= 1 {
+		historyIndexName = options[0]
+	}
 	return &PasswordRepository{
 		Client:            db,
 		UserIndexName:     userIndexName,
 		PasswordIndexName: passwordIndexName,
+		Key:               key,
 		ToAddressName:     emailName,
 		PasswordName:      passwordName,
 		ChangedTimeName:   passwordModifiedTimeName,
 		FailCountName:     failCountName,
 		UserName:          userName,
 		ChangedByName:     changedByName,
+		HistoryIndexName:  historyIndexName,
+		IdName:            "userId",
+		TimestampName:     "timestamp",
 	}
 }
 
 func (r *PasswordRepository) GetUserId(ctx context.Context, userName string) (string, error) {
 	query := map[string]interface{}{
 		"query": map[string]interface{}{
-			"bool": map[string]interface{}{
-				"match": map[string]interface{}{
-					r.UserName: userName,
-				},
-			},
+			"term": map[string]interface{}{r.UserName: userName},
 		},
 	}
+	index, err := tenantIndex(ctx, r.TenantKey, r.UserIndexName)
+	if err != nil {
+		return "", err
+	}
 	res := make(map[string]interface{})
-	ok, err := findOneAndDecode(ctx, r.Client, []string{r.UserIndexName}, query, &res)
+	ok, err := findOneAndDecode(ctx, r.Client, []string{index}, query, &res)
 	if !ok || err != nil {
 		return "", err
 	}
@@ -73,24 +97,39 @@ func (r *PasswordRepository) GetUser(ctx context.Context, userNameOrEmail string
 			},
 		},
 	}
+	userIndex, err := tenantIndex(ctx, r.TenantKey, r.UserIndexName)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	passwordIndex, err := tenantIndex(ctx, r.TenantKey, r.PasswordIndexName)
+	if err != nil {
+		return "", "", "", "", err
+	}
 	user := make(map[string]interface{})
-	ok, err := findOneAndDecode(ctx, r.Client, []string{r.UserIndexName}, userQuery, &user)
+	ok, err := findOneAndDecode(ctx, r.Client, []string{userIndex}, userQuery, &user)
 	if !ok || err != nil {
 		return "", "", "", "", err
 	}
-	userID = user["_id"].(string)
+	// the hit keeps the fields of the user in _source
+	userID, _ = user["_id"].(string)
+	source, _ := user["_source"].(map[string]interface{})
+	userName, _ = source[r.UserName].(string)
+	toAddressName, _ = source[r.ToAddressName].(string)
 
 	pass := make(map[string]interface{})
-	ok, err = findOneByIdAndDecode(ctx, r.Client, r.PasswordIndexName, userID, &pass)
-	if !ok || err != nil {
+	ok, err = findOneByIdAndDecode(ctx, r.Client, passwordIndex, userID, &pass)
+	if err != nil {
 		return "", "", "", "", err
 	}
-	return userID, user[r.UserName].(string), user[r.ToAddressName].(string), pass[r.PasswordName].(string), nil
+	if !ok {
+		return userID, userName, toAddressName, "", nil
+	}
+	passwordName, _ = pass[r.PasswordName].(string)
+	return userID, userName, toAddressName, passwordName, nil
 }
 
 func (r *PasswordRepository) Update(ctx context.Context, userId string, newPassword string) (int64, error) {
 	pass := make(map[string]interface{})
-	pass["_id"] = userId
 	pass[r.PasswordName] = newPassword
 	if len(r.ChangedTimeName) > 0 {
 		pass[r.ChangedTimeName] = time.Now()
@@ -106,10 +145,14 @@ func (r *PasswordRepository) Update(ctx context.Context, userId string, newPassw
 			pass[r.ChangedByName] = userId
 		}
 	}
+	index, err := tenantIndex(ctx, r.TenantKey, r.PasswordIndexName)
+	if err != nil {
+		return -1, err
+	}
 	req := esapi.UpdateRequest{
-		Index:      r.PasswordIndexName,
+		Index:      index,
 		DocumentID: userId,
-		Body:       esutil.NewJSONReader(pass),
+		Body:       esutil.NewJSONReader(map[string]interface{}{"doc": pass, "doc_as_upsert": true}),
 		Refresh:    "true",
 	}
 	res, err := req.Do(ctx, r.Client)
@@ -132,12 +175,84 @@ func (r *PasswordRepository) Update(ctx context.Context, userId string, newPassw
 }
 
 func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, userId string, currentPassword, newPassword string) (int64, error) {
-	return r.Update(ctx, userId, newPassword)
+	count, err := r.Update(ctx, userId, newPassword)
+	if err != nil || len(r.HistoryIndexName) == 0 {
+		return count, err
+	}
+	history := map[string]interface{}{
+		r.IdName:        userId,
+		r.PasswordName:  currentPassword,
+		r.TimestampName: time.Now(),
+	}
+	index, err := tenantIndex(ctx, r.TenantKey, r.HistoryIndexName)
+	if err != nil {
+		return count, err
+	}
+	req := esapi.IndexRequest{
+		Index:   index,
+		Body:    esutil.NewJSONReader(history),
+		Refresh: "true",
+	}
+	res, err := req.Do(ctx, r.Client)
+	if err != nil {
+		return count, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return count, fmt.Errorf("cannot index the history of %s: %s", userId, res.Status())
+	}
+	return count + 1, nil
 }
 
 func (r *PasswordRepository) GetHistory(ctx context.Context, userId string, max int) ([]string, error) {
-	a := make([]string, 0)
-	return a, nil
+	history := make([]string, 0)
+	if len(r.HistoryIndexName) == 0 {
+		return history, nil
+	}
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"term": map[string]interface{}{r.IdName: userId},
+		},
+		"sort": []map[string]interface{}{
+			{r.TimestampName: map[string]interface{}{"order": "desc"}},
+		},
+		"size": max,
+	}
+	index, err := tenantIndex(ctx, r.TenantKey, r.HistoryIndexName)
+	if err != nil {
+		return history, err
+	}
+	req := esapi.SearchRequest{
+		Index: []string{index},
+		Body:  esutil.NewJSONReader(query),
+	}
+	res, err := req.Do(ctx, r.Client)
+	if err != nil {
+		return history, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			return history, nil
+		}
+		return history, fmt.Errorf("cannot search the history of %s: %s", userId, res.Status())
+	}
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				Source map[string]interface{} `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return history, err
+	}
+	for _, hit := range result.Hits.Hits {
+		if password, ok := hit.Source[r.PasswordName].(string); ok {
+			history = append(history, password)
+		}
+	}
+	return history, nil
 }
 
 func getString(ctx context.Context, key string) string {
@@ -197,6 +312,9 @@ func findOneByIdAndDecode(ctx context.Context, client *elasticsearch.Client, ind
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
 	if res.IsError() {
 		return false, errors.New("response error")
 	} else {
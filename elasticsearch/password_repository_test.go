@@ -0,0 +1,115 @@
+//go:build integration
+
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/testkit"
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"github.com/elastic/go-elasticsearch/v7/esutil"
+)
+
+// openClient connects to ELASTICSEARCH_URL. Each case has indices with a suffix of their own, which are deleted after the case.
+// Run with: ELASTICSEARCH_URL=http://localhost:9200 go test -tags integration ./elasticsearch
+func openClient(t *testing.T) (*elasticsearch.Client, string) {
+	url := os.Getenv("ELASTICSEARCH_URL")
+	if len(url) == 0 {
+		t.Skip("ELASTICSEARCH_URL is not set")
+	}
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{url}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, strconv.FormatInt(time.Now().UnixNano(), 10)
+}
+
+// createIndex creates the index with the mapping of the properties, and deletes it after the case.
+func createIndex(t *testing.T, client *elasticsearch.Client, index string, properties map[string]interface{}) {
+	ctx := context.Background()
+	req := esapi.IndicesCreateRequest{
+		Index: index,
+		Body:  esutil.NewJSONReader(map[string]interface{}{"mappings": map[string]interface{}{"properties": properties}}),
+	}
+	res, err := req.Do(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.IsError() {
+		t.Fatalf("cannot create the index %s: %s", index, res.Status())
+	}
+	t.Cleanup(func() {
+		res, err := esapi.IndicesDeleteRequest{Index: []string{index}}.Do(ctx, client)
+		if err == nil {
+			res.Body.Close()
+		}
+	})
+}
+
+func index(t *testing.T, client *elasticsearch.Client, index string, id string, doc map[string]interface{}) {
+	req := esapi.IndexRequest{Index: index, DocumentID: id, Body: esutil.NewJSONReader(doc), Refresh: "true"}
+	res, err := req.Do(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.IsError() {
+		t.Fatalf("cannot index %s in %s: %s", id, index, res.Status())
+	}
+}
+
+var keyword = map[string]interface{}{"type": "keyword"}
+
+func TestPasswordRepositoryContract(t *testing.T) {
+	testkit.RunPasswordRepositoryContract(t, testkit.PasswordRepositoryContract{
+		Key: "userId",
+		New: func(t *testing.T, users []testkit.User) p.PasswordRepository {
+			client, suffix := openClient(t)
+			userIndex, passwordIndex, historyIndex := "users"+suffix, "passwords"+suffix, "histories"+suffix
+			createIndex(t, client, userIndex, map[string]interface{}{"username": keyword, "email": keyword})
+			createIndex(t, client, passwordIndex, map[string]interface{}{"password": keyword, "changedBy": keyword})
+			// the changes of a case are in the same millisecond sometimes, so the history is sorted by nanoseconds
+			createIndex(t, client, historyIndex, map[string]interface{}{"userId": keyword, "password": keyword, "timestamp": map[string]interface{}{"type": "date_nanos"}})
+			for _, u := range users {
+				index(t, client, userIndex, u.Id, map[string]interface{}{"username": u.Username, "email": u.Email})
+				if len(u.Password) > 0 {
+					index(t, client, passwordIndex, u.Id, map[string]interface{}{"password": u.Password, "failCount": u.FailCount})
+				}
+			}
+			return NewPasswordRepository(client, userIndex, passwordIndex, "userId", "password", "email", "username", "changedTime", "failCount", "changedBy", historyIndex)
+		},
+		Inspect: func(t *testing.T, repository p.PasswordRepository, id string) (string, int) {
+			r := repository.(*PasswordRepository)
+			res, err := esapi.GetRequest{Index: r.PasswordIndexName, DocumentID: id}.Do(context.Background(), r.Client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			var doc struct {
+				Source struct {
+					ChangedBy string `json:"changedBy"`
+					FailCount int    `json:"failCount"`
+				} `json:"_source"`
+			}
+			if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+				t.Fatal(err)
+			}
+			return doc.Source.ChangedBy, doc.Source.FailCount
+		},
+	})
+}
+
+func TestVerificationCodeRepositoryContract(t *testing.T) {
+	testkit.RunVerificationCodeRepositoryContract(t, func(t *testing.T) p.VerificationCodeRepository {
+		client, suffix := openClient(t)
+		return NewDefaultVerificationCodeRepository(client, "codes"+suffix)
+	})
+}
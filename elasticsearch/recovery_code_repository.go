@@ -0,0 +1,127 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"github.com/elastic/go-elasticsearch/v7/esutil"
+)
+
+const removeCodeScript = "if (ctx._source.%s != null && ctx._source.%s.contains(params.code)) { ctx._source.%s.remove(ctx._source.%s.indexOf(params.code)) } else { ctx.op = 'noop' }"
+
+type RecoveryCodeRepository struct {
+	Client    *elasticsearch.Client
+	IndexName string
+	CodeName  string
+	TenantKey string // Tenant Id from context; when it is set, each tenant has its own index, such as "codes-tenant"
+}
+
+func NewDefaultRecoveryCodeRepository(client *elasticsearch.Client, indexName string) *RecoveryCodeRepository {
+	return NewRecoveryCodeRepository(client, indexName, "codes")
+}
+
+func NewRecoveryCodeRepository(client *elasticsearch.Client, indexName, codeName string) *RecoveryCodeRepository {
+	if len(codeName) == 0 {
+		codeName = "codes"
+	}
+	return &RecoveryCodeRepository{Client: client, IndexName: indexName, CodeName: codeName}
+}
+
+func (r *RecoveryCodeRepository) Save(ctx context.Context, id string, codes []string) (int64, error) {
+	index, err := tenantIndex(ctx, r.TenantKey, r.IndexName)
+	if err != nil {
+		return 0, err
+	}
+	req := esapi.IndexRequest{
+		Index:      index,
+		DocumentID: id,
+		Body:       esutil.NewJSONReader(map[string]interface{}{r.CodeName: codes}),
+		Refresh:    "true",
+	}
+	res, err := req.Do(ctx, r.Client)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return 0, fmt.Errorf("cannot save recovery codes: %s", res.Status())
+	}
+	return int64(len(codes)), nil
+}
+
+func (r *RecoveryCodeRepository) Load(ctx context.Context, id string) ([]string, error) {
+	codes := make([]string, 0)
+	index, err := tenantIndex(ctx, r.TenantKey, r.IndexName)
+	if err != nil {
+		return codes, err
+	}
+	req := esapi.GetRequest{
+		Index:      index,
+		DocumentID: id,
+	}
+	res, err := req.Do(ctx, r.Client)
+	if err != nil {
+		return codes, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return codes, nil
+	}
+	if res.IsError() {
+		return codes, fmt.Errorf("cannot load recovery codes: %s", res.Status())
+	}
+	var doc struct {
+		Source map[string][]string `json:"_source"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		return codes, err
+	}
+	if v, ok := doc.Source[r.CodeName]; ok {
+		codes = v
+	}
+	return codes, nil
+}
+
+func (r *RecoveryCodeRepository) Delete(ctx context.Context, id string, code string) (int64, error) {
+	n := r.CodeName
+	body := map[string]interface{}{
+		"script": map[string]interface{}{
+			"source": fmt.Sprintf(removeCodeScript, n, n, n, n),
+			"lang":   "painless",
+			"params": map[string]interface{}{"code": code},
+		},
+	}
+	index, err := tenantIndex(ctx, r.TenantKey, r.IndexName)
+	if err != nil {
+		return 0, err
+	}
+	req := esapi.UpdateRequest{
+		Index:      index,
+		DocumentID: id,
+		Body:       esutil.NewJSONReader(body),
+		Refresh:    "true",
+	}
+	res, err := req.Do(ctx, r.Client)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return 0, nil
+	}
+	if res.IsError() {
+		return 0, fmt.Errorf("cannot delete recovery code: %s", res.Status())
+	}
+	var result map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	if result["result"] == "updated" {
+		return 1, nil
+	}
+	return 0, nil
+}
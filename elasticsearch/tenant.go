@@ -0,0 +1,24 @@
+package elasticsearch
+
+import (
+	"context"
+
+	p "github.com/core-go/password"
+)
+
+// tenantIndex returns the index of the tenant of the context, such as "passwords-tenant".
+// It returns the index itself when tenantKey is empty, which means the index is not multi-tenant.
+// It returns ErrInvalidTenant when the context has no tenant, or a tenant which is not valid, so that the documents are never read or written in an index of another tenant, or in the index without a tenant.
+func tenantIndex(ctx context.Context, tenantKey string, indexName string) (string, error) {
+	if len(tenantKey) == 0 || len(indexName) == 0 {
+		return indexName, nil
+	}
+	tenant, err := p.GetTenant(ctx, tenantKey)
+	if err != nil {
+		return "", err
+	}
+	if len(tenant) == 0 {
+		return "", p.ErrInvalidTenant
+	}
+	return indexName + "-" + tenant, nil
+}
@@ -0,0 +1,132 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	p "github.com/core-go/password"
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"github.com/elastic/go-elasticsearch/v7/esutil"
+)
+
+type VerificationCodeRepository struct {
+	Client        *elasticsearch.Client
+	IndexName     string
+	PasscodeName  string
+	ExpiredAtName string
+	TenantKey     string // Tenant Id from context; when it is set, each tenant has its own index, such as "codes-tenant"
+}
+
+func NewVerificationCodeRepositoryByConfig(client *elasticsearch.Client, indexName string, c p.VerificationCodeSchemaConfig) *VerificationCodeRepository {
+	return NewVerificationCodeRepository(client, indexName, c.Passcode, c.ExpiredAt)
+}
+
+func NewDefaultVerificationCodeRepository(client *elasticsearch.Client, indexName string) *VerificationCodeRepository {
+	return NewVerificationCodeRepository(client, indexName, "passcode", "expiredAt")
+}
+
+// NewVerificationCodeRepository uses the id as the document id.
+func NewVerificationCodeRepository(client *elasticsearch.Client, indexName, passcodeName, expiredAtName string) *VerificationCodeRepository {
+	if len(passcodeName) == 0 {
+		passcodeName = "passcode"
+	}
+	if len(expiredAtName) == 0 {
+		expiredAtName = "expiredAt"
+	}
+	return &VerificationCodeRepository{Client: client, IndexName: indexName, PasscodeName: passcodeName, ExpiredAtName: expiredAtName}
+}
+
+func (r *VerificationCodeRepository) Save(ctx context.Context, id string, passcode string, expireAt time.Time) (int64, error) {
+	index, err := tenantIndex(ctx, r.TenantKey, r.IndexName)
+	if err != nil {
+		return 0, err
+	}
+	req := esapi.IndexRequest{
+		Index:      index,
+		DocumentID: id,
+		Body:       esutil.NewJSONReader(map[string]interface{}{r.PasscodeName: passcode, r.ExpiredAtName: expireAt}),
+		Refresh:    "true",
+	}
+	res, err := req.Do(ctx, r.Client)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return 0, fmt.Errorf("cannot save verification code: %s", res.Status())
+	}
+	return 1, nil
+}
+
+func (r *VerificationCodeRepository) Load(ctx context.Context, id string) (string, time.Time, error) {
+	index, err := tenantIndex(ctx, r.TenantKey, r.IndexName)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req := esapi.GetRequest{
+		Index:      index,
+		DocumentID: id,
+	}
+	res, err := req.Do(ctx, r.Client)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return "", time.Time{}, nil
+	}
+	if res.IsError() {
+		return "", time.Time{}, fmt.Errorf("cannot load verification code: %s", res.Status())
+	}
+	var doc struct {
+		Source map[string]interface{} `json:"_source"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		return "", time.Time{}, err
+	}
+	passcode, _ := doc.Source[r.PasscodeName].(string)
+	var expiredAt time.Time
+	if s, ok := doc.Source[r.ExpiredAtName].(string); ok {
+		t, er1 := time.Parse(time.RFC3339Nano, s)
+		if er1 != nil {
+			return "", time.Time{}, er1
+		}
+		expiredAt = t
+	}
+	return passcode, expiredAt, nil
+}
+
+func (r *VerificationCodeRepository) Delete(ctx context.Context, id string) (int64, error) {
+	index, err := tenantIndex(ctx, r.TenantKey, r.IndexName)
+	if err != nil {
+		return 0, err
+	}
+	req := esapi.DeleteRequest{
+		Index:      index,
+		DocumentID: id,
+		Refresh:    "true",
+	}
+	res, err := req.Do(ctx, r.Client)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return 0, nil
+	}
+	if res.IsError() {
+		return 0, fmt.Errorf("cannot delete verification code: %s", res.Status())
+	}
+	var result map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	if result["result"] == "deleted" {
+		return 1, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,77 @@
+package password
+
+import "strings"
+
+const (
+	FactorEmail    = "email"
+	FactorSMS      = "sms"
+	FactorTOTP     = "totp"
+	FactorRecovery = "recovery"
+)
+
+type Factor struct {
+	Type        string `mapstructure:"type" json:"type,omitempty" gorm:"column:type" bson:"type,omitempty" dynamodbav:"type,omitempty" firestore:"type,omitempty"`
+	Destination string `mapstructure:"destination" json:"destination,omitempty" gorm:"column:destination" bson:"destination,omitempty" dynamodbav:"destination,omitempty" firestore:"destination,omitempty"`
+}
+
+// SendsCode reports whether the factor is verified by a generated code, which is saved and sent to the destination.
+func (f Factor) SendsCode() bool {
+	return f.Type == FactorEmail || f.Type == FactorSMS
+}
+
+// factorCode returns the code to save at the first step, with the type of the selected factor; the code is empty for the factors which do not send a code.
+func factorCode(factorType string, code string) string {
+	return factorType + ":" + code
+}
+
+// splitFactorCode returns the type of the factor and the code of a saved code.
+func splitFactorCode(saved string) (string, string) {
+	i := strings.Index(saved, ":")
+	if i < 0 {
+		return "", saved
+	}
+	return saved[:i], saved[i+1:]
+}
+
+func selectFactor(factors []Factor, factorType string) (Factor, bool) {
+	if len(factors) == 0 {
+		return Factor{}, false
+	}
+	if len(factorType) == 0 {
+		return factors[0], true
+	}
+	for _, factor := range factors {
+		if factor.Type == factorType {
+			return factor, true
+		}
+	}
+	return Factor{}, false
+}
+
+func Mask(factorType string, destination string) string {
+	if len(destination) == 0 {
+		return destination
+	}
+	if factorType == FactorEmail || strings.Contains(destination, "@") {
+		i := strings.LastIndex(destination, "@")
+		if i < 0 {
+			return maskRight(destination, 1)
+		}
+		return maskRight(destination[:i], 1) + destination[i:]
+	}
+	return maskLeft(destination, 4)
+}
+
+func maskRight(s string, visible int) string {
+	if len(s) <= visible {
+		return times("*", len(s))
+	}
+	return s[:visible] + times("*", len(s)-visible)
+}
+
+func maskLeft(s string, visible int) string {
+	if len(s) <= visible {
+		return times("*", len(s))
+	}
+	return times("*", len(s)-visible) + s[len(s)-visible:]
+}
@@ -0,0 +1,114 @@
+package password_test
+
+import (
+	"context"
+	"testing"
+
+	p "github.com/core-go/password"
+)
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		factor      string
+		destination string
+		want        string
+	}{
+		{p.FactorEmail, "alice@example.com", "a****@example.com"},
+		{p.FactorEmail, "a@example.com", "*@example.com"},
+		{p.FactorEmail, "alice", "a****"},
+		{p.FactorSMS, "+15551234567", "********4567"},
+		{p.FactorSMS, "4567", "****"},
+		{p.FactorSMS, "bob@example.com", "b**@example.com"},
+		{p.FactorTOTP, "", ""},
+	}
+	for _, tt := range tests {
+		if got := p.Mask(tt.factor, tt.destination); got != tt.want {
+			t.Errorf("Mask(%s, %q) = %q; want %q", tt.factor, tt.destination, got, tt.want)
+		}
+	}
+}
+
+func TestChangePasswordFactors(t *testing.T) {
+	factors := []p.Factor{
+		{Type: p.FactorEmail, Destination: "alice@example.com"},
+		{Type: p.FactorSMS, Destination: "+15551234567"},
+		{Type: p.FactorTOTP},
+	}
+	tests := []struct {
+		name        string
+		challenge   string
+		status      int32
+		factor      string
+		destination string
+		sent        bool
+		// verify is the factor of the second step, with the code, or with the sent code if code is empty
+		verify string
+		code   string
+		want   int32
+	}{
+		{"Default", "", 2, p.FactorEmail, "a****@example.com", true, p.FactorEmail, "", 1},
+		{"SMS", p.FactorSMS, 2, p.FactorSMS, "********4567", true, p.FactorSMS, "", 1},
+		{"TOTP", p.FactorTOTP, 2, p.FactorTOTP, "", false, p.FactorTOTP, "654321", 1},
+		{"WrongCode", p.FactorSMS, 2, p.FactorSMS, "********4567", true, p.FactorSMS, "000000", 0},
+		{"WrongTOTP", p.FactorTOTP, 2, p.FactorTOTP, "", false, p.FactorTOTP, "000000", 0},
+		// the code is bound to the factor selected at the first step
+		{"OtherFactor", p.FactorSMS, 2, p.FactorSMS, "********4567", true, p.FactorEmail, "", 0},
+		{"UnknownFactor", "voice", 0, "", "", false, "", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newUsers(user{id: "u1", username: "alice", email: "alice@example.com", password: "h:p0"})
+			box := &outbox{}
+			s := p.PasswordUseCase{
+				PasswordComparator:       plain{},
+				PasswordRepository:       repository,
+				PasswordChangeExpires:    300,
+				ChangePasscodeRepository: newCodes(),
+				SendChangeCode:           box.send,
+				ResolveFactors: func(ctx context.Context, id string) ([]p.Factor, error) {
+					return factors, nil
+				},
+				VerifyFactor: func(ctx context.Context, id string, factor p.Factor, code string) (bool, error) {
+					return factor.Type == p.FactorTOTP && code == "654321", nil
+				},
+			}
+			change := p.PasswordChange{Username: "alice", CurrentPassword: "p0", Password: "p1", Factor: tt.challenge}
+			result, err := s.ChangePasswordWithResult(ctx, change)
+			if err != nil || result.Status != tt.status || result.Factor != tt.factor || result.Destination != tt.destination {
+				t.Fatalf("ChangePasswordWithResult() = %+v, %v; want status %d, factor %q, destination %q", result, err, tt.status, tt.factor, tt.destination)
+			}
+			sent := box.codes()
+			if !tt.sent {
+				if len(sent) != 0 {
+					t.Errorf("sent %v; want no code", sent)
+				}
+			} else if len(sent) != 1 || sent[0].params != factors[indexOf(factors, tt.factor)] {
+				t.Fatalf("sent %v; want a code by the factor %s", sent, tt.factor)
+			}
+			if tt.status != 2 {
+				return
+			}
+			change.Step, change.Factor, change.Passcode = 1, tt.verify, tt.code
+			if len(tt.code) == 0 {
+				change.Passcode = sent[0].code
+			}
+			result, err = s.ChangePasswordWithResult(ctx, change)
+			if err != nil || result.Status != tt.want {
+				t.Fatalf("ChangePasswordWithResult(step 1) = %+v, %v; want status %d", result, err, tt.want)
+			}
+			if changed := repository.password("u1") == "h:p1"; changed != (tt.want == 1) {
+				t.Errorf("password = %q; changed %v, want %v", repository.password("u1"), changed, tt.want == 1)
+			}
+		})
+	}
+}
+
+func indexOf(factors []p.Factor, factorType string) int {
+	for i, factor := range factors {
+		if factor.Type == factorType {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,73 @@
+package fasthttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	p "github.com/core-go/password"
+	"github.com/fasthttp/router"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+type PasswordHandler struct {
+	p.HandlerCore
+}
+
+// NewHandler returns the handler of the service, with the options of HandlerCore, such as p.WithProblem.
+func NewHandler(service p.PasswordService, options ...p.HandlerOption) *PasswordHandler {
+	return &PasswordHandler{*p.NewHandlerCore(service, options...)}
+}
+
+func NewPasswordHandler(authenticationService p.PasswordService, logError func(context.Context, string, ...map[string]interface{}), writeLog func(context.Context, string, string, bool, string) error, options ...p.PasswordActionConfig) *PasswordHandler {
+	opts := []p.HandlerOption{p.WithError(logError), p.WithLog(writeLog)}
+	if len(options) >= 1 {
+		opts = append(opts, p.WithConfig(options[0]))
+	}
+	return NewHandler(authenticationService, opts...)
+}
+
+func (h *PasswordHandler) ChangePassword(ctx *fasthttp.RequestCtx) {
+	h.handle(ctx, h.Change)
+}
+func (h *PasswordHandler) ForgotPassword(ctx *fasthttp.RequestCtx) {
+	h.handle(ctx, h.Forgot)
+}
+func (h *PasswordHandler) ResetPassword(ctx *fasthttp.RequestCtx) {
+	h.handle(ctx, h.Reset)
+}
+func (h *PasswordHandler) DenyChange(ctx *fasthttp.RequestCtx) {
+	h.handle(ctx, h.Deny)
+}
+
+// RegisterRoutes registers the actions under the prefix, such as "/password":
+// POST change, GET forgot/{contact}, POST forgot, POST reset and POST deny.
+func RegisterRoutes(r *router.Router, prefix string, h *PasswordHandler) {
+	r.POST(prefix+"/change", h.ChangePassword)
+	r.GET(prefix+"/forgot/{contact}", h.ForgotPassword)
+	r.POST(prefix+"/forgot", h.ForgotPassword)
+	r.POST(prefix+"/reset", h.ResetPassword)
+	r.POST(prefix+"/deny", h.DenyChange)
+}
+
+func (h *PasswordHandler) handle(ctx *fasthttp.RequestCtx, action func(r *http.Request) p.Response) {
+	var r http.Request
+	if err := fasthttpadaptor.ConvertRequest(ctx, &r, true); err != nil {
+		ctx.Error(err.Error(), http.StatusInternalServerError)
+		return
+	}
+	res := action(r.WithContext(ctx))
+	if len(res.Text) > 0 {
+		ctx.Error(res.Text, res.Status)
+		h.WriteLog(&r, res)
+		return
+	}
+	ctx.SetContentType(res.ContentType)
+	ctx.SetStatusCode(res.Status)
+	if err := json.NewEncoder(ctx).Encode(res.Body); err != nil {
+		ctx.Error(err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.WriteLog(&r, res)
+}
@@ -0,0 +1,34 @@
+package fasthttp
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/testkit"
+	"github.com/fasthttp/router"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestHandlerContract(t *testing.T) {
+	testkit.RunHandlerContract(t, testkit.HandlerContract{
+		New: func(t *testing.T, service p.PasswordService, options ...p.HandlerOption) testkit.Handle {
+			r := router.New()
+			RegisterRoutes(r, "/password", NewHandler(service, options...))
+			ln := fasthttputil.NewInmemoryListener()
+			go fasthttp.Serve(ln, r.Handler)
+			t.Cleanup(func() { ln.Close() })
+			client := &http.Client{Transport: &http.Transport{
+				DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+					return ln.Dial()
+				},
+			}}
+			return func(t *testing.T, method string, path string, body string) (int, string, []byte) {
+				return testkit.Send(t, client, method, "http://password"+path, body)
+			}
+		},
+	})
+}
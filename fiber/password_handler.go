@@ -0,0 +1,66 @@
+package fiber
+
+import (
+	"context"
+	"net/http"
+
+	p "github.com/core-go/password"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+type PasswordHandler struct {
+	p.HandlerCore
+}
+
+// NewHandler returns the handler of the service, with the options of HandlerCore, such as p.WithProblem.
+func NewHandler(service p.PasswordService, options ...p.HandlerOption) *PasswordHandler {
+	return &PasswordHandler{*p.NewHandlerCore(service, options...)}
+}
+
+func NewPasswordHandler(authenticationService p.PasswordService, logError func(context.Context, string, ...map[string]interface{}), writeLog func(context.Context, string, string, bool, string) error, options ...p.PasswordActionConfig) *PasswordHandler {
+	opts := []p.HandlerOption{p.WithError(logError), p.WithLog(writeLog)}
+	if len(options) >= 1 {
+		opts = append(opts, p.WithConfig(options[0]))
+	}
+	return NewHandler(authenticationService, opts...)
+}
+
+func (h *PasswordHandler) ChangePassword(c *fiber.Ctx) error {
+	return h.handle(c, h.Change)
+}
+func (h *PasswordHandler) ForgotPassword(c *fiber.Ctx) error {
+	return h.handle(c, h.Forgot)
+}
+func (h *PasswordHandler) ResetPassword(c *fiber.Ctx) error {
+	return h.handle(c, h.Reset)
+}
+func (h *PasswordHandler) DenyChange(c *fiber.Ctx) error {
+	return h.handle(c, h.Deny)
+}
+
+// RegisterRoutes registers the actions under the prefix, such as "/password":
+// POST change, GET forgot/:contact, POST forgot, POST reset and POST deny.
+func RegisterRoutes(r fiber.Router, prefix string, h *PasswordHandler) {
+	r.Post(prefix+"/change", h.ChangePassword)
+	r.Get(prefix+"/forgot/:contact", h.ForgotPassword)
+	r.Post(prefix+"/forgot", h.ForgotPassword)
+	r.Post(prefix+"/reset", h.ResetPassword)
+	r.Post(prefix+"/deny", h.DenyChange)
+}
+
+func (h *PasswordHandler) handle(c *fiber.Ctx, action func(r *http.Request) p.Response) error {
+	r, err := adaptor.ConvertRequest(c, true)
+	if err != nil {
+		return err
+	}
+	res := action(r.WithContext(c.UserContext()))
+	if len(res.Text) > 0 {
+		err = c.Status(res.Status).SendString(res.Text)
+		h.WriteLog(r, res)
+		return err
+	}
+	err = c.Status(res.Status).JSON(res.Body, res.ContentType)
+	h.WriteLog(r, res)
+	return err
+}
@@ -0,0 +1,33 @@
+package fiber
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/testkit"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHandlerContract(t *testing.T) {
+	testkit.RunHandlerContract(t, testkit.HandlerContract{
+		New: func(t *testing.T, service p.PasswordService, options ...p.HandlerOption) testkit.Handle {
+			app := fiber.New()
+			RegisterRoutes(app, "/password", NewHandler(service, options...))
+			return func(t *testing.T, method string, path string, body string) (int, string, []byte) {
+				res, err := app.Test(httptest.NewRequest(method, path, strings.NewReader(body)), -1)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer res.Body.Close()
+				b, err := ioutil.ReadAll(res.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return res.StatusCode, res.Header.Get("Content-Type"), b
+			}
+		},
+	})
+}
@@ -16,6 +16,8 @@ type PasswordRepository struct {
 	PasswordCollection *firestore.CollectionRef
 	HistoryCollection  *firestore.CollectionRef
 	Key                string // User Id from context
+	TenantKey          string // Tenant Id from context
+	TenantName         string // the tenant field; when it is set, the documents are filtered by the tenant of the context
 	IdName             string
 	PasswordName       string
 	ToAddressName      string
@@ -33,7 +35,7 @@ func NewDefaultPasswordRepository(client *firestore.Client, userCollection, pass
 	if len(userName) == 0 {
 		userName = "username"
 	}
-	return NewPasswordRepository(client, userCollection, passwordCollection, historyCollectionName, key, "password", userId, "email", userName, changedTimeName, failCountName, "", "timestamp")
+	return NewPasswordRepository(client, userCollection, passwordCollection, historyCollectionName, key, userId, "password", toAddress, userName, changedTimeName, failCountName, "", "timestamp")
 }
 
 func NewPasswordRepositoryByConfig(client *firestore.Client, userCollectionName, passwordCollectionName, historyCollectionName string, key string, c p.PasswordSchemaConfig) *PasswordRepository {
@@ -56,6 +58,12 @@ func NewPasswordRepository(client *firestore.Client, userCollectionName, passwor
 	if len(userName) == 0 {
 		userName = "userName"
 	}
+	if len(userId) == 0 {
+		userId = "userId"
+	}
+	if len(timestampName) == 0 {
+		timestampName = "timestamp"
+	}
 	return &PasswordRepository{
 		Client:             client,
 		UserCollection:     userCollection,
@@ -74,7 +82,7 @@ func NewPasswordRepository(client *firestore.Client, userCollectionName, passwor
 }
 
 func (r *PasswordRepository) GetUserId(ctx context.Context, userName string) (string, error) {
-	docs, err := r.UserCollection.Where(r.Username, "==", userName).Limit(1).Documents(ctx).GetAll()
+	docs, err := whereTenant(ctx, r.TenantKey, r.TenantName, r.UserCollection.Where(r.Username, "==", userName)).Limit(1).Documents(ctx).GetAll()
 	if err != nil {
 		return "", err
 	}
@@ -86,12 +94,12 @@ func (r *PasswordRepository) GetUserId(ctx context.Context, userName string) (st
 }
 
 func (r *PasswordRepository) GetUser(ctx context.Context, usernameOrEmail string) (string, string, string, string, error) {
-	docs, er0 := r.UserCollection.Where(r.Username, "==", usernameOrEmail).Limit(1).Documents(ctx).GetAll()
+	docs, er0 := whereTenant(ctx, r.TenantKey, r.TenantName, r.UserCollection.Where(r.Username, "==", usernameOrEmail)).Limit(1).Documents(ctx).GetAll()
 	if er0 != nil {
 		return "", "", "", "", er0
 	}
 	if len(docs) == 0 {
-		docs, er1 := r.UserCollection.Where(r.ToAddressName, "==", usernameOrEmail).Limit(1).Documents(ctx).GetAll()
+		docs, er1 := whereTenant(ctx, r.TenantKey, r.TenantName, r.UserCollection.Where(r.ToAddressName, "==", usernameOrEmail)).Limit(1).Documents(ctx).GetAll()
 		if er1 != nil {
 			return "", "", "", "", er1
 		}
@@ -127,6 +135,9 @@ func (r *PasswordRepository) GetUser(ctx context.Context, usernameOrEmail string
 		}
 		return "", "", "", "", er5
 	}
+	if !inTenant(ctx, r.TenantKey, r.TenantName, pass) {
+		return userId, userName.(string), email.(string), "", nil
+	}
 	password, er6 := pass.DataAt(r.PasswordName)
 	return userId, userName.(string), email.(string), password.(string), er6
 }
@@ -148,6 +159,7 @@ func (r *PasswordRepository) Update(ctx context.Context, userId string, newPassw
 			pass[r.ChangedByName] = userId
 		}
 	}
+	setTenant(ctx, r.TenantKey, r.TenantName, pass)
 	_, err := r.PasswordCollection.Doc(userId).Set(ctx, pass, firestore.MergeAll)
 	if err != nil {
 		return 0, err
@@ -172,6 +184,7 @@ func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, user
 				pass[r.ChangedByName] = userId
 			}
 		}
+		setTenant(ctx, r.TenantKey, r.TenantName, pass)
 		err1 := tx.Set(r.PasswordCollection.Doc(userId), pass, firestore.MergeAll)
 		if err1 != nil {
 			return err1
@@ -181,6 +194,7 @@ func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, user
 		history[r.IdName] = userId
 		history[r.PasswordName] = currentPassword
 		history[r.TimestampName] = time.Now()
+		setTenant(ctx, r.TenantKey, r.TenantName, history)
 		return tx.Create(r.HistoryCollection.NewDoc(), history)
 	})
 	if err != nil {
@@ -191,25 +205,30 @@ func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, user
 
 func (r *PasswordRepository) GetHistory(ctx context.Context, userId string, max int) ([]string, error) {
 	history := make([]string, 0)
-	iter := r.HistoryCollection.Where(r.IdName, "==", userId).OrderBy(r.TimestampName, firestore.Desc).Limit(max).Offset(1).Documents(ctx)
-	defer iter.Stop()
-	result, err := iter.Next()
-	if err == iterator.Done {
+	if max <= 0 {
 		return history, nil
 	}
-	if err != nil {
-		return history, err
-	}
-	rawStatus := result.Data()
-	if rawStatus == nil {
-		return history, fmt.Errorf("user history not found")
-	}
-	if password, ok := rawStatus[r.PasswordName]; ok {
-		if p, k := password.(string); k {
-			history = append(history, p)
+	// The history keeps the replaced passwords, so the latest one is not the current password.
+	iter := whereTenant(ctx, r.TenantKey, r.TenantName, r.HistoryCollection.Where(r.IdName, "==", userId)).OrderBy(r.TimestampName, firestore.Desc).Limit(max).Documents(ctx)
+	defer iter.Stop()
+	for {
+		result, err := iter.Next()
+		if err == iterator.Done {
+			return history, nil
+		}
+		if err != nil {
+			return history, err
+		}
+		rawStatus := result.Data()
+		if rawStatus == nil {
+			return history, fmt.Errorf("user history not found")
+		}
+		if password, ok := rawStatus[r.PasswordName]; ok {
+			if p, k := password.(string); k {
+				history = append(history, p)
+			}
 		}
 	}
-	return history, nil
 }
 
 func getString(ctx context.Context, key string) string {
@@ -0,0 +1,68 @@
+//go:build integration
+
+package firestore
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	p "github.com/core-go/password"
+	"github.com/core-go/password/testkit"
+)
+
+// openClient connects to the emulator of FIRESTORE_EMULATOR_HOST. Each case has collections with a suffix of their own, so that it starts empty.
+// Run with: FIRESTORE_EMULATOR_HOST=localhost:8080 go test -tags integration ./firestore
+func openClient(t *testing.T) (*firestore.Client, string) {
+	if len(os.Getenv("FIRESTORE_EMULATOR_HOST")) == 0 {
+		t.Skip("FIRESTORE_EMULATOR_HOST is not set")
+	}
+	client, err := firestore.NewClient(context.Background(), "password-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+	})
+	return client, strconv.FormatInt(time.Now().UnixNano(), 10)
+}
+
+func TestPasswordRepositoryContract(t *testing.T) {
+	testkit.RunPasswordRepositoryContract(t, testkit.PasswordRepositoryContract{
+		Key: "userId",
+		New: func(t *testing.T, users []testkit.User) p.PasswordRepository {
+			client, suffix := openClient(t)
+			ctx := context.Background()
+			for _, u := range users {
+				if _, err := client.Collection("users"+suffix).Doc(u.Id).Set(ctx, map[string]interface{}{"username": u.Username, "email": u.Email}); err != nil {
+					t.Fatal(err)
+				}
+				if len(u.Password) > 0 {
+					if _, err := client.Collection("passwords"+suffix).Doc(u.Id).Set(ctx, map[string]interface{}{"password": u.Password, "failCount": u.FailCount}); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			return NewPasswordRepository(client, "users"+suffix, "passwords"+suffix, "histories"+suffix, "userId", "userId", "password", "email", "username", "changedTime", "failCount", "changedBy", "timestamp")
+		},
+		Inspect: func(t *testing.T, repository p.PasswordRepository, id string) (string, int) {
+			doc, err := repository.(*PasswordRepository).PasswordCollection.Doc(id).Get(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			changedBy, _ := doc.Data()["changedBy"].(string)
+			failCount, _ := doc.Data()["failCount"].(int64)
+			return changedBy, int(failCount)
+		},
+	})
+}
+
+func TestVerificationCodeRepositoryContract(t *testing.T) {
+	testkit.RunVerificationCodeRepositoryContract(t, func(t *testing.T) p.VerificationCodeRepository {
+		client, suffix := openClient(t)
+		return NewDefaultVerificationCodeRepository(client, "codes"+suffix)
+	})
+}
@@ -0,0 +1,96 @@
+package firestore
+
+import (
+	"context"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+)
+
+type RecoveryCodeRepository struct {
+	Client     *firestore.Client
+	Collection *firestore.CollectionRef
+	CodeName   string
+	TenantKey  string // Tenant Id from context
+	TenantName string // the tenant field; when it is set, the codes are filtered by the tenant of the context, and the ids must be unique across the tenants
+}
+
+func NewDefaultRecoveryCodeRepository(client *firestore.Client, collectionName string) *RecoveryCodeRepository {
+	return NewRecoveryCodeRepository(client, collectionName, "codes")
+}
+
+func NewRecoveryCodeRepository(client *firestore.Client, collectionName, codeName string) *RecoveryCodeRepository {
+	if len(codeName) == 0 {
+		codeName = "codes"
+	}
+	return &RecoveryCodeRepository{Client: client, Collection: client.Collection(collectionName), CodeName: codeName}
+}
+
+func (r *RecoveryCodeRepository) Save(ctx context.Context, id string, codes []string) (int64, error) {
+	doc := map[string]interface{}{r.CodeName: codes}
+	setTenant(ctx, r.TenantKey, r.TenantName, doc)
+	_, err := r.Collection.Doc(id).Set(ctx, doc)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(codes)), nil
+}
+
+func (r *RecoveryCodeRepository) Load(ctx context.Context, id string) ([]string, error) {
+	doc, err := r.Collection.Doc(id).Get(ctx)
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFound") {
+			return make([]string, 0), nil
+		}
+		return make([]string, 0), err
+	}
+	if !inTenant(ctx, r.TenantKey, r.TenantName, doc) {
+		return make([]string, 0), nil
+	}
+	return getCodes(doc, r.CodeName), nil
+}
+
+func (r *RecoveryCodeRepository) Delete(ctx context.Context, id string, code string) (int64, error) {
+	var count int64
+	ref := r.Collection.Doc(id)
+	err := r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		count = 0
+		doc, er1 := tx.Get(ref)
+		if er1 != nil {
+			if strings.Contains(er1.Error(), "NotFound") {
+				return nil
+			}
+			return er1
+		}
+		if !inTenant(ctx, r.TenantKey, r.TenantName, doc) {
+			return nil
+		}
+		for _, c := range getCodes(doc, r.CodeName) {
+			if c == code {
+				count = 1
+				return tx.Update(ref, []firestore.Update{{Path: r.CodeName, Value: firestore.ArrayRemove(code)}})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func getCodes(doc *firestore.DocumentSnapshot, codeName string) []string {
+	codes := make([]string, 0)
+	v, err := doc.DataAt(codeName)
+	if err != nil {
+		return codes
+	}
+	if arr, ok := v.([]interface{}); ok {
+		for _, c := range arr {
+			if s, ok := c.(string); ok {
+				codes = append(codes, s)
+			}
+		}
+	}
+	return codes
+}
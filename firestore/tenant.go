@@ -0,0 +1,35 @@
+package firestore
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+)
+
+// whereTenant filters the query by the tenant of the context, when tenantName is set, which means the collection is multi-tenant.
+func whereTenant(ctx context.Context, tenantKey string, tenantName string, q firestore.Query) firestore.Query {
+	if len(tenantName) == 0 {
+		return q
+	}
+	return q.Where(tenantName, "==", getString(ctx, tenantKey))
+}
+
+// setTenant sets the tenant of the context to the document, when tenantName is set.
+func setTenant(ctx context.Context, tenantKey string, tenantName string, m map[string]interface{}) {
+	if len(tenantName) > 0 {
+		m[tenantName] = getString(ctx, tenantKey)
+	}
+}
+
+// inTenant returns false if the document belongs to another tenant than the one of the context.
+func inTenant(ctx context.Context, tenantKey string, tenantName string, doc *firestore.DocumentSnapshot) bool {
+	if len(tenantName) == 0 {
+		return true
+	}
+	v, err := doc.DataAt(tenantName)
+	if err != nil {
+		return false
+	}
+	tenant, _ := v.(string)
+	return tenant == getString(ctx, tenantKey)
+}
@@ -0,0 +1,95 @@
+package firestore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	p "github.com/core-go/password"
+)
+
+type VerificationCodeRepository struct {
+	Client        *firestore.Client
+	Collection    *firestore.CollectionRef
+	PasscodeName  string
+	ExpiredAtName string
+	TenantKey     string // Tenant Id from context
+	TenantName    string // the tenant field; when it is set, the codes are filtered by the tenant of the context, and the ids must be unique across the tenants
+}
+
+func NewVerificationCodeRepositoryByConfig(client *firestore.Client, collectionName string, c p.VerificationCodeSchemaConfig) *VerificationCodeRepository {
+	return NewVerificationCodeRepository(client, collectionName, c.Passcode, c.ExpiredAt)
+}
+
+func NewDefaultVerificationCodeRepository(client *firestore.Client, collectionName string) *VerificationCodeRepository {
+	return NewVerificationCodeRepository(client, collectionName, "passcode", "expiredAt")
+}
+
+// NewVerificationCodeRepository uses the id as the document id.
+// expiredAtName is stored as a timestamp, so that it can be used by a TTL policy of the collection.
+func NewVerificationCodeRepository(client *firestore.Client, collectionName, passcodeName, expiredAtName string) *VerificationCodeRepository {
+	if len(passcodeName) == 0 {
+		passcodeName = "passcode"
+	}
+	if len(expiredAtName) == 0 {
+		expiredAtName = "expiredAt"
+	}
+	return &VerificationCodeRepository{Client: client, Collection: client.Collection(collectionName), PasscodeName: passcodeName, ExpiredAtName: expiredAtName}
+}
+
+func (r *VerificationCodeRepository) Save(ctx context.Context, id string, passcode string, expireAt time.Time) (int64, error) {
+	doc := map[string]interface{}{r.PasscodeName: passcode, r.ExpiredAtName: expireAt}
+	setTenant(ctx, r.TenantKey, r.TenantName, doc)
+	_, err := r.Collection.Doc(id).Set(ctx, doc)
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (r *VerificationCodeRepository) Load(ctx context.Context, id string) (string, time.Time, error) {
+	doc, err := r.Collection.Doc(id).Get(ctx)
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFound") {
+			return "", time.Time{}, nil
+		}
+		return "", time.Time{}, err
+	}
+	if !inTenant(ctx, r.TenantKey, r.TenantName, doc) {
+		return "", time.Time{}, nil
+	}
+	var passcode string
+	var expiredAt time.Time
+	if v, er1 := doc.DataAt(r.PasscodeName); er1 == nil {
+		passcode, _ = v.(string)
+	}
+	if v, er2 := doc.DataAt(r.ExpiredAtName); er2 == nil {
+		expiredAt, _ = v.(time.Time)
+	}
+	return passcode, expiredAt, nil
+}
+
+func (r *VerificationCodeRepository) Delete(ctx context.Context, id string) (int64, error) {
+	var count int64
+	ref := r.Collection.Doc(id)
+	err := r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		count = 0
+		doc, er1 := tx.Get(ref)
+		if er1 != nil {
+			if strings.Contains(er1.Error(), "NotFound") {
+				return nil
+			}
+			return er1
+		}
+		if !inTenant(ctx, r.TenantKey, r.TenantName, doc) {
+			return nil
+		}
+		count = 1
+		return tx.Delete(ref)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,25 @@
+package gin
+
+import (
+	"testing"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/testkit"
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandlerContract(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testkit.RunHandlerContract(t, testkit.HandlerContract{
+		New: func(t *testing.T, service p.PasswordService, options ...p.HandlerOption) testkit.Handle {
+			h := NewHandler(service, options...)
+			r := gin.New()
+			r.POST("/password/change", h.ChangePassword)
+			r.GET("/password/forgot/:contact", h.ForgotPassword)
+			r.POST("/password/forgot", h.ForgotPassword)
+			r.POST("/password/reset", h.ResetPassword)
+			r.POST("/password/deny", h.DenyChange)
+			return testkit.ServeHTTP(r)
+		},
+	})
+}
@@ -0,0 +1,118 @@
+package grpc
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/grpc/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// InterceptorConfig is the config of UnaryServerInterceptor.
+type InterceptorConfig struct {
+	TenantKey    string // the key of the tenant in the context of the service
+	TenantHeader string // the metadata of the tenant, "x-tenant-id" by default
+	// RateLimiter limits the requests by the IP address of the client; the requests which are not allowed fail with ResourceExhausted.
+	RateLimiter p.RateLimiter
+	// TrustedProxies are the proxies whose x-forwarded-for and x-real-ip metadata are used for the IP address of the client; the other requests use the address of the peer.
+	TrustedProxies []netip.Prefix
+	// UserKey and GetUser bind ChangePassword to the authenticated user, like WithRequestUser of the handlers: GetUser returns the id of the user of the metadata, such as the subject of a verified token,
+	// or the id is taken from the context with UserKey, which is put by an authentication interceptor, if GetUser is nil. The changes without a user fail with Unauthenticated,
+	// and the changes of the passwords of the other users with PermissionDenied, unless IsAdmin returns true. Without them, ChangePassword is not bound to a user.
+	UserKey string
+	GetUser func(ctx context.Context) string
+	IsAdmin func(ctx context.Context) bool
+}
+
+// servicePrefix is the prefix of the full methods of PasswordService; the interceptor does not handle the other services of the server.
+const servicePrefix = "/password.v1.PasswordService/"
+
+// UnaryServerInterceptor puts the client and the locale of the metadata into the context, like BuildContext for the HTTP handlers, so that they are in the audit events,
+// and the tenant of the metadata, when TenantKey is set; the requests with a tenant which is not valid fail with InvalidArgument.
+// When UserKey or GetUser is set, it puts the authenticated user of ChangePassword into the context with p.WithPrincipal.
+// It handles the methods of PasswordService only, so that the rate limiter and the tenant do not apply to the other services of the same server.
+func UnaryServerInterceptor(options ...InterceptorConfig) grpc.UnaryServerInterceptor {
+	var c InterceptorConfig
+	if len(options) >= 1 {
+		c = options[0]
+	}
+	if len(c.TenantHeader) == 0 {
+		c.TenantHeader = strings.ToLower(p.HeaderTenant)
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, servicePrefix) {
+			return handler(ctx, req)
+		}
+		ctx = BuildContext(ctx, c.TrustedProxies...)
+		if c.RateLimiter != nil {
+			ip, _ := p.GetClient(ctx)
+			allowed, err := c.RateLimiter.Allow(ctx, "grpc:"+ip)
+			if err != nil {
+				return nil, Error(err)
+			}
+			if !allowed {
+				return nil, status.Error(codes.ResourceExhausted, p.ErrTooManyRequests.Error())
+			}
+		}
+		if len(c.TenantKey) > 0 {
+			if tenant := get(ctx, c.TenantHeader); len(tenant) > 0 {
+				if !p.ValidTenant(tenant) {
+					return nil, status.Error(codes.InvalidArgument, p.ErrInvalidTenant.Error())
+				}
+				ctx = context.WithValue(ctx, c.TenantKey, tenant)
+			}
+		}
+		if info.FullMethod == pb.PasswordService_ChangePassword_FullMethodName && (len(c.UserKey) > 0 || c.GetUser != nil) {
+			var ok bool
+			if ctx, ok = principal(ctx, c); !ok {
+				return nil, status.Error(codes.Unauthenticated, "authentication is required")
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// principal adds the authenticated user to the context, by GetUser or from the context with UserKey; it returns false if there is no authenticated user.
+func principal(ctx context.Context, c InterceptorConfig) (context.Context, bool) {
+	var id string
+	if c.GetUser != nil {
+		id = c.GetUser(ctx)
+		if len(id) > 0 && len(c.UserKey) > 0 {
+			ctx = context.WithValue(ctx, c.UserKey, id)
+		}
+	} else if len(c.UserKey) > 0 {
+		id, _ = ctx.Value(c.UserKey).(string)
+	}
+	if len(id) == 0 {
+		return ctx, false
+	}
+	return p.WithPrincipal(ctx, id, c.IsAdmin != nil && c.IsAdmin(ctx)), true
+}
+
+// BuildContext returns the context with the locale of the accept-language metadata, and the client: the IP address of ClientIP and the user agent.
+func BuildContext(ctx context.Context, trustedProxies ...netip.Prefix) context.Context {
+	ctx = p.WithLocale(ctx, p.ParseAcceptLanguage(get(ctx, "accept-language")))
+	return p.WithClient(ctx, ClientIP(ctx, trustedProxies...), get(ctx, "user-agent"))
+}
+
+// ClientIP returns the address of the peer, or the address of the x-forwarded-for or x-real-ip metadata when the peer is one of the trusted proxies, like ClientIP of the HTTP handlers.
+func ClientIP(ctx context.Context, trustedProxies ...netip.Prefix) string {
+	remoteAddr := ""
+	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+		remoteAddr = pr.Addr.String()
+	}
+	return p.ForwardedIP(remoteAddr, strings.Join(metadata.ValueFromIncomingContext(ctx, "x-forwarded-for"), ","), get(ctx, "x-real-ip"), trustedProxies)
+}
+
+func get(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
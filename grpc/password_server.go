@@ -0,0 +1,85 @@
+package grpc
+
+import (
+	"context"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/grpc/pb"
+)
+
+// PasswordServer is the gRPC server of PasswordService; the failures are returned as the statuses of Status and Error.
+type PasswordServer struct {
+	pb.UnimplementedPasswordServiceServer
+	PasswordService p.PasswordService
+	Error           func(context.Context, string, ...map[string]interface{})
+}
+
+func NewPasswordServer(service p.PasswordService, options ...func(context.Context, string, ...map[string]interface{})) *PasswordServer {
+	var logError func(context.Context, string, ...map[string]interface{})
+	if len(options) >= 1 {
+		logError = options[0]
+	}
+	return &PasswordServer{PasswordService: service, Error: logError}
+}
+
+func (s *PasswordServer) ForgotPassword(ctx context.Context, req *pb.ForgotPasswordRequest) (*pb.ForgotPasswordResponse, error) {
+	sent, err := s.PasswordService.ForgotPassword(ctx, req.GetContact())
+	if err != nil {
+		return nil, s.error(ctx, err)
+	}
+	return &pb.ForgotPasswordResponse{Sent: sent}, nil
+}
+
+func (s *PasswordServer) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.PasswordResult, error) {
+	reset := p.PasswordReset{Username: req.GetUsername(), Passcode: req.GetPasscode(), Password: req.GetPassword(), Factor: req.GetFactor()}
+	result, err := p.ResultService(s.PasswordService).ResetPasswordWithResult(ctx, reset)
+	return s.result(ctx, result, err)
+}
+
+// ChangePassword changes the password of the username of the request; it is bound to the authenticated user only when the context has a principal,
+// such as by the UserKey or GetUser of InterceptorConfig, or by an authentication interceptor which calls p.WithPrincipal.
+func (s *PasswordServer) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.PasswordResult, error) {
+	change := p.PasswordChange{
+		Step:            int(req.GetStep()),
+		Username:        req.GetUsername(),
+		Passcode:        req.GetPasscode(),
+		CurrentPassword: req.GetCurrentPassword(),
+		Password:        req.GetPassword(),
+		Sender:          req.GetSender(),
+		Factor:          req.GetFactor(),
+	}
+	result, err := p.ResultService(s.PasswordService).ChangePasswordWithResult(ctx, change)
+	return s.result(ctx, result, err)
+}
+
+func (s *PasswordServer) DenyChange(ctx context.Context, req *pb.DenyChangeRequest) (*pb.PasswordResult, error) {
+	result, err := p.ResultService(s.PasswordService).DenyChange(ctx, p.PasswordDeny{Username: req.GetUsername(), Passcode: req.GetPasscode()})
+	return s.result(ctx, result, err)
+}
+
+// ValidatePassword returns Unimplemented if the service is not a PasswordValidator.
+func (s *PasswordServer) ValidatePassword(ctx context.Context, req *pb.ValidatePasswordRequest) (*pb.PasswordResult, error) {
+	validator, ok := s.PasswordService.(p.PasswordValidator)
+	if !ok {
+		return nil, Error(p.ErrValidationNotSupported)
+	}
+	result, err := validator.ValidatePassword(ctx, req.GetUsername(), req.GetPassword())
+	return s.result(ctx, result, err)
+}
+
+func (s *PasswordServer) result(ctx context.Context, result p.PasswordResult, err error) (*pb.PasswordResult, error) {
+	if err != nil {
+		return nil, s.error(ctx, err)
+	}
+	if st := Status(result); st != nil {
+		return nil, st.Err()
+	}
+	return ToResult(result), nil
+}
+
+func (s *PasswordServer) error(ctx context.Context, err error) error {
+	if s.Error != nil {
+		s.Error(ctx, err.Error())
+	}
+	return Error(err)
+}
@@ -0,0 +1,300 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/grpc/pb"
+	"github.com/core-go/password/memory"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dial serves the service with the interceptor on a bufconn listener, and returns the client.
+func dial(t *testing.T, service p.PasswordService, c InterceptorConfig) pb.PasswordServiceClient {
+	t.Helper()
+	ln := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.UnaryInterceptor(UnaryServerInterceptor(c)))
+	pb.RegisterPasswordServiceServer(server, NewPasswordServer(service))
+	go server.Serve(ln)
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUserAgent("password-test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewPasswordServiceClient(conn)
+}
+
+type comparator struct{}
+
+func (c comparator) Compare(plaintext string, hashed string) (bool, error) {
+	return plaintext == hashed, nil
+}
+func (c comparator) Hash(plaintext string) (string, error) {
+	return plaintext, nil
+}
+
+// contextService keeps the context of the last ForgotPassword; it is not a PasswordValidator.
+type contextService struct {
+	mu  sync.Mutex
+	ctx context.Context
+}
+
+func (s *contextService) ForgotPassword(ctx context.Context, contact string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx = ctx
+	return true, nil
+}
+func (s *contextService) ResetPassword(ctx context.Context, pass p.PasswordReset) (int32, error) {
+	return 0, nil
+}
+func (s *contextService) ChangePassword(ctx context.Context, pass p.PasswordChange) (int32, error) {
+	return 0, nil
+}
+
+// limiter allows the first requests of each key, up to limit.
+type limiter struct {
+	mu    sync.Mutex
+	limit int
+	keys  map[string]int
+}
+
+func (l *limiter) Allow(ctx context.Context, key string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.keys[key]++
+	return l.keys[key] <= l.limit, nil
+}
+
+func TestCode(t *testing.T) {
+	cases := []struct {
+		result p.PasswordResult
+		want   codes.Code
+	}{
+		{p.PasswordResult{Status: 1}, codes.OK},
+		{p.PasswordResult{Status: 2, Factor: "email"}, codes.OK},
+		{p.PasswordResult{Status: -1}, codes.AlreadyExists},
+		{p.PasswordResult{Status: -2}, codes.InvalidArgument},
+		{p.PasswordResult{Code: p.MessageExpired}, codes.FailedPrecondition},
+		{p.PasswordResult{Code: p.MessageInvalid}, codes.Unauthenticated},
+	}
+	for _, c := range cases {
+		if got := Code(c.result); got != c.want {
+			t.Errorf("Code(%+v) = %v; want %v", c.result, got, c.want)
+		}
+		st := Status(c.result)
+		if c.want == codes.OK {
+			if st != nil {
+				t.Errorf("Status(%+v) = %v; want nil", c.result, st)
+			}
+			continue
+		}
+		if st.Code() != c.want || len(st.Details()) != 1 {
+			t.Errorf("Status(%+v) = %v with %d details; want %v with the result", c.result, st.Code(), len(st.Details()), c.want)
+		}
+	}
+	errs := map[error]codes.Code{
+		p.ErrForbidden:              codes.PermissionDenied,
+		p.ErrTooManyRequests:        codes.ResourceExhausted,
+		p.ErrValidationNotSupported: codes.Unimplemented,
+		p.ErrDenyNotSupported:       codes.Unimplemented,
+		context.DeadlineExceeded:    codes.DeadlineExceeded,
+		net.ErrClosed:               codes.Internal,
+	}
+	for err, want := range errs {
+		if got := status.Code(Error(err)); got != want {
+			t.Errorf("Error(%v) = %v; want %v", err, got, want)
+		}
+	}
+}
+
+func TestChangePasswordTwoSteps(t *testing.T) {
+	repository := memory.NewPasswordRepository("userId", 5, memory.User{Id: "u1", Username: "alice", Email: "alice@example.com", Password: "h0"})
+	var sent string
+	send := func(ctx context.Context, to string, code string, expireAt time.Time, params interface{}) error {
+		sent = code
+		return nil
+	}
+	service := p.New(repository, comparator{},
+		p.WithChange(300, memory.NewVerificationCodeRepository(), send),
+		p.WithRequireTwoFactors(func(ctx context.Context, id string) (bool, error) { return true, nil }))
+	client := dial(t, service, InterceptorConfig{})
+	ctx := context.Background()
+
+	challenge, err := client.ChangePassword(ctx, &pb.ChangePasswordRequest{Username: "alice", CurrentPassword: "h0", Password: "h1"})
+	if err != nil || challenge.GetStatus() != 2 || len(sent) == 0 {
+		t.Fatalf("ChangePassword(step 0) = %v, %v with the code %q; want the status 2 and a code", challenge, err, sent)
+	}
+	_, err = client.ChangePassword(ctx, &pb.ChangePasswordRequest{Step: 1, Username: "alice", CurrentPassword: "h0", Password: "h1", Passcode: "wrong"})
+	st := status.Convert(err)
+	if st.Code() != codes.Unauthenticated || len(st.Details()) != 1 {
+		t.Fatalf("ChangePassword(wrong code) = %v; want Unauthenticated with the result", err)
+	}
+	if result, ok := st.Details()[0].(*pb.PasswordResult); !ok || result.GetStatus() != 0 {
+		t.Errorf("details = %v; want the result with the status 0", st.Details())
+	}
+
+	if _, err = client.ChangePassword(ctx, &pb.ChangePasswordRequest{Username: "alice", CurrentPassword: "h0", Password: "h1"}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := client.ChangePassword(ctx, &pb.ChangePasswordRequest{Step: 1, Username: "alice", CurrentPassword: "h0", Password: "h1", Passcode: sent})
+	if err != nil || result.GetStatus() != 1 {
+		t.Fatalf("ChangePassword(step 1) = %v, %v; want the status 1", result, err)
+	}
+	if user, _ := repository.User("u1"); user.Password != "h1" {
+		t.Errorf("password = %q; want h1", user.Password)
+	}
+}
+
+func TestValidatePasswordUnimplemented(t *testing.T) {
+	client := dial(t, &contextService{}, InterceptorConfig{})
+	_, err := client.ValidatePassword(context.Background(), &pb.ValidatePasswordRequest{Username: "alice", Password: "h1"})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("ValidatePassword() = %v; want Unimplemented", err)
+	}
+}
+
+func TestInterceptorMetadata(t *testing.T) {
+	service := &contextService{}
+	client := dial(t, service, InterceptorConfig{TenantKey: "tenant"})
+	md := metadata.Pairs("x-forwarded-for", "203.0.113.9", "accept-language", "vi-VN,vi;q=0.9", "x-tenant-id", "acme")
+	if _, err := client.ForgotPassword(metadata.NewOutgoingContext(context.Background(), md), &pb.ForgotPasswordRequest{Contact: "alice@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	ip, userAgent := p.GetClient(service.ctx)
+	if ip == "203.0.113.9" {
+		t.Errorf("client IP = %q; x-forwarded-for must not be trusted without TrustedProxies", ip)
+	}
+	if !strings.HasPrefix(userAgent, "password-test") {
+		t.Errorf("user agent = %q; want password-test", userAgent)
+	}
+	if locale := p.GetLocale(service.ctx); locale != "vi-VN" {
+		t.Errorf("locale = %q; want vi-VN", locale)
+	}
+	if tenant, _ := service.ctx.Value("tenant").(string); tenant != "acme" {
+		t.Errorf("tenant = %q; want acme", tenant)
+	}
+
+	md = metadata.Pairs("x-tenant-id", "../acme")
+	_, err := client.ForgotPassword(metadata.NewOutgoingContext(context.Background(), md), &pb.ForgotPasswordRequest{Contact: "alice@example.com"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ForgotPassword(invalid tenant) = %v; want InvalidArgument", err)
+	}
+}
+
+func TestInterceptorRateLimiter(t *testing.T) {
+	l := &limiter{limit: 2, keys: make(map[string]int)}
+	client := dial(t, &contextService{}, InterceptorConfig{RateLimiter: l})
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := client.ForgotPassword(ctx, &pb.ForgotPasswordRequest{Contact: "alice@example.com"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := client.ForgotPassword(ctx, &pb.ForgotPasswordRequest{Contact: "alice@example.com"})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("ForgotPassword(third) = %v; want ResourceExhausted", err)
+	}
+	if len(l.keys) != 1 {
+		t.Errorf("keys = %v; want one key by the IP of the client", l.keys)
+	}
+}
+
+func TestInterceptorOtherServices(t *testing.T) {
+	l := &limiter{limit: 0, keys: make(map[string]int)}
+	interceptor := UnaryServerInterceptor(InterceptorConfig{TenantKey: "tenant", RateLimiter: l})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant-id", "acme#other"))
+	tests := []struct {
+		method string
+		code   codes.Code
+	}{
+		{"/grpc.health.v1.Health/Check", codes.OK},
+		{"/password.v1.PasswordServiceX/ForgotPassword", codes.OK},
+		{pb.PasswordService_ForgotPassword_FullMethodName, codes.ResourceExhausted},
+	}
+	for _, tt := range tests {
+		called := false
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			called = true
+			if tenant := ctx.Value("tenant"); tenant != nil {
+				t.Errorf("%s: tenant = %v; want no tenant for the other services", tt.method, tenant)
+			}
+			return nil, nil
+		})
+		if status.Code(err) != tt.code || called != (tt.code == codes.OK) {
+			t.Errorf("%s: error = %v, called = %v; want %v", tt.method, err, called, tt.code)
+		}
+	}
+	if len(l.keys) != 1 {
+		t.Errorf("keys = %v; want the limiter to be called for PasswordService only", l.keys)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-forwarded-for", "198.51.100.7, 203.0.113.9", "x-real-ip", "192.0.2.1"))
+	if ip := ClientIP(ctx); ip != "10.0.0.1" {
+		t.Errorf("ClientIP() = %q; want the address of the peer", ip)
+	}
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	if ip := ClientIP(ctx, proxies...); ip != "203.0.113.9" {
+		t.Errorf("ClientIP(trusted) = %q; want the right-most address of x-forwarded-for", ip)
+	}
+	ctx = peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-real-ip", "192.0.2.1"))
+	if ip := ClientIP(ctx, proxies...); ip != "192.0.2.1" {
+		t.Errorf("ClientIP(trusted, x-real-ip) = %q; want 192.0.2.1", ip)
+	}
+}
+
+func TestChangePasswordBoundToUser(t *testing.T) {
+	repository := memory.NewPasswordRepository("userId", 5,
+		memory.User{Id: "u1", Username: "alice", Email: "alice@example.com", Password: "h0"},
+		memory.User{Id: "u2", Username: "bob", Email: "bob@example.com", Password: "h0"})
+	getUser := func(ctx context.Context) string {
+		return get(ctx, "x-user-id")
+	}
+	client := dial(t, p.New(repository, comparator{}), InterceptorConfig{UserKey: "userId", GetUser: getUser})
+	change := func(user string, username string) error {
+		ctx := context.Background()
+		if len(user) > 0 {
+			ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("x-user-id", user))
+		}
+		_, err := client.ChangePassword(ctx, &pb.ChangePasswordRequest{Username: username, CurrentPassword: "h0", Password: "h1"})
+		return err
+	}
+	if err := change("", "alice"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("ChangePassword(no user) = %v; want Unauthenticated", err)
+	}
+	if err := change("u2", "alice"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ChangePassword(another user) = %v; want PermissionDenied", err)
+	}
+	if err := change("u2", "nobody"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ChangePassword(unknown user) = %v; want PermissionDenied, like another user", err)
+	}
+	if err := change("alice", "alice"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ChangePassword(user by the username) = %v; want PermissionDenied", err)
+	}
+	if err := change("u1", "alice"); err != nil {
+		t.Fatalf("ChangePassword(own) = %v", err)
+	}
+	if user, _ := repository.User("u1"); user.Password != "h1" || user.ChangedBy != "u1" {
+		t.Errorf("user = %+v; want the password h1 changed by u1", user)
+	}
+}
@@ -0,0 +1,556 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: password.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ForgotPasswordRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Contact       string                 `protobuf:"bytes,1,opt,name=contact,proto3" json:"contact,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForgotPasswordRequest) Reset() {
+	*x = ForgotPasswordRequest{}
+	mi := &file_password_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForgotPasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForgotPasswordRequest) ProtoMessage() {}
+
+func (x *ForgotPasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_password_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForgotPasswordRequest.ProtoReflect.Descriptor instead.
+func (*ForgotPasswordRequest) Descriptor() ([]byte, []int) {
+	return file_password_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ForgotPasswordRequest) GetContact() string {
+	if x != nil {
+		return x.Contact
+	}
+	return ""
+}
+
+type ForgotPasswordResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sent          bool                   `protobuf:"varint,1,opt,name=sent,proto3" json:"sent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForgotPasswordResponse) Reset() {
+	*x = ForgotPasswordResponse{}
+	mi := &file_password_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForgotPasswordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForgotPasswordResponse) ProtoMessage() {}
+
+func (x *ForgotPasswordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_password_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForgotPasswordResponse.ProtoReflect.Descriptor instead.
+func (*ForgotPasswordResponse) Descriptor() ([]byte, []int) {
+	return file_password_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ForgotPasswordResponse) GetSent() bool {
+	if x != nil {
+		return x.Sent
+	}
+	return false
+}
+
+type ResetPasswordRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Passcode      string                 `protobuf:"bytes,2,opt,name=passcode,proto3" json:"passcode,omitempty"`
+	Password      string                 `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
+	Factor        string                 `protobuf:"bytes,4,opt,name=factor,proto3" json:"factor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetPasswordRequest) Reset() {
+	*x = ResetPasswordRequest{}
+	mi := &file_password_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetPasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetPasswordRequest) ProtoMessage() {}
+
+func (x *ResetPasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_password_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetPasswordRequest.ProtoReflect.Descriptor instead.
+func (*ResetPasswordRequest) Descriptor() ([]byte, []int) {
+	return file_password_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ResetPasswordRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *ResetPasswordRequest) GetPasscode() string {
+	if x != nil {
+		return x.Passcode
+	}
+	return ""
+}
+
+func (x *ResetPasswordRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *ResetPasswordRequest) GetFactor() string {
+	if x != nil {
+		return x.Factor
+	}
+	return ""
+}
+
+type ChangePasswordRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Step            int32                  `protobuf:"varint,1,opt,name=step,proto3" json:"step,omitempty"`
+	Username        string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	Passcode        string                 `protobuf:"bytes,3,opt,name=passcode,proto3" json:"passcode,omitempty"`
+	CurrentPassword string                 `protobuf:"bytes,4,opt,name=current_password,json=currentPassword,proto3" json:"current_password,omitempty"`
+	Password        string                 `protobuf:"bytes,5,opt,name=password,proto3" json:"password,omitempty"`
+	Sender          string                 `protobuf:"bytes,6,opt,name=sender,proto3" json:"sender,omitempty"`
+	Factor          string                 `protobuf:"bytes,7,opt,name=factor,proto3" json:"factor,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ChangePasswordRequest) Reset() {
+	*x = ChangePasswordRequest{}
+	mi := &file_password_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangePasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangePasswordRequest) ProtoMessage() {}
+
+func (x *ChangePasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_password_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangePasswordRequest.ProtoReflect.Descriptor instead.
+func (*ChangePasswordRequest) Descriptor() ([]byte, []int) {
+	return file_password_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ChangePasswordRequest) GetStep() int32 {
+	if x != nil {
+		return x.Step
+	}
+	return 0
+}
+
+func (x *ChangePasswordRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *ChangePasswordRequest) GetPasscode() string {
+	if x != nil {
+		return x.Passcode
+	}
+	return ""
+}
+
+func (x *ChangePasswordRequest) GetCurrentPassword() string {
+	if x != nil {
+		return x.CurrentPassword
+	}
+	return ""
+}
+
+func (x *ChangePasswordRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *ChangePasswordRequest) GetSender() string {
+	if x != nil {
+		return x.Sender
+	}
+	return ""
+}
+
+func (x *ChangePasswordRequest) GetFactor() string {
+	if x != nil {
+		return x.Factor
+	}
+	return ""
+}
+
+type DenyChangeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Passcode      string                 `protobuf:"bytes,2,opt,name=passcode,proto3" json:"passcode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DenyChangeRequest) Reset() {
+	*x = DenyChangeRequest{}
+	mi := &file_password_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DenyChangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DenyChangeRequest) ProtoMessage() {}
+
+func (x *DenyChangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_password_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DenyChangeRequest.ProtoReflect.Descriptor instead.
+func (*DenyChangeRequest) Descriptor() ([]byte, []int) {
+	return file_password_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DenyChangeRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *DenyChangeRequest) GetPasscode() string {
+	if x != nil {
+		return x.Passcode
+	}
+	return ""
+}
+
+type ValidatePasswordRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidatePasswordRequest) Reset() {
+	*x = ValidatePasswordRequest{}
+	mi := &file_password_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidatePasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidatePasswordRequest) ProtoMessage() {}
+
+func (x *ValidatePasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_password_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidatePasswordRequest.ProtoReflect.Descriptor instead.
+func (*ValidatePasswordRequest) Descriptor() ([]byte, []int) {
+	return file_password_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ValidatePasswordRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *ValidatePasswordRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type PasswordResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        int32                  `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Factor        string                 `protobuf:"bytes,4,opt,name=factor,proto3" json:"factor,omitempty"`
+	Destination   string                 `protobuf:"bytes,5,opt,name=destination,proto3" json:"destination,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PasswordResult) Reset() {
+	*x = PasswordResult{}
+	mi := &file_password_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PasswordResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PasswordResult) ProtoMessage() {}
+
+func (x *PasswordResult) ProtoReflect() protoreflect.Message {
+	mi := &file_password_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PasswordResult.ProtoReflect.Descriptor instead.
+func (*PasswordResult) Descriptor() ([]byte, []int) {
+	return file_password_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PasswordResult) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *PasswordResult) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *PasswordResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *PasswordResult) GetFactor() string {
+	if x != nil {
+		return x.Factor
+	}
+	return ""
+}
+
+func (x *PasswordResult) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+var File_password_proto protoreflect.FileDescriptor
+
+const file_password_proto_rawDesc = "" +
+	"\n" +
+	"\x0epassword.proto\x12\vpassword.v1\"1\n" +
+	"\x15ForgotPasswordRequest\x12\x18\n" +
+	"\acontact\x18\x01 \x01(\tR\acontact\",\n" +
+	"\x16ForgotPasswordResponse\x12\x12\n" +
+	"\x04sent\x18\x01 \x01(\bR\x04sent\"\x82\x01\n" +
+	"\x14ResetPasswordRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
+	"\bpasscode\x18\x02 \x01(\tR\bpasscode\x12\x1a\n" +
+	"\bpassword\x18\x03 \x01(\tR\bpassword\x12\x16\n" +
+	"\x06factor\x18\x04 \x01(\tR\x06factor\"\xda\x01\n" +
+	"\x15ChangePasswordRequest\x12\x12\n" +
+	"\x04step\x18\x01 \x01(\x05R\x04step\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x1a\n" +
+	"\bpasscode\x18\x03 \x01(\tR\bpasscode\x12)\n" +
+	"\x10current_password\x18\x04 \x01(\tR\x0fcurrentPassword\x12\x1a\n" +
+	"\bpassword\x18\x05 \x01(\tR\bpassword\x12\x16\n" +
+	"\x06sender\x18\x06 \x01(\tR\x06sender\x12\x16\n" +
+	"\x06factor\x18\a \x01(\tR\x06factor\"K\n" +
+	"\x11DenyChangeRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
+	"\bpasscode\x18\x02 \x01(\tR\bpasscode\"Q\n" +
+	"\x17ValidatePasswordRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"\x90\x01\n" +
+	"\x0ePasswordResult\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\x05R\x06status\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x16\n" +
+	"\x06factor\x18\x04 \x01(\tR\x06factor\x12 \n" +
+	"\vdestination\x18\x05 \x01(\tR\vdestination2\xb2\x03\n" +
+	"\x0fPasswordService\x12Y\n" +
+	"\x0eForgotPassword\x12\".password.v1.ForgotPasswordRequest\x1a#.password.v1.ForgotPasswordResponse\x12O\n" +
+	"\rResetPassword\x12!.password.v1.ResetPasswordRequest\x1a\x1b.password.v1.PasswordResult\x12Q\n" +
+	"\x0eChangePassword\x12\".password.v1.ChangePasswordRequest\x1a\x1b.password.v1.PasswordResult\x12I\n" +
+	"\n" +
+	"DenyChange\x12\x1e.password.v1.DenyChangeRequest\x1a\x1b.password.v1.PasswordResult\x12U\n" +
+	"\x10ValidatePassword\x12$.password.v1.ValidatePasswordRequest\x1a\x1b.password.v1.PasswordResultB(Z&github.com/core-go/password/grpc/pb;pbb\x06proto3"
+
+var (
+	file_password_proto_rawDescOnce sync.Once
+	file_password_proto_rawDescData []byte
+)
+
+func file_password_proto_rawDescGZIP() []byte {
+	file_password_proto_rawDescOnce.Do(func() {
+		file_password_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_password_proto_rawDesc), len(file_password_proto_rawDesc)))
+	})
+	return file_password_proto_rawDescData
+}
+
+var file_password_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_password_proto_goTypes = []any{
+	(*ForgotPasswordRequest)(nil),   // 0: password.v1.ForgotPasswordRequest
+	(*ForgotPasswordResponse)(nil),  // 1: password.v1.ForgotPasswordResponse
+	(*ResetPasswordRequest)(nil),    // 2: password.v1.ResetPasswordRequest
+	(*ChangePasswordRequest)(nil),   // 3: password.v1.ChangePasswordRequest
+	(*DenyChangeRequest)(nil),       // 4: password.v1.DenyChangeRequest
+	(*ValidatePasswordRequest)(nil), // 5: password.v1.ValidatePasswordRequest
+	(*PasswordResult)(nil),          // 6: password.v1.PasswordResult
+}
+var file_password_proto_depIdxs = []int32{
+	0, // 0: password.v1.PasswordService.ForgotPassword:input_type -> password.v1.ForgotPasswordRequest
+	2, // 1: password.v1.PasswordService.ResetPassword:input_type -> password.v1.ResetPasswordRequest
+	3, // 2: password.v1.PasswordService.ChangePassword:input_type -> password.v1.ChangePasswordRequest
+	4, // 3: password.v1.PasswordService.DenyChange:input_type -> password.v1.DenyChangeRequest
+	5, // 4: password.v1.PasswordService.ValidatePassword:input_type -> password.v1.ValidatePasswordRequest
+	1, // 5: password.v1.PasswordService.ForgotPassword:output_type -> password.v1.ForgotPasswordResponse
+	6, // 6: password.v1.PasswordService.ResetPassword:output_type -> password.v1.PasswordResult
+	6, // 7: password.v1.PasswordService.ChangePassword:output_type -> password.v1.PasswordResult
+	6, // 8: password.v1.PasswordService.DenyChange:output_type -> password.v1.PasswordResult
+	6, // 9: password.v1.PasswordService.ValidatePassword:output_type -> password.v1.PasswordResult
+	5, // [5:10] is the sub-list for method output_type
+	0, // [0:5] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_password_proto_init() }
+func file_password_proto_init() {
+	if File_password_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_password_proto_rawDesc), len(file_password_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_password_proto_goTypes,
+		DependencyIndexes: file_password_proto_depIdxs,
+		MessageInfos:      file_password_proto_msgTypes,
+	}.Build()
+	File_password_proto = out.File
+	file_password_proto_goTypes = nil
+	file_password_proto_depIdxs = nil
+}
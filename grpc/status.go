@@ -0,0 +1,74 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/grpc/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Code returns the code of the result: OK for the successes and the challenges, AlreadyExists for a duplicate password,
+// InvalidArgument for a password which does not meet the policy, FailedPrecondition for an expired code, and Unauthenticated for an invalid password or code.
+func Code(result p.PasswordResult) codes.Code {
+	switch {
+	case result.Status > 0:
+		return codes.OK
+	case result.Status == -1:
+		return codes.AlreadyExists
+	case result.Status == -2:
+		return codes.InvalidArgument
+	case result.Code == p.MessageExpired:
+		return codes.FailedPrecondition
+	default:
+		return codes.Unauthenticated
+	}
+}
+
+// Status returns the status of the failed result, with the result in the details, or nil if the result is a success or a challenge.
+func Status(result p.PasswordResult) *status.Status {
+	code := Code(result)
+	if code == codes.OK {
+		return nil
+	}
+	message := result.Message
+	if len(message) == 0 {
+		message = result.Code
+	}
+	if len(message) == 0 {
+		message = code.String()
+	}
+	st := status.New(code, message)
+	if detailed, err := st.WithDetails(ToResult(result)); err == nil {
+		return detailed
+	}
+	return st
+}
+
+// Error returns the status of the error of the service: PermissionDenied for ErrForbidden, ResourceExhausted for ErrTooManyRequests, Unimplemented for ErrValidationNotSupported and ErrDenyNotSupported,
+// Canceled and DeadlineExceeded for the errors of the context, and Internal for the other errors, such as the errors of the repositories.
+func Error(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, p.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, p.ErrTooManyRequests):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, p.ErrValidationNotSupported), errors.Is(err, p.ErrDenyNotSupported):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func ToResult(result p.PasswordResult) *pb.PasswordResult {
+	return &pb.PasswordResult{Status: result.Status, Code: result.Code, Message: result.Message, Factor: result.Factor, Destination: result.Destination}
+}
@@ -0,0 +1,288 @@
+package password
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// HandlerCore decodes the requests, decrypts the passwords, calls the service and builds the responses, for all the frameworks.
+// The adapters of the frameworks write the responses, then call WriteLog.
+type HandlerCore struct {
+	PasswordService PasswordService
+	Error           func(context.Context, string, ...map[string]interface{})
+	Decrypt         func(string) (string, error)
+	Config          PasswordActionConfig
+	Log             func(ctx context.Context, resource string, action string, success bool, desc string) error
+	TenantKey       string // the key of the tenant in the context of the service
+	GetTenant       func(r *http.Request) string
+	// TrustedProxies are the proxies whose X-Forwarded-For and X-Real-Ip headers are used for the IP address of the client; the other requests use the remote address.
+	TrustedProxies []netip.Prefix
+	// Problem answers the failures with the status of HTTPStatus or ErrorStatus and an application/problem+json body, and the results as objects.
+	// When it is false, all the results are answered with 200 and the bare status, as the legacy clients expect.
+	Problem     bool
+	ProblemBase string // the prefix of the types of the problems, "/problems/" by default
+	// UserKey is the key of the id of the authenticated user in the context of the request, which is put by the authentication middleware, or by GetUser.
+	// When UserKey or GetUser is set, ChangePassword requires an authenticated user, and rejects the changes of the passwords of the other users with 403, unless IsAdmin returns true.
+	// The repositories take ChangedBy, and the audit events the actor, from the context with the same key.
+	UserKey string
+	GetUser func(r *http.Request) string
+	IsAdmin func(r *http.Request) bool
+}
+
+// Response is the response of HandlerCore: the text with the status if Text is not empty, or Body as JSON, with ContentType.
+type Response struct {
+	Status      int
+	Body        interface{}
+	ContentType string
+	Text        string
+	Action      string
+	Success     bool
+	Desc        string
+}
+
+// ActionConfig returns the config with the default resource, actions and contact key.
+func ActionConfig(c PasswordActionConfig) PasswordActionConfig {
+	if len(c.Resource) == 0 {
+		c.Resource = "password"
+	}
+	if len(c.Change) == 0 {
+		c.Change = "change"
+	}
+	if len(c.Reset) == 0 {
+		c.Reset = "reset"
+	}
+	if len(c.Forgot) == 0 {
+		c.Forgot = "forgot"
+	}
+	if len(c.Deny) == 0 {
+		c.Deny = "deny"
+	}
+	if len(c.Contact) == 0 {
+		c.Contact = "contact"
+	}
+	return c
+}
+
+func (h *HandlerCore) Change(r *http.Request) Response {
+	ctx, er0 := h.buildContext(r)
+	if er0 != nil {
+		return h.badRequest(r, "invalid tenant", er0)
+	}
+	if len(h.UserKey) > 0 || h.GetUser != nil {
+		var ok bool
+		if ctx, ok = h.principal(r, ctx); !ok {
+			return h.reject(r, http.StatusUnauthorized, ProblemUnauthenticated, "authentication is required")
+		}
+	}
+	var passwordChange PasswordChange
+	er1 := json.NewDecoder(r.Body).Decode(&passwordChange)
+	if er1 != nil {
+		return h.badRequest(r, "Cannot decode PasswordChange model", er1)
+	}
+	if h.Decrypt != nil {
+		decodedCurrentPassword, er2 := h.Decrypt(passwordChange.CurrentPassword)
+		if er2 != nil {
+			return h.badRequest(r, "cannot decode current password", er2)
+		}
+		decodedNewPassword, er3 := h.Decrypt(passwordChange.Password)
+		if er3 != nil {
+			return h.badRequest(r, "cannot decode new password", er3)
+		}
+		passwordChange.CurrentPassword = decodedCurrentPassword
+		passwordChange.Password = decodedNewPassword
+	}
+	result, er4 := ResultService(h.PasswordService).ChangePasswordWithResult(ctx, passwordChange)
+	if er4 != nil {
+		return h.failure(r, result.Response(), h.Config.Change, er4)
+	}
+	return h.result(r, result, h.Config.Change, result.Status > 0)
+}
+
+// Forgot gets the contact from the last segment of the path of a GET request, or from the body, which is a JSON object with the Contact key of Config, a JSON string or the contact itself.
+func (h *HandlerCore) Forgot(r *http.Request) Response {
+	contact := ""
+	if r.Method == http.MethodGet {
+		i := strings.LastIndex(r.URL.Path, "/")
+		if i >= 0 {
+			contact = r.URL.Path[i+1:]
+		}
+	} else {
+		b, er1 := ioutil.ReadAll(r.Body)
+		if er1 != nil {
+			return h.badRequest(r, "Cannot get the body of 'Forgot Password'", er1)
+		}
+		contact, er1 = h.contact(b)
+		if er1 != nil {
+			return h.badRequest(r, "Cannot get the body of 'Forgot Password'", er1)
+		}
+	}
+	ctx, er0 := h.buildContext(r)
+	if er0 != nil {
+		return h.badRequest(r, "invalid tenant", er0)
+	}
+	result, er2 := h.PasswordService.ForgotPassword(ctx, contact)
+	if er2 != nil {
+		return h.failure(r, result, h.Config.Forgot, er2)
+	}
+	return Response{Status: http.StatusOK, Body: result, ContentType: contentTypeJSON, Action: h.Config.Forgot, Success: result}
+}
+
+func (h *HandlerCore) Reset(r *http.Request) Response {
+	var passwordReset PasswordReset
+	er1 := json.NewDecoder(r.Body).Decode(&passwordReset)
+	if er1 != nil {
+		return h.badRequest(r, "Cannot decode PasswordReset model", er1)
+	}
+	if h.Decrypt != nil {
+		decodedNewPassword, er2 := h.Decrypt(passwordReset.Password)
+		if er2 != nil {
+			return h.badRequest(r, "cannot decode new password", er2)
+		}
+		passwordReset.Password = decodedNewPassword
+	}
+	ctx, er0 := h.buildContext(r)
+	if er0 != nil {
+		return h.badRequest(r, "invalid tenant", er0)
+	}
+	result, er3 := ResultService(h.PasswordService).ResetPasswordWithResult(ctx, passwordReset)
+	if er3 != nil {
+		return h.failure(r, result.Response(), h.Config.Reset, er3)
+	}
+	return h.result(r, result, h.Config.Reset, result.Status == 1)
+}
+
+func (h *HandlerCore) Deny(r *http.Request) Response {
+	var passwordDeny PasswordDeny
+	er1 := json.NewDecoder(r.Body).Decode(&passwordDeny)
+	if er1 != nil {
+		return h.badRequest(r, "Cannot decode PasswordDeny model", er1)
+	}
+	ctx, er0 := h.buildContext(r)
+	if er0 != nil {
+		return h.badRequest(r, "invalid tenant", er0)
+	}
+	result, er2 := ResultService(h.PasswordService).DenyChange(ctx, passwordDeny)
+	if er2 != nil {
+		return h.failure(r, result.Response(), h.Config.Deny, er2)
+	}
+	return h.result(r, result, h.Config.Deny, result.Status == 1)
+}
+
+// WriteLog writes the log of the action of the response, if Log is set; the responses of the bad requests are not logged.
+func (h *HandlerCore) WriteLog(r *http.Request, res Response) {
+	if h.Log != nil && len(res.Action) > 0 {
+		ctx := context.WithValue(r.Context(), "request", r)
+		h.Log(ctx, h.Config.Resource, res.Action, res.Success, res.Desc)
+	}
+}
+
+func (h *HandlerCore) badRequest(r *http.Request, text string, err error) Response {
+	if h.Error != nil {
+		h.Error(r.Context(), text+": "+err.Error())
+	}
+	return h.reject(r, http.StatusBadRequest, ProblemBadRequest, text)
+}
+
+func (h *HandlerCore) reject(r *http.Request, status int, kind string, text string) Response {
+	if h.Problem {
+		return Response{Status: status, Body: h.problem(r, kind, status, text, ""), ContentType: ContentTypeProblem}
+	}
+	return Response{Status: status, Text: text}
+}
+
+func (h *HandlerCore) failure(r *http.Request, body interface{}, action string, err error) Response {
+	msg := err.Error()
+	if h.Error != nil {
+		h.Error(r.Context(), msg)
+	}
+	if h.Problem {
+		status := ErrorStatus(err)
+		kind := ProblemInternal
+		detail := ""
+		switch status {
+		case http.StatusTooManyRequests:
+			kind = ProblemTooManyRequests
+			detail = msg
+		case http.StatusForbidden:
+			kind = ProblemForbidden
+			detail = msg
+		}
+		return Response{Status: status, Body: h.problem(r, kind, status, detail, ""), ContentType: ContentTypeProblem, Action: action, Desc: msg}
+	}
+	if errors.Is(err, ErrForbidden) {
+		return Response{Status: http.StatusForbidden, Text: msg, Action: action, Desc: msg}
+	}
+	return Response{Status: http.StatusOK, Body: body, ContentType: contentTypeJSON, Action: action, Desc: msg}
+}
+
+func (h *HandlerCore) result(r *http.Request, result PasswordResult, action string, success bool) Response {
+	if !h.Problem {
+		return Response{Status: http.StatusOK, Body: result.Response(), ContentType: contentTypeJSON, Action: action, Success: success}
+	}
+	status := HTTPStatus(result)
+	if status == http.StatusOK {
+		return Response{Status: status, Body: result, ContentType: contentTypeJSON, Action: action, Success: success}
+	}
+	return Response{Status: status, Body: h.problem(r, problemKind(status), status, result.Message, result.Code), ContentType: ContentTypeProblem, Action: action, Success: success}
+}
+
+func (h *HandlerCore) problem(r *http.Request, kind string, status int, detail string, code string) Problem {
+	base := h.ProblemBase
+	if len(base) == 0 {
+		base = "/problems/"
+	}
+	return Problem{Type: base + kind, Title: http.StatusText(status), Status: status, Detail: detail, Instance: r.URL.Path, Code: code}
+}
+
+func (h *HandlerCore) contact(body []byte) (string, error) {
+	b := bytes.TrimSpace(body)
+	if len(b) > 0 && b[0] == '{' {
+		m := make(map[string]interface{})
+		if err := json.Unmarshal(b, &m); err != nil {
+			return "", err
+		}
+		key := h.Config.Contact
+		if len(key) == 0 {
+			key = "contact"
+		}
+		contact, _ := m[key].(string)
+		return strings.TrimSpace(contact), nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		var contact string
+		if err := json.Unmarshal(b, &contact); err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(contact), nil
+	}
+	return string(b), nil
+}
+
+// buildContext returns the context of the request for the service, with the tenant of the request if GetTenant is set;
+// it returns ErrInvalidTenant if the tenant of the request is not valid.
+func (h *HandlerCore) buildContext(r *http.Request) (context.Context, error) {
+	return WithTenant(BuildContext(r, h.TrustedProxies...), r, h.TenantKey, h.GetTenant)
+}
+
+// principal adds the authenticated user of the request to the context, by GetUser or from the context of the request with UserKey; it returns false if there is no authenticated user.
+func (h *HandlerCore) principal(r *http.Request, ctx context.Context) (context.Context, bool) {
+	var id string
+	if h.GetUser != nil {
+		id = h.GetUser(r)
+		if len(id) > 0 && len(h.UserKey) > 0 {
+			ctx = context.WithValue(ctx, h.UserKey, id)
+		}
+	} else {
+		id = getString(ctx, h.UserKey)
+	}
+	if len(id) == 0 {
+		return ctx, false
+	}
+	return WithPrincipal(ctx, id, h.IsAdmin != nil && h.IsAdmin(r)), true
+}
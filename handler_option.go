@@ -0,0 +1,85 @@
+package password
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+)
+
+// HandlerOption sets a concern of HandlerCore, for NewHandlerCore and the NewHandler of all the frameworks.
+type HandlerOption func(*HandlerCore)
+
+// NewHandlerCore returns the core of the handlers of the service, with the options; Config has the defaults of ActionConfig.
+func NewHandlerCore(service PasswordService, options ...HandlerOption) *HandlerCore {
+	h := &HandlerCore{PasswordService: service}
+	for _, option := range options {
+		option(h)
+	}
+	h.Config = ActionConfig(h.Config)
+	return h
+}
+
+// NewHandler returns the net/http handler of the service, with the options.
+func NewHandler(service PasswordService, options ...HandlerOption) *PasswordHandler {
+	return &PasswordHandler{*NewHandlerCore(service, options...)}
+}
+
+func WithError(logError func(context.Context, string, ...map[string]interface{})) HandlerOption {
+	return func(h *HandlerCore) {
+		h.Error = logError
+	}
+}
+
+// WithDecrypt sets the function to decrypt the passwords of the requests.
+func WithDecrypt(decrypt func(string) (string, error)) HandlerOption {
+	return func(h *HandlerCore) {
+		h.Decrypt = decrypt
+	}
+}
+
+func WithLog(writeLog func(ctx context.Context, resource string, action string, success bool, desc string) error) HandlerOption {
+	return func(h *HandlerCore) {
+		h.Log = writeLog
+	}
+}
+
+func WithConfig(c PasswordActionConfig) HandlerOption {
+	return func(h *HandlerCore) {
+		h.Config = c
+	}
+}
+
+// WithRequestTenant sets the key of the tenant in the context of the service, and the function to get the tenant of the request.
+func WithRequestTenant(key string, getTenant func(r *http.Request) string) HandlerOption {
+	return func(h *HandlerCore) {
+		h.TenantKey = key
+		h.GetTenant = getTenant
+	}
+}
+
+// WithRequestUser binds ChangePassword to the authenticated user, which is taken by getUser, or from the context of the request with the key if getUser is nil.
+// The changes of the passwords of the other users are rejected, unless isAdmin returns true; the key should be the key of the repositories, for ChangedBy.
+func WithRequestUser(key string, getUser func(r *http.Request) string, isAdmin func(r *http.Request) bool) HandlerOption {
+	return func(h *HandlerCore) {
+		h.UserKey = key
+		h.GetUser = getUser
+		h.IsAdmin = isAdmin
+	}
+}
+
+// WithTrustedProxies sets the proxies whose X-Forwarded-For and X-Real-Ip headers are trusted, such as the load balancers; see ParseTrustedProxies.
+func WithTrustedProxies(trustedProxies ...netip.Prefix) HandlerOption {
+	return func(h *HandlerCore) {
+		h.TrustedProxies = trustedProxies
+	}
+}
+
+// WithProblem answers the failures with their HTTP status and application/problem+json, with the prefix of the types of the problems, if any.
+func WithProblem(options ...string) HandlerOption {
+	return func(h *HandlerCore) {
+		h.Problem = true
+		if len(options) >= 1 {
+			h.ProblemBase = options[0]
+		}
+	}
+}
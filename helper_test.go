@@ -0,0 +1,177 @@
+package password_test
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// plain is a TextComparator which hashes by a prefix, so that the tests can read the stored passwords and codes.
+type plain struct{}
+
+func (plain) Hash(plaintext string) (string, error) {
+	return "h:" + plaintext, nil
+}
+func (plain) Compare(plaintext string, hashed string) (bool, error) {
+	return "h:"+plaintext == hashed, nil
+}
+
+type sentCode struct {
+	to     string
+	code   string
+	params interface{}
+}
+
+// outbox records the codes of a send function of the use case.
+type outbox struct {
+	mutex sync.Mutex
+	sent  []sentCode
+}
+
+func (o *outbox) send(ctx context.Context, to string, code string, expireAt time.Time, params interface{}) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.sent = append(o.sent, sentCode{to: to, code: code, params: params})
+	return nil
+}
+
+func (o *outbox) codes() []sentCode {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return append([]sentCode(nil), o.sent...)
+}
+
+type user struct {
+	id       string
+	username string
+	email    string
+	password string
+}
+
+// users is a PasswordRepository of the given users, by the id.
+type users struct {
+	mutex sync.Mutex
+	users map[string]*user
+}
+
+func newUsers(list ...user) *users {
+	r := &users{users: make(map[string]*user)}
+	for i := range list {
+		r.users[list[i].id] = &list[i]
+	}
+	return r
+}
+
+func (r *users) find(usernameOrEmail string) *user {
+	for _, u := range r.users {
+		if u.username == usernameOrEmail || u.email == usernameOrEmail {
+			return u
+		}
+	}
+	return nil
+}
+
+func (r *users) GetUserId(ctx context.Context, username string) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if u := r.find(username); u != nil {
+		return u.id, nil
+	}
+	return "", nil
+}
+func (r *users) GetUser(ctx context.Context, usernameOrEmail string) (string, string, string, string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if u := r.find(usernameOrEmail); u != nil {
+		return u.id, u.username, u.email, u.password, nil
+	}
+	return "", "", "", "", nil
+}
+func (r *users) Update(ctx context.Context, userId string, newPassword string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	u, ok := r.users[userId]
+	if !ok {
+		return 0, nil
+	}
+	u.password = newPassword
+	return 1, nil
+}
+func (r *users) UpdateWithCurrentPassword(ctx context.Context, userId string, currentPassword, newPassword string) (int64, error) {
+	return r.Update(ctx, userId, newPassword)
+}
+func (r *users) GetHistory(ctx context.Context, userId string, max int) ([]string, error) {
+	return nil, nil
+}
+func (r *users) password(id string) string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.users[id].password
+}
+
+type savedCode struct {
+	code     string
+	expireAt time.Time
+}
+
+// codes is a VerificationCodeRepository in a map, which is safe for the codes deleted in the background.
+type codes struct {
+	mutex sync.Mutex
+	codes map[string]savedCode
+}
+
+func newCodes() *codes {
+	return &codes{codes: make(map[string]savedCode)}
+}
+
+func (r *codes) Save(ctx context.Context, id string, passcode string, expireAt time.Time) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.codes[id] = savedCode{code: passcode, expireAt: expireAt}
+	return 1, nil
+}
+func (r *codes) Load(ctx context.Context, id string) (string, time.Time, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	c := r.codes[id]
+	return c.code, c.expireAt, nil
+}
+func (r *codes) Delete(ctx context.Context, id string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.codes[id]; !ok {
+		return 0, nil
+	}
+	delete(r.codes, id)
+	return 1, nil
+}
+
+// deleted waits for the code of the id to be deleted, since the service may delete it in the background.
+func (r *codes) deleted(id string) bool {
+	for i := 0; i < 100; i++ {
+		if code, _, _ := r.Load(context.Background(), id); len(code) == 0 {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+// ids records the ids given to a function of the use case, such as Lock or RevokeAllTokens.
+type ids struct {
+	mutex sync.Mutex
+	ids   []string
+}
+
+func (r *ids) add(ctx context.Context, id string, reason string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.ids = append(r.ids, id)
+	return nil
+}
+
+func (r *ids) list() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]string(nil), r.ids...)
+}
@@ -0,0 +1,93 @@
+package password
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type localeKey struct{}
+
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z]{4})?(-([a-z]{2}|[0-9]{3}))?$`)
+
+func WithLocale(ctx context.Context, locale string) context.Context {
+	if len(locale) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+func GetLocale(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok {
+		return locale
+	}
+	return ""
+}
+
+// NormalizeLocale returns the locale as a BCP 47 tag of a language with an optional script and region, such as "pt-BR" for "pt_br", or an empty string if it is not such a tag.
+func NormalizeLocale(locale string) string {
+	l := strings.ToLower(strings.Replace(strings.TrimSpace(locale), "_", "-", -1))
+	if !localePattern.MatchString(l) {
+		return ""
+	}
+	parts := strings.Split(l, "-")
+	for i := 1; i < len(parts); i++ {
+		if len(parts[i]) == 4 {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		} else {
+			parts[i] = strings.ToUpper(parts[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// ParseAcceptLanguage returns the language with the highest quality in the Accept-Language header, such as "vi" or "pt-BR"; the tags which are not valid are skipped.
+func ParseAcceptLanguage(header string) string {
+	type language struct {
+		tag     string
+		quality float64
+	}
+	languages := make([]language, 0)
+	for _, part := range strings.Split(header, ",") {
+		items := strings.Split(strings.TrimSpace(part), ";")
+		tag := NormalizeLocale(items[0])
+		if len(tag) == 0 {
+			continue
+		}
+		quality := 1.0
+		for _, item := range items[1:] {
+			item = strings.TrimSpace(item)
+			if strings.HasPrefix(item, "q=") {
+				if q, err := strconv.ParseFloat(item[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			languages = append(languages, language{tag, quality})
+		}
+	}
+	if len(languages) == 0 {
+		return ""
+	}
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].quality > languages[j].quality
+	})
+	return languages[0].tag
+}
+
+// Locales returns the locale and its fallbacks, such as "pt-BR", "pt".
+func Locales(locale string) []string {
+	locales := make([]string, 0)
+	if len(locale) == 0 {
+		return locales
+	}
+	locale = strings.Replace(locale, "_", "-", -1)
+	locales = append(locales, locale)
+	if i := strings.Index(locale, "-"); i > 0 {
+		locales = append(locales, locale[:i])
+	}
+	return locales
+}
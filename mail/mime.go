@@ -0,0 +1,67 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"time"
+)
+
+// BuildMessage builds a multipart/alternative message with the text and the html parts; an empty part is omitted.
+func BuildMessage(from string, to string, subject string, text string, html string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	if len(text) == 0 || len(html) == 0 {
+		contentType := "text/plain; charset=utf-8"
+		content := text
+		if len(html) > 0 {
+			contentType = "text/html; charset=utf-8"
+			content = html
+		}
+		fmt.Fprintf(&buf, "Content-Type: %s\r\n", contentType)
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, content); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	writer := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+	if err := writePart(writer, "text/plain; charset=utf-8", text); err != nil {
+		return nil, err
+	}
+	if err := writePart(writer, "text/html; charset=utf-8", html); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writePart(writer *multipart.Writer, contentType string, content string) error {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	return writeQuotedPrintable(part, content)
+}
+
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
@@ -0,0 +1,67 @@
+package mail
+
+import (
+	p "github.com/core-go/password"
+)
+
+// NewPasswordServiceFromConfig builds the service and the mail sender of the templates of the config, which sends the codes when the dependencies have no sender and no Delivery.
+// The errors of the templates are returned as ValidationErrors with the keys of the templates, such as "template.reset".
+func NewPasswordServiceFromConfig(c PasswordMailConfig, transport Transport, from string, d p.PasswordDependencies) (*p.PasswordUseCase, *PasswordMailSender, error) {
+	var errs p.ValidationErrors
+	if transport == nil {
+		errs = append(errs, p.ValidationError{Key: "Transport", Message: "is required"})
+	}
+	if len(from) == 0 {
+		errs = append(errs, p.ValidationError{Key: "From", Message: "is required"})
+	}
+	reset, err := LoadTemplate(c.Template.ResetTemplate)
+	if err != nil {
+		errs = append(errs, p.ValidationError{Key: "template.reset", Message: err.Error()})
+	}
+	change, err := LoadTemplate(c.Template.ChangeTemplate)
+	if err != nil {
+		errs = append(errs, p.ValidationError{Key: "template.change", Message: err.Error()})
+	}
+	var notice *PasswordTemplate
+	if len(c.Template.NoticeTemplate.Body) > 0 {
+		if notice, err = LoadTemplate(c.Template.NoticeTemplate); err != nil {
+			errs = append(errs, p.ValidationError{Key: "template.notice", Message: err.Error()})
+		}
+	}
+	var sender *PasswordMailSender
+	if len(errs) == 0 {
+		sender = &PasswordMailSender{Transport: transport, From: from, Reset: reset, Change: change, Notice: notice, TimeFormat: "2006-01-02 15:04 MST", Config: c.Template, SupportedLocales: c.Template.Locales}
+	}
+	if sender != nil && d.Delivery == nil {
+		if d.SendResetCode == nil {
+			d.SendResetCode = sender.SendResetCode
+		}
+		if d.SendChangeCode == nil {
+			d.SendChangeCode = sender.SendChangeCode
+		}
+	}
+	config := p.PasswordConfig{
+		ResetExpires:  c.ResetExpires,
+		ChangeExpires: c.ChangeExpires,
+		Exp1:          c.Exp1,
+		Exp2:          c.Exp2,
+		Exp3:          c.Exp3,
+		Exp4:          c.Exp4,
+		Exp5:          c.Exp5,
+		Exp6:          c.Exp6,
+		Schema:        c.Schema,
+		Policy:        c.Policy,
+	}
+	service, err := p.NewPasswordServiceFromConfig(config, d)
+	if err != nil {
+		if e, ok := err.(p.ValidationErrors); ok {
+			errs = append(errs, e...)
+		} else {
+			errs = append(errs, p.ValidationError{Key: "password", Message: err.Error()})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+	return service, sender, nil
+}
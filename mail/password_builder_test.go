@@ -0,0 +1,109 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/memory"
+)
+
+type plain struct{}
+
+func (plain) Hash(plaintext string) (string, error) {
+	return "h:" + plaintext, nil
+}
+func (plain) Compare(plaintext string, hashed string) (bool, error) {
+	return "h:"+plaintext == hashed, nil
+}
+
+func TestNewPasswordServiceFromConfig(t *testing.T) {
+	dir := writeFiles(t, templateFiles)
+	valid := PasswordMailConfig{ResetExpires: 600, Template: templateConfig(dir)}
+	dependencies := func() p.PasswordDependencies {
+		return p.PasswordDependencies{Comparator: plain{}, Repository: memory.NewPasswordRepository("userId", 5), ResetCodeRepository: memory.NewVerificationCodeRepository()}
+	}
+	tests := []struct {
+		name      string
+		config    func(c PasswordMailConfig) PasswordMailConfig
+		transport Transport
+		from      string
+		deps      func(d p.PasswordDependencies) p.PasswordDependencies
+		keys      []string
+	}{
+		{"Valid", nil, &recorder{}, "noreply@example.com", nil, nil},
+		// without a sender, the service has no SendResetCode either
+		{"MissingTransportAndFrom", nil, nil, "", nil, []string{"Transport", "From", "SendResetCode"}},
+		{"MissingResetTemplate", func(c PasswordMailConfig) PasswordMailConfig {
+			c.Template.ResetTemplate.Body = filepath.Join(dir, "missing.html")
+			return c
+		}, &recorder{}, "noreply@example.com", nil, []string{"template.reset", "SendResetCode"}},
+		{"MissingComparator", nil, &recorder{}, "noreply@example.com", func(d p.PasswordDependencies) p.PasswordDependencies {
+			d.Comparator = nil
+			return d
+		}, []string{"Comparator"}},
+		{"BadExpression", func(c PasswordMailConfig) PasswordMailConfig {
+			c.Exp2 = `(`
+			return c
+		}, &recorder{}, "noreply@example.com", nil, []string{"exp2"}},
+		{"MissingTemplateAndDependencies", func(c PasswordMailConfig) PasswordMailConfig {
+			c.Template.ChangeTemplate.Body = ""
+			return c
+		}, &recorder{}, "noreply@example.com", func(d p.PasswordDependencies) p.PasswordDependencies {
+			d.Repository = nil
+			return d
+		}, []string{"template.change", "Repository", "SendResetCode"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			if tt.config != nil {
+				c = tt.config(c)
+			}
+			d := dependencies()
+			if tt.deps != nil {
+				d = tt.deps(d)
+			}
+			service, sender, err := NewPasswordServiceFromConfig(c, tt.transport, tt.from, d)
+			if tt.keys == nil {
+				if err != nil || service == nil || sender == nil {
+					t.Fatalf("NewPasswordServiceFromConfig() = %v, %v, %v; want a service and a sender", service, sender, err)
+				}
+				return
+			}
+			var errs p.ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("NewPasswordServiceFromConfig() error = %v; want ValidationErrors", err)
+			}
+			keys := make([]string, len(errs))
+			for i, e := range errs {
+				keys[i] = e.Key
+			}
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("keys = %v; want %v", keys, tt.keys)
+			}
+		})
+	}
+}
+
+func TestNewPasswordServiceFromConfigSendsByMail(t *testing.T) {
+	transport := &recorder{}
+	users := memory.NewPasswordRepository("userId", 5, memory.User{Id: "u1", Username: "alice", Email: "alice@example.com", Password: "h:p0"})
+	d := p.PasswordDependencies{Comparator: plain{}, Repository: users, ResetCodeRepository: memory.NewVerificationCodeRepository()}
+	service, _, err := NewPasswordServiceFromConfig(PasswordMailConfig{ResetExpires: 600, Template: templateConfig(writeFiles(t, templateFiles))}, transport, "noreply@example.com", d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := service.ForgotPassword(context.Background(), "alice@example.com"); !ok || err != nil {
+		t.Fatalf("ForgotPassword() = %v, %v", ok, err)
+	}
+	if len(transport.messages) != 1 {
+		t.Fatalf("%d messages; want the reset code sent by the mail sender", len(transport.messages))
+	}
+	if subject, _ := parts(t, transport.messages[0]); subject != "Reset the password of alice" {
+		t.Errorf("Subject = %q; want the subject of the reset template", subject)
+	}
+}
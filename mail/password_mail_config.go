@@ -15,10 +15,13 @@ type PasswordMailConfig struct {
 	Exp5          string                        `mapstructure:"exp5" json:"exp5,omitempty" gorm:"column:exp5" bson:"exp5,omitempty" dynamodbav:"exp5,omitempty" firestore:"exp5,omitempty"`
 	Exp6          string                        `mapstructure:"exp6" json:"exp6,omitempty" gorm:"column:exp6" bson:"exp6,omitempty" dynamodbav:"exp6,omitempty" firestore:"exp6,omitempty"`
 	Schema        password.PasswordSchemaConfig `mapstructure:"schema" json:"schema,omitempty" gorm:"column:schema" bson:"schema,omitempty" dynamodbav:"schema,omitempty" firestore:"schema,omitempty"`
+	Policy        password.PolicyConfig         `mapstructure:"policy" json:"policy,omitempty" gorm:"column:policy" bson:"policy,omitempty" dynamodbav:"policy,omitempty" firestore:"policy,omitempty"`
 	Template      PasswordTemplateConfig        `mapstructure:"template" json:"template,omitempty" gorm:"column:template" bson:"template,omitempty" dynamodbav:"template,omitempty" firestore:"template,omitempty"`
 }
 
 type PasswordTemplateConfig struct {
 	ResetTemplate  mail.TemplateConfig `mapstructure:"reset" json:"reset,omitempty" gorm:"column:reset" bson:"reset,omitempty" dynamodbav:"reset,omitempty" firestore:"reset,omitempty"`
 	ChangeTemplate mail.TemplateConfig `mapstructure:"change" json:"change,omitempty" gorm:"column:change" bson:"change,omitempty" dynamodbav:"change,omitempty" firestore:"change,omitempty"`
+	NoticeTemplate mail.TemplateConfig `mapstructure:"notice" json:"notice,omitempty" gorm:"column:notice" bson:"notice,omitempty" dynamodbav:"notice,omitempty" firestore:"notice,omitempty"`
+	Locales        []string            `mapstructure:"locales" json:"locales,omitempty" gorm:"column:locales" bson:"locales,omitempty" dynamodbav:"locales,omitempty" firestore:"locales,omitempty"`
 }
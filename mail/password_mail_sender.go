@@ -0,0 +1,181 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	nm "net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/core-go/mail"
+	p "github.com/core-go/password"
+)
+
+type PasswordMailSender struct {
+	Transport  Transport
+	From       string
+	Reset      *PasswordTemplate
+	Change     *PasswordTemplate
+	Notice     *PasswordTemplate // optional, the notice that the password was changed, with the "this wasn't me" link
+	TimeFormat string
+	// Location returns the time zone of the user, to format the expiry; the local time zone is used when it is nil or returns nil.
+	Location func(ctx context.Context, username string) *time.Location
+	// Link returns the optional link of the message, such as the link to the reset page with the code.
+	Link func(ctx context.Context, message p.DeliveryMessage) string
+	// Config is used to load the templates of the locales other than the default.
+	Config PasswordTemplateConfig
+	// SupportedLocales are the locales of the templates; the other locales use the default templates.
+	// When it is empty, the supported locales are the ones which have the files of the template.
+	SupportedLocales []string
+	mutex            sync.RWMutex
+	templates        map[string]*PasswordTemplate
+}
+
+func NewPasswordMailSender(transport Transport, from string, c PasswordTemplateConfig, options ...func(context.Context, string) *time.Location) (*PasswordMailSender, error) {
+	reset, err := LoadTemplate(c.ResetTemplate)
+	if err != nil {
+		return nil, err
+	}
+	change, err := LoadTemplate(c.ChangeTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var notice *PasswordTemplate
+	if len(c.NoticeTemplate.Body) > 0 {
+		notice, err = LoadTemplate(c.NoticeTemplate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var location func(context.Context, string) *time.Location
+	if len(options) >= 1 {
+		location = options[0]
+	}
+	return &PasswordMailSender{Transport: transport, From: from, Reset: reset, Change: change, Notice: notice, TimeFormat: "2006-01-02 15:04 MST", Location: location, Config: c, SupportedLocales: c.Locales}, nil
+}
+
+func (s *PasswordMailSender) SendResetCode(ctx context.Context, to string, code string, expireAt time.Time, params interface{}) error {
+	message := p.DeliveryMessage{Username: to, Destination: getAddress(params), Code: code, ExpireAt: expireAt, Channel: p.ChannelEmail, Purpose: p.PurposeReset, Locale: p.GetLocale(ctx)}
+	return s.Send(ctx, message)
+}
+
+func (s *PasswordMailSender) SendChangeCode(ctx context.Context, to string, code string, expireAt time.Time, params interface{}) error {
+	message := p.DeliveryMessage{Username: to, Destination: getAddress(params), Code: code, ExpireAt: expireAt, Channel: p.ChannelEmail, Purpose: p.PurposeChange, Locale: p.GetLocale(ctx)}
+	return s.Send(ctx, message)
+}
+
+func (s *PasswordMailSender) Send(ctx context.Context, message p.DeliveryMessage) error {
+	if len(message.Destination) == 0 {
+		return errors.New("no email address to send the " + message.Purpose + " code to")
+	}
+	t, err := s.template(message.Purpose, message.Locale)
+	if err != nil {
+		return err
+	}
+	subject, text, html, err := t.Execute(s.data(ctx, message))
+	if err != nil {
+		return err
+	}
+	data, err := BuildMessage(s.From, message.Destination, subject, text, html)
+	if err != nil {
+		return err
+	}
+	from := s.From
+	if addr, er1 := nm.ParseAddress(s.From); er1 == nil {
+		from = addr.Address
+	}
+	return s.Transport.Send(ctx, from, []string{message.Destination}, data)
+}
+
+func (s *PasswordMailSender) template(purpose string, locale string) (*PasswordTemplate, error) {
+	t := s.Reset
+	c := s.Config.ResetTemplate
+	if purpose == p.PurposeChange {
+		t = s.Change
+		c = s.Config.ChangeTemplate
+	} else if purpose == p.PurposeNotice {
+		if s.Notice == nil {
+			return nil, errors.New("no template for the notice")
+		}
+		t = s.Notice
+		c = s.Config.NoticeTemplate
+	}
+	if len(c.Body) == 0 {
+		return t, nil
+	}
+	locale = s.locale(c, locale)
+	if len(locale) == 0 {
+		return t, nil
+	}
+	key := purpose + "." + locale
+	s.mutex.RLock()
+	cached, ok := s.templates[key]
+	s.mutex.RUnlock()
+	if ok {
+		return cached, nil
+	}
+	localized, err := LoadLocalizedTemplate(c, locale)
+	if err != nil {
+		return nil, err
+	}
+	s.mutex.Lock()
+	if s.templates == nil {
+		s.templates = make(map[string]*PasswordTemplate)
+	}
+	s.templates[key] = localized
+	s.mutex.Unlock()
+	return localized, nil
+}
+
+// locale returns the supported locale of the locale or of its language, or an empty string for the default templates.
+func (s *PasswordMailSender) locale(c mail.TemplateConfig, locale string) string {
+	for _, l := range p.Locales(p.NormalizeLocale(locale)) {
+		if len(s.SupportedLocales) == 0 {
+			if hasLocale(c, l) {
+				return l
+			}
+			continue
+		}
+		for _, supported := range s.SupportedLocales {
+			if strings.EqualFold(supported, l) {
+				return l
+			}
+		}
+	}
+	return ""
+}
+
+func (s *PasswordMailSender) data(ctx context.Context, message p.DeliveryMessage) TemplateData {
+	location := time.Local
+	if s.Location != nil {
+		if l := s.Location(ctx, message.Username); l != nil {
+			location = l
+		}
+	}
+	data := TemplateData{
+		Username: message.Username,
+		Code:     message.Code,
+		Expiry:   message.ExpireAt.In(location).Format(s.TimeFormat),
+		ExpireAt: message.ExpireAt,
+		Locale:   message.Locale,
+	}
+	if len(message.Link) > 0 {
+		data.Link = message.Link
+	} else if s.Link != nil {
+		data.Link = s.Link(ctx, message)
+	}
+	return data
+}
+
+func getAddress(params interface{}) string {
+	switch v := params.(type) {
+	case string:
+		return v
+	case p.Factor:
+		return v.Destination
+	case p.Contact:
+		return v.Destination
+	}
+	return ""
+}
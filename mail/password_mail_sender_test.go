@@ -0,0 +1,150 @@
+package mail
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/core-go/mail"
+	p "github.com/core-go/password"
+)
+
+// writeFiles writes the files in a temporary directory, and returns the directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+var templateFiles = map[string]string{
+	"reset.html":  `<p>Hi {{.Username}}, the reset code is <b>{{.Code}}</b>, until {{.Expiry}}.</p>`,
+	"reset.txt":   `Hi {{.Username}}, the reset code is {{.Code}}, until {{.Expiry}}.`,
+	"change.txt":  `The code to change the password of {{.Username}} is {{.Code}}.`,
+	"subject.txt": `Reset the password of {{.Username}}`,
+}
+
+func templateConfig(dir string) PasswordTemplateConfig {
+	return PasswordTemplateConfig{
+		ResetTemplate:  mail.TemplateConfig{Subject: filepath.Join(dir, "subject.txt"), Body: filepath.Join(dir, "reset.html")},
+		ChangeTemplate: mail.TemplateConfig{Subject: "Change the password", Body: filepath.Join(dir, "change.txt")},
+	}
+}
+
+func utc(ctx context.Context, username string) *time.Location {
+	return time.UTC
+}
+
+func TestPasswordMailSender(t *testing.T) {
+	addr, messages := serveSMTP(t)
+	sender, err := NewPasswordMailSender(&SMTPTransport{Addr: addr}, "Support <noreply@example.com>", templateConfig(writeFiles(t, templateFiles)), utc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expireAt := time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		send    func(ctx context.Context, to string, code string, expireAt time.Time, params interface{}) error
+		params  interface{}
+		to      string
+		subject string
+		parts   map[string]string
+	}{
+		{"Reset", sender.SendResetCode, "alice@example.com", "alice@example.com", "Reset the password of alice", map[string]string{
+			"text/plain": "Hi alice, the reset code is 123456, until 2026-10-19 08:30 UTC.",
+			"text/html":  "<p>Hi alice, the reset code is <b>123456</b>, until 2026-10-19 08:30 UTC.</p>",
+		}},
+		{"ChangeByFactor", sender.SendChangeCode, p.Factor{Type: p.FactorEmail, Destination: "alice@work.example.com"}, "alice@work.example.com", "Change the password", map[string]string{
+			"text/plain": "The code to change the password of alice is 123456.",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.send(context.Background(), "alice", "123456", expireAt, tt.params); err != nil {
+				t.Fatal(err)
+			}
+			m := receive(t, messages)
+			if m.From != "noreply@example.com" || len(m.To) != 1 || m.To[0] != tt.to {
+				t.Errorf("MAIL FROM = %q, RCPT TO = %v; want noreply@example.com and %s", m.From, m.To, tt.to)
+			}
+			// the server reads the lines of the data without the carriage returns
+			if !strings.Contains(string(m.Data), "From: Support <noreply@example.com>\n") {
+				t.Errorf("the message has no From header of the sender:\n%s", m.Data)
+			}
+			subject, body := parts(t, m.Data)
+			if subject != tt.subject {
+				t.Errorf("Subject = %q; want %q", subject, tt.subject)
+			}
+			if len(body) != len(tt.parts) {
+				t.Errorf("parts = %v; want %v", body, tt.parts)
+			}
+			for mediaType, want := range tt.parts {
+				if got := body[mediaType]; got != want {
+					t.Errorf("%s part = %q; want %q", mediaType, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPasswordMailSenderWithoutAddress(t *testing.T) {
+	sender, err := NewPasswordMailSender(&SMTPTransport{Addr: "127.0.0.1:1"}, "noreply@example.com", templateConfig(writeFiles(t, templateFiles)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sender.SendResetCode(context.Background(), "alice", "123456", time.Now(), nil); err == nil {
+		t.Error("SendResetCode() without an address = nil; want an error")
+	}
+}
+
+func TestNewPasswordMailSenderMissingTemplate(t *testing.T) {
+	c := templateConfig(writeFiles(t, templateFiles))
+	c.ChangeTemplate.Body = filepath.Join(filepath.Dir(c.ResetTemplate.Body), "missing.txt")
+	if _, err := NewPasswordMailSender(&SMTPTransport{}, "noreply@example.com", c); err == nil {
+		t.Error("NewPasswordMailSender() with a missing template = nil; want an error")
+	}
+}
+
+func TestPasswordMailSenderNotice(t *testing.T) {
+	files := map[string]string{"notice.txt": `The password of {{.Username}} was changed. If it wasn't you: {{.Link}}`}
+	for name, content := range templateFiles {
+		files[name] = content
+	}
+	dir := writeFiles(t, files)
+	c := templateConfig(dir)
+	message := p.DeliveryMessage{Username: "alice", Destination: "alice@example.com", Code: "abc", ExpireAt: time.Now(), Purpose: p.PurposeNotice, Link: "https://example.com/deny?username=alice&code=abc"}
+
+	transport := &recorder{}
+	sender, err := NewPasswordMailSender(transport, "noreply@example.com", c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sender.Send(context.Background(), message); err == nil {
+		t.Error("Send() of a notice without a notice template = nil; want an error")
+	}
+
+	c.NoticeTemplate = mail.TemplateConfig{Subject: "Your password was changed", Body: filepath.Join(dir, "notice.txt")}
+	if sender, err = NewPasswordMailSender(transport, "noreply@example.com", c); err != nil {
+		t.Fatal(err)
+	}
+	if err := sender.Send(context.Background(), message); err != nil {
+		t.Fatal(err)
+	}
+	if len(transport.messages) != 1 {
+		t.Fatalf("%d messages; want 1", len(transport.messages))
+	}
+	subject, body := parts(t, transport.messages[0])
+	if subject != "Your password was changed" {
+		t.Errorf("Subject = %q; want the subject of the notice", subject)
+	}
+	if want := "The password of alice was changed. If it wasn't you: https://example.com/deny?username=alice&code=abc"; body["text/plain"] != want {
+		t.Errorf("text = %q; want %q", body["text/plain"], want)
+	}
+}
@@ -0,0 +1,135 @@
+package mail
+
+import (
+	"bytes"
+	htemplate "html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	ttemplate "text/template"
+	"time"
+
+	"github.com/core-go/mail"
+	p "github.com/core-go/password"
+)
+
+type TemplateData struct {
+	Username string
+	Code     string
+	Expiry   string
+	ExpireAt time.Time
+	Link     string
+	Locale   string
+}
+
+type PasswordTemplate struct {
+	Subject *ttemplate.Template
+	Text    *ttemplate.Template
+	HTML    *htemplate.Template
+}
+
+// LoadTemplate loads the subject and the body of the template.
+// Subject is the path of the subject file, or the subject itself if there is no such file.
+// Body is the path of an html or a text file; the file with the same name and the other extension, if any, is loaded as the alternative part.
+func LoadTemplate(c mail.TemplateConfig) (*PasswordTemplate, error) {
+	return LoadLocalizedTemplate(c, "")
+}
+
+// LoadLocalizedTemplate loads the files of the locale, such as reset.vi.html for reset.html, and falls back to the default files.
+func LoadLocalizedTemplate(c mail.TemplateConfig, locale string) (*PasswordTemplate, error) {
+	subject := c.Subject
+	if subjectPath := localize(c.Subject, locale); isFile(subjectPath) {
+		b, err := ioutil.ReadFile(subjectPath)
+		if err != nil {
+			return nil, err
+		}
+		subject = strings.TrimSpace(string(b))
+	}
+	t := &PasswordTemplate{}
+	var err error
+	if t.Subject, err = ttemplate.New("subject").Parse(subject); err != nil {
+		return nil, err
+	}
+	htmlPath, textPath := alternatives(c.Body)
+	htmlPath = localize(htmlPath, locale)
+	textPath = localize(textPath, locale)
+	if isFile(htmlPath) {
+		if t.HTML, err = htemplate.ParseFiles(htmlPath); err != nil {
+			return nil, err
+		}
+	}
+	if isFile(textPath) {
+		if t.Text, err = ttemplate.ParseFiles(textPath); err != nil {
+			return nil, err
+		}
+	}
+	if t.HTML == nil && t.Text == nil {
+		return nil, os.ErrNotExist
+	}
+	return t, nil
+}
+
+func (t *PasswordTemplate) Execute(data TemplateData) (string, string, string, error) {
+	var subject, text, html bytes.Buffer
+	if err := t.Subject.Execute(&subject, data); err != nil {
+		return "", "", "", err
+	}
+	if t.Text != nil {
+		if err := t.Text.Execute(&text, data); err != nil {
+			return "", "", "", err
+		}
+	}
+	if t.HTML != nil {
+		if err := t.HTML.Execute(&html, data); err != nil {
+			return "", "", "", err
+		}
+	}
+	return subject.String(), text.String(), html.String(), nil
+}
+
+func alternatives(body string) (string, string) {
+	ext := strings.ToLower(filepath.Ext(body))
+	name := strings.TrimSuffix(body, filepath.Ext(body))
+	if ext == ".html" || ext == ".htm" {
+		return body, name + ".txt"
+	}
+	return name + ".html", body
+}
+
+// localize returns the path of the file of the locale or of its language, if any, or the path itself.
+func localize(path string, locale string) string {
+	if len(path) == 0 || len(locale) == 0 {
+		return path
+	}
+	for _, l := range p.Locales(locale) {
+		if localized := localizedPath(path, l); isFile(localized) {
+			return localized
+		}
+	}
+	return path
+}
+
+func localizedPath(path string, locale string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + locale + ext
+}
+
+// hasLocale reports whether the template has a file of the locale, for the subject or the body.
+func hasLocale(c mail.TemplateConfig, locale string) bool {
+	htmlPath, textPath := alternatives(c.Body)
+	for _, path := range []string{c.Subject, htmlPath, textPath} {
+		if len(path) > 0 && isFile(localizedPath(path, locale)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isFile(path string) bool {
+	if len(path) == 0 {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
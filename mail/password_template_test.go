@@ -0,0 +1,94 @@
+package mail
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/core-go/mail"
+	p "github.com/core-go/password"
+)
+
+var localizedFiles = map[string]string{
+	"reset.txt":    `Reset code {{.Code}}`,
+	"reset.vi.txt": `Mã đặt lại {{.Code}}`,
+	"subject.txt":  `Reset`,
+	"change.txt":   `Change code {{.Code}}`,
+}
+
+func TestLoadLocalizedTemplate(t *testing.T) {
+	dir := writeFiles(t, localizedFiles)
+	c := mail.TemplateConfig{Subject: filepath.Join(dir, "subject.txt"), Body: filepath.Join(dir, "reset.txt")}
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"", "Reset code 123456"},
+		{"vi", "Mã đặt lại 123456"},
+		{"vi-VN", "Mã đặt lại 123456"},
+		{"fr", "Reset code 123456"},
+	}
+	for _, tt := range tests {
+		template, err := LoadLocalizedTemplate(c, tt.locale)
+		if err != nil {
+			t.Fatalf("LoadLocalizedTemplate(%q) = %v", tt.locale, err)
+		}
+		subject, text, _, err := template.Execute(TemplateData{Code: "123456"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if subject != "Reset" || text != tt.want {
+			t.Errorf("LoadLocalizedTemplate(%q) renders %q, %q; want Reset, %q", tt.locale, subject, text, tt.want)
+		}
+	}
+}
+
+// recorder is a Transport which keeps the messages.
+type recorder struct {
+	messages [][]byte
+}
+
+func (r *recorder) Send(ctx context.Context, from string, to []string, message []byte) error {
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func TestPasswordMailSenderLocale(t *testing.T) {
+	dir := writeFiles(t, localizedFiles)
+	c := PasswordTemplateConfig{
+		ResetTemplate:  mail.TemplateConfig{Subject: filepath.Join(dir, "subject.txt"), Body: filepath.Join(dir, "reset.txt")},
+		ChangeTemplate: mail.TemplateConfig{Subject: "Change", Body: filepath.Join(dir, "change.txt")},
+	}
+	tests := []struct {
+		name      string
+		supported []string
+		locale    string
+		want      string
+	}{
+		{"LocaleWithFiles", nil, "vi-VN", "Mã đặt lại 123456"},
+		{"LocaleWithoutFiles", nil, "fr-FR", "Reset code 123456"},
+		{"NotSupported", []string{"en"}, "vi", "Reset code 123456"},
+		{"Supported", []string{"en", "vi"}, "VI_vn", "Mã đặt lại 123456"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recorder{}
+			c.Locales = tt.supported
+			sender, err := NewPasswordMailSender(transport, "noreply@example.com", c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := p.WithLocale(context.Background(), tt.locale)
+			if err := sender.SendResetCode(ctx, "alice", "123456", time.Now(), "alice@example.com"); err != nil {
+				t.Fatal(err)
+			}
+			if len(transport.messages) != 1 {
+				t.Fatalf("%d messages; want 1", len(transport.messages))
+			}
+			if _, body := parts(t, transport.messages[0]); body["text/plain"] != tt.want {
+				t.Errorf("text = %q; want %q", body["text/plain"], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"strconv"
+)
+
+type Transport interface {
+	Send(ctx context.Context, from string, to []string, message []byte) error
+}
+
+type SMTPTransport struct {
+	Addr      string
+	Host      string
+	Auth      smtp.Auth
+	TLSConfig *tls.Config
+}
+
+func NewSMTPTransport(host string, port int, username string, password string) *SMTPTransport {
+	var auth smtp.Auth
+	if len(username) > 0 {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPTransport{Addr: net.JoinHostPort(host, strconv.Itoa(port)), Host: host, Auth: auth}
+}
+
+// Send uses STARTTLS when the server supports it, and authenticates when Auth is set and the server supports it.
+func (t *SMTPTransport) Send(ctx context.Context, from string, to []string, message []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", t.Addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	host := t.Host
+	if len(host) == 0 {
+		host, _, _ = net.SplitHostPort(t.Addr)
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		config := t.TLSConfig
+		if config == nil {
+			config = &tls.Config{ServerName: host}
+		}
+		if err = c.StartTLS(config); err != nil {
+			return err
+		}
+	}
+	if t.Auth != nil {
+		if ok, _ := c.Extension("AUTH"); ok {
+			if err = c.Auth(t.Auth); err != nil {
+				return err
+			}
+		}
+	}
+	if err = c.Mail(from); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err = c.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(message); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,180 @@
+package mail
+
+import (
+	"context"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	nm "net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpMessage is a message received by the fake SMTP server.
+type smtpMessage struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// serveSMTP starts a fake SMTP server, without STARTTLS and AUTH, which receives one message for each connection.
+func serveSMTP(t *testing.T) (string, <-chan smtpMessage) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	messages := make(chan smtpMessage, 1)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go handleSMTP(textproto.NewConn(conn), messages)
+		}
+	}()
+	return ln.Addr().String(), messages
+}
+
+func handleSMTP(c *textproto.Conn, messages chan<- smtpMessage) {
+	defer c.Close()
+	var m smtpMessage
+	c.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			c.PrintfLine("250-localhost")
+			c.PrintfLine("250 8BITMIME")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			m.From = address(line[len("MAIL FROM:"):])
+			c.PrintfLine("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			m.To = append(m.To, address(line[len("RCPT TO:"):]))
+			c.PrintfLine("250 OK")
+		case command == "DATA":
+			c.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			if m.Data, err = c.ReadDotBytes(); err != nil {
+				return
+			}
+			c.PrintfLine("250 OK")
+			messages <- m
+		case command == "QUIT":
+			c.PrintfLine("221 Bye")
+			return
+		default:
+			c.PrintfLine("250 OK")
+		}
+	}
+}
+
+// address returns the address of the path of MAIL FROM or RCPT TO, without the parameters such as BODY=8BITMIME.
+func address(path string) string {
+	path = strings.TrimSpace(path)
+	if i := strings.Index(path, ">"); i >= 0 {
+		path = path[:i]
+	}
+	return strings.TrimPrefix(path, "<")
+}
+
+func receive(t *testing.T, messages <-chan smtpMessage) smtpMessage {
+	t.Helper()
+	select {
+	case m := <-messages:
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+		return smtpMessage{}
+	}
+}
+
+// parts returns the decoded parts of the message by their media types, and the decoded subject.
+func parts(t *testing.T, data []byte) (string, map[string]string) {
+	t.Helper()
+	msg, err := nm.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := make(map[string]string)
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		b, err := ioutil.ReadAll(quotedprintable.NewReader(msg.Body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the end of the data is a line break
+		result[mediaType] = strings.TrimSuffix(string(b), "\n")
+		return subject, result
+	}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		// the reader decodes the quoted-printable parts
+		b, err := ioutil.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result[partType] = string(b)
+	}
+	return subject, result
+}
+
+func TestSMTPTransportSend(t *testing.T) {
+	addr, messages := serveSMTP(t)
+	transport := &SMTPTransport{Addr: addr}
+	data, err := BuildMessage("Support <noreply@example.com>", "alice@example.com", "Réinitialiser", "code 123456", "<p>code <b>123456</b></p>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := transport.Send(context.Background(), "noreply@example.com", []string{"alice@example.com", "bob@example.com"}, data); err != nil {
+		t.Fatal(err)
+	}
+	m := receive(t, messages)
+	if m.From != "noreply@example.com" {
+		t.Errorf("MAIL FROM = %q; want noreply@example.com", m.From)
+	}
+	if len(m.To) != 2 || m.To[0] != "alice@example.com" || m.To[1] != "bob@example.com" {
+		t.Errorf("RCPT TO = %v; want alice@example.com and bob@example.com", m.To)
+	}
+	subject, body := parts(t, m.Data)
+	if subject != "Réinitialiser" {
+		t.Errorf("Subject = %q; want Réinitialiser", subject)
+	}
+	if body["text/plain"] != "code 123456" || body["text/html"] != "<p>code <b>123456</b></p>" {
+		t.Errorf("parts = %v; want the text and the html parts", body)
+	}
+}
+
+func TestSMTPTransportDialError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	transport := &SMTPTransport{Addr: addr}
+	if err := transport.Send(context.Background(), "noreply@example.com", []string{"alice@example.com"}, []byte("x")); err == nil {
+		t.Error("Send() to a closed port = nil; want an error")
+	}
+}
@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// PasswordRepository keeps the users and the histories of their passwords in memory, for testing and small tools.
+// The passwords are stored as they are given, which are the hashed passwords when used by PasswordUseCase.
+type PasswordRepository struct {
+	Key       string // User Id from context
+	TenantKey string // Tenant Id from context; when it is set, only the users of the tenant of the context are found and updated
+	Max       int    // the max number of the previous passwords in the history, 5 by default
+	mutex     sync.RWMutex
+	users     map[string]User
+	histories map[string][]History
+}
+
+func NewPasswordRepository(key string, max int, users ...User) *PasswordRepository {
+	if max <= 0 {
+		max = 5
+	}
+	r := &PasswordRepository{Key: key, Max: max, users: make(map[string]User), histories: make(map[string][]History)}
+	r.Seed(users...)
+	return r
+}
+
+// Seed adds the users, or replaces the users with the same ids in the same tenants.
+func (r *PasswordRepository) Seed(users ...User) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, user := range users {
+		r.users[tenantKey(user.Tenant, user.Id)] = user
+	}
+}
+
+// User returns the user of the id, which is prefixed by the tenant for the users of a tenant, such as "tenant#id".
+func (r *PasswordRepository) User(id string) (User, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	user, ok := r.users[id]
+	return user, ok
+}
+
+// History returns the previous passwords of the user, the latest first. Like User, the id is prefixed by the tenant for the users of a tenant.
+func (r *PasswordRepository) History(id string) []History {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	history := make([]History, len(r.histories[id]))
+	copy(history, r.histories[id])
+	return history
+}
+
+func (r *PasswordRepository) GetUserId(ctx context.Context, username string) (string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	tenant := getString(ctx, r.TenantKey)
+	for _, user := range r.users {
+		if user.Tenant == tenant && user.Username == username {
+			return user.Id, nil
+		}
+	}
+	return "", nil
+}
+
+func (r *PasswordRepository) GetUser(ctx context.Context, usernameOrEmail string) (string, string, string, string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	tenant := getString(ctx, r.TenantKey)
+	for _, user := range r.users {
+		if user.Tenant == tenant && (user.Username == usernameOrEmail || (len(user.Email) > 0 && user.Email == usernameOrEmail)) {
+			return user.Id, user.Username, user.Email, user.Password, nil
+		}
+	}
+	return "", "", "", "", nil
+}
+
+func (r *PasswordRepository) Update(ctx context.Context, userId string, newPassword string) (int64, error) {
+	return r.update(ctx, userId, newPassword), nil
+}
+
+func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, userId string, currentPassword, newPassword string) (int64, error) {
+	return r.update(ctx, userId, newPassword), nil
+}
+
+// update keeps the replaced password in the history.
+func (r *PasswordRepository) update(ctx context.Context, userId string, newPassword string) int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	key := r.key(ctx, userId)
+	user, ok := r.users[key]
+	if !ok {
+		return 0
+	}
+	now := time.Now()
+	changedBy := getString(ctx, r.Key)
+	if len(changedBy) == 0 {
+		changedBy = userId
+	}
+	if len(user.Password) > 0 {
+		history := append([]History{{Password: user.Password, Timestamp: now, ChangedBy: changedBy}}, r.histories[key]...)
+		if len(history) > r.Max {
+			history = history[:r.Max]
+		}
+		r.histories[key] = history
+	}
+	user.Password = newPassword
+	user.ChangedTime = now
+	user.ChangedBy = changedBy
+	user.FailCount = 0
+	r.users[key] = user
+	return 1
+}
+
+func (r *PasswordRepository) GetHistory(ctx context.Context, userId string, max int) ([]string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	passwords := make([]string, 0)
+	for i, history := range r.histories[r.key(ctx, userId)] {
+		if max > 0 && i >= max {
+			break
+		}
+		passwords = append(passwords, history.Password)
+	}
+	return passwords, nil
+}
+
+// key returns the key of the user in the tenant of the context.
+func (r *PasswordRepository) key(ctx context.Context, userId string) string {
+	return tenantKey(getString(ctx, r.TenantKey), userId)
+}
+
+func getString(ctx context.Context, key string) string {
+	if len(key) > 0 {
+		u := ctx.Value(key)
+		if u != nil {
+			s, ok := u.(string)
+			if ok {
+				return s
+			} else {
+				return ""
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/testkit"
+)
+
+func TestPasswordRepositoryContract(t *testing.T) {
+	testkit.RunPasswordRepositoryContract(t, testkit.PasswordRepositoryContract{
+		Key: "userId",
+		New: func(t *testing.T, users []testkit.User) p.PasswordRepository {
+			r := NewPasswordRepository("userId", 5)
+			for _, u := range users {
+				r.Seed(User{Id: u.Id, Username: u.Username, Email: u.Email, Password: u.Password, FailCount: u.FailCount})
+			}
+			return r
+		},
+		Inspect: func(t *testing.T, repository p.PasswordRepository, id string) (string, int) {
+			user, _ := repository.(*PasswordRepository).User(id)
+			return user.ChangedBy, user.FailCount
+		},
+	})
+}
+
+func TestPasswordRepositoryTenant(t *testing.T) {
+	r := NewPasswordRepository("userId", 5, User{Id: "u1", Tenant: "acme", Username: "alice", Password: "h0"}, User{Id: "u1", Tenant: "other", Username: "alice", Password: "x0"})
+	r.TenantKey = "tenant"
+	acme := context.WithValue(context.Background(), "tenant", "acme")
+	other := context.WithValue(context.Background(), "tenant", "other")
+	if _, err := r.UpdateWithCurrentPassword(acme, "u1", "h0", "h1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, password, _ := r.GetUser(acme, "alice"); password != "h1" {
+		t.Errorf("password of alice of acme = %q; want h1", password)
+	}
+	if _, _, _, password, _ := r.GetUser(other, "alice"); password != "x0" {
+		t.Errorf("password of alice of other = %q; want x0, the password of another tenant must not change", password)
+	}
+	if history, _ := r.GetHistory(other, "u1", 5); len(history) > 0 {
+		t.Errorf("GetHistory(u1) of other = %v; want no history", history)
+	}
+	if id, _ := r.GetUserId(context.WithValue(context.Background(), "tenant", "none"), "alice"); len(id) > 0 {
+		t.Errorf("GetUserId(alice) of a tenant without users = %q; want no user", id)
+	}
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+type RecoveryCodeRepository struct {
+	TenantKey string // Tenant Id from context; when it is set, the codes are kept by tenant, with the ids prefixed by the tenant, such as "tenant#userid"
+	mutex     sync.Mutex
+	codes     map[string][]string
+}
+
+func NewRecoveryCodeRepository() *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{codes: make(map[string][]string)}
+}
+
+func (r *RecoveryCodeRepository) Save(ctx context.Context, id string, codes []string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	saved := make([]string, len(codes))
+	copy(saved, codes)
+	r.codes[tenantKey(getString(ctx, r.TenantKey), id)] = saved
+	return int64(len(saved)), nil
+}
+
+func (r *RecoveryCodeRepository) Load(ctx context.Context, id string) ([]string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	saved := r.codes[tenantKey(getString(ctx, r.TenantKey), id)]
+	codes := make([]string, len(saved))
+	copy(codes, saved)
+	return codes, nil
+}
+
+func (r *RecoveryCodeRepository) Delete(ctx context.Context, id string, code string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	key := tenantKey(getString(ctx, r.TenantKey), id)
+	codes := r.codes[key]
+	for i, c := range codes {
+		if c == code {
+			r.codes[key] = append(codes[:i:i], codes[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
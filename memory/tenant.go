@@ -0,0 +1,9 @@
+package memory
+
+// tenantKey returns the key of the id in the tenant, such as "tenant#id", or the id itself when there is no tenant.
+func tenantKey(tenant string, id string) string {
+	if len(tenant) == 0 {
+		return id
+	}
+	return tenant + "#" + id
+}
@@ -0,0 +1,22 @@
+package memory
+
+import "time"
+
+// User is a user of the PasswordRepository; Tenant is the tenant of the user, which is empty when the repository is not multi-tenant.
+type User struct {
+	Id          string    `mapstructure:"id" json:"id,omitempty" gorm:"column:id" bson:"_id,omitempty" dynamodbav:"id,omitempty" firestore:"id,omitempty"`
+	Tenant      string    `mapstructure:"tenant" json:"tenant,omitempty" gorm:"column:tenant" bson:"tenant,omitempty" dynamodbav:"tenant,omitempty" firestore:"tenant,omitempty"`
+	Username    string    `mapstructure:"username" json:"username,omitempty" gorm:"column:username" bson:"username,omitempty" dynamodbav:"username,omitempty" firestore:"username,omitempty"`
+	Email       string    `mapstructure:"email" json:"email,omitempty" gorm:"column:email" bson:"email,omitempty" dynamodbav:"email,omitempty" firestore:"email,omitempty"`
+	Password    string    `mapstructure:"password" json:"password,omitempty" gorm:"column:password" bson:"password,omitempty" dynamodbav:"password,omitempty" firestore:"password,omitempty"`
+	ChangedTime time.Time `mapstructure:"changed_time" json:"changedTime,omitempty" gorm:"column:changedtime" bson:"changedTime,omitempty" dynamodbav:"changedTime,omitempty" firestore:"changedTime,omitempty"`
+	ChangedBy   string    `mapstructure:"changed_by" json:"changedBy,omitempty" gorm:"column:changedby" bson:"changedBy,omitempty" dynamodbav:"changedBy,omitempty" firestore:"changedBy,omitempty"`
+	FailCount   int       `mapstructure:"fail_count" json:"failCount,omitempty" gorm:"column:failcount" bson:"failCount,omitempty" dynamodbav:"failCount,omitempty" firestore:"failCount,omitempty"`
+}
+
+// History is a previous password of the user, with the time it was replaced and the user who replaced it.
+type History struct {
+	Password  string    `mapstructure:"password" json:"password,omitempty" gorm:"column:password" bson:"password,omitempty" dynamodbav:"password,omitempty" firestore:"password,omitempty"`
+	Timestamp time.Time `mapstructure:"timestamp" json:"timestamp,omitempty" gorm:"column:timestamp" bson:"timestamp,omitempty" dynamodbav:"timestamp,omitempty" firestore:"timestamp,omitempty"`
+	ChangedBy string    `mapstructure:"changed_by" json:"changedBy,omitempty" gorm:"column:changedby" bson:"changedBy,omitempty" dynamodbav:"changedBy,omitempty" firestore:"changedBy,omitempty"`
+}
@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type code struct {
+	passcode string
+	expireAt time.Time
+}
+
+// VerificationCodeRepository keeps the codes until they are deleted or purged.
+// Load returns an expired code with its expiry time, so that PasswordUseCase can tell an expired code from an invalid one.
+type VerificationCodeRepository struct {
+	TenantKey string // Tenant Id from context; when it is set, the codes are kept by tenant, with the ids prefixed by the tenant, such as "tenant#userid"
+	mutex     sync.Mutex
+	codes     map[string]code
+}
+
+func NewVerificationCodeRepository() *VerificationCodeRepository {
+	return &VerificationCodeRepository{codes: make(map[string]code)}
+}
+
+func (r *VerificationCodeRepository) Save(ctx context.Context, id string, passcode string, expireAt time.Time) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.codes[tenantKey(getString(ctx, r.TenantKey), id)] = code{passcode: passcode, expireAt: expireAt}
+	return 1, nil
+}
+
+func (r *VerificationCodeRepository) Load(ctx context.Context, id string) (string, time.Time, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	c, ok := r.codes[tenantKey(getString(ctx, r.TenantKey), id)]
+	if !ok {
+		return "", time.Time{}, nil
+	}
+	return c.passcode, c.expireAt, nil
+}
+
+func (r *VerificationCodeRepository) Delete(ctx context.Context, id string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	key := tenantKey(getString(ctx, r.TenantKey), id)
+	if _, ok := r.codes[key]; !ok {
+		return 0, nil
+	}
+	delete(r.codes, key)
+	return 1, nil
+}
+
+// Consume deletes the code only if it is still the given one, under the lock, so that a code can be used only once, like the sql and redis repositories.
+func (r *VerificationCodeRepository) Consume(ctx context.Context, id string, passcode string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	key := tenantKey(getString(ctx, r.TenantKey), id)
+	c, ok := r.codes[key]
+	if !ok || c.passcode != passcode {
+		return 0, nil
+	}
+	delete(r.codes, key)
+	return 1, nil
+}
+
+// Purge deletes the codes which expired before the time, and returns the number of the deleted codes.
+func (r *VerificationCodeRepository) Purge(before time.Time) int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var count int64
+	for id, c := range r.codes {
+		if c.expireAt.Before(before) {
+			delete(r.codes, id)
+			count++
+		}
+	}
+	return count
+}
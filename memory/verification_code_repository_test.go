@@ -0,0 +1,35 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/testkit"
+)
+
+func TestVerificationCodeRepositoryContract(t *testing.T) {
+	testkit.RunVerificationCodeRepositoryContract(t, func(t *testing.T) p.VerificationCodeRepository {
+		return NewVerificationCodeRepository()
+	})
+}
+
+func TestVerificationCodeRepositoryTenant(t *testing.T) {
+	r := NewVerificationCodeRepository()
+	r.TenantKey = "tenant"
+	acme := context.WithValue(context.Background(), "tenant", "acme")
+	other := context.WithValue(context.Background(), "tenant", "other")
+	if _, err := r.Save(acme, "u1", "c1", time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, _ := r.Load(other, "u1"); len(code) > 0 {
+		t.Errorf("Load(u1) of another tenant = %q; want no code", code)
+	}
+	if count, _ := r.Delete(other, "u1"); count != 0 {
+		t.Errorf("Delete(u1) of another tenant = %d; want 0", count)
+	}
+	if code, _, _ := r.Load(acme, "u1"); code != "c1" {
+		t.Errorf("Load(u1) = %q; want c1", code)
+	}
+}
@@ -0,0 +1,52 @@
+package password
+
+import "strconv"
+
+const (
+	MessageChanged    = "password.changed"
+	MessageReset      = "password.reset"
+	MessageChallenged = "password.challenged"
+	MessageInvalid    = "password.invalid"
+	MessageExpired    = "password.expired"
+	MessageDuplicate  = "password.duplicate"
+	MessagePolicy     = "password.policy"
+	MessageDenied     = "password.denied"
+	MessageBlocked    = "password.blocked"
+)
+
+// PolicyMessage returns the key of the message of the expression at the index, such as "password.exp1" for Exp1 of PasswordConfig.
+func PolicyMessage(i int) string {
+	return "password.exp" + strconv.Itoa(i+1)
+}
+
+type Localizer interface {
+	Localize(locale string, key string) string
+}
+
+// Messages maps a locale to the messages by key. The messages of the empty locale are the default.
+type Messages map[string]map[string]string
+
+func (m Messages) Localize(locale string, key string) string {
+	for _, l := range append(Locales(locale), "") {
+		if messages, ok := m[l]; ok {
+			if message, ok := messages[key]; ok {
+				return message
+			}
+		}
+	}
+	return ""
+}
+
+var DefaultMessages = Messages{
+	"": {
+		MessageChanged:    "Your password has been changed.",
+		MessageReset:      "Your password has been reset.",
+		MessageChallenged: "A verification code has been sent.",
+		MessageInvalid:    "The password or the code is not valid.",
+		MessageExpired:    "The code has expired.",
+		MessageDuplicate:  "The new password must not be one of the recent passwords.",
+		MessagePolicy:     "The new password does not meet the password policy.",
+		MessageDenied:     "Your account has been locked. A code to reset your password has been sent.",
+		MessageBlocked:    "The new password is too common. Please choose another password.",
+	},
+}
@@ -17,6 +17,8 @@ type PasswordRepository struct {
 	PasswordCollection *mongo.Collection
 	HistoryCollection  *mongo.Collection
 	Key                string // User Id from context
+	TenantKey          string // Tenant Id from context
+	TenantName         string // the tenant field; when it is set, the documents are filtered by the tenant of the context
 	PasswordName       string
 	ToAddressName      string
 	ChangedTimeName    string
@@ -72,6 +74,7 @@ func NewPasswordRepositoryByConfig(db *mongo.Database, userCollectionName, passw
 
 func (r *PasswordRepository) GetUserId(ctx context.Context, userName string) (string, error) {
 	query := bson.M{r.Username: userName}
+	setTenant(ctx, r.TenantKey, r.TenantName, query)
 	x := r.UserCollection.FindOne(ctx, query)
 	er1 := x.Err()
 	if er1 != nil {
@@ -90,6 +93,7 @@ func (r *PasswordRepository) GetUserId(ctx context.Context, userName string) (st
 
 func (r *PasswordRepository) GetUser(ctx context.Context, userNameOrEmail string) (string, string, string, string, error) {
 	query := bson.M{"$or": []bson.M{{r.Username: userNameOrEmail}, {r.ToAddressName: userNameOrEmail}}}
+	setTenant(ctx, r.TenantKey, r.TenantName, query)
 	x := r.UserCollection.FindOne(ctx, query)
 	er1 := x.Err()
 	if er1 != nil {
@@ -106,25 +110,12 @@ func (r *PasswordRepository) GetUser(ctx context.Context, userNameOrEmail string
 	userName := k.Lookup(r.Username).StringValue()
 	email := k.Lookup(r.ToAddressName).StringValue()
 
-	if r.HistoryCollection.Name() == r.UserCollection.Name() {
-		history := make([]string, 0)
-		rawValues, err := k.Lookup(r.HistoryName).Array().Values()
-		if err != nil {
-			return "", "", "", "", err
-		}
-		for i := range rawValues {
-			if password, ok := rawValues[i].Document().Lookup(r.PasswordName).StringValueOK(); ok {
-				history = append(history, password)
-			}
-		}
-		ctx = context.WithValue(ctx, r.HistoryName, history)
-	}
-
 	if r.UserCollection.Name() == r.PasswordCollection.Name() {
 		password := k.Lookup(r.PasswordName).StringValue()
 		return userId, userName, email, password, nil
 	}
 	idQuery := bson.M{"_id": userId}
+	setTenant(ctx, r.TenantKey, r.TenantName, idQuery)
 	y := r.PasswordCollection.FindOne(ctx, idQuery)
 	er4 := y.Err()
 	if er4 != nil {
@@ -145,7 +136,6 @@ func (r *PasswordRepository) GetUser(ctx context.Context, userNameOrEmail string
 
 func (r *PasswordRepository) Update(ctx context.Context, userId string, newPassword string) (int64, error) {
 	pass := make(map[string]interface{})
-	pass["_id"] = userId
 	pass[r.PasswordName] = newPassword
 	if len(r.ChangedTimeName) > 0 {
 		pass[r.ChangedTimeName] = time.Now()
@@ -162,11 +152,16 @@ func (r *PasswordRepository) Update(ctx context.Context, userId string, newPassw
 		}
 	}
 	idQuery := bson.M{"_id": userId}
+	setTenant(ctx, r.TenantKey, r.TenantName, idQuery)
+	setTenant(ctx, r.TenantKey, r.TenantName, pass)
 
 	updateQuery := bson.M{
 		"$set": pass,
 	}
-	result, err := r.PasswordCollection.UpdateOne(ctx, idQuery, updateQuery)
+	result, err := r.PasswordCollection.UpdateOne(ctx, idQuery, updateQuery, options.Update().SetUpsert(true))
+	if err != nil {
+		return 0, err
+	}
 	if result.ModifiedCount > 0 {
 		return result.ModifiedCount, err
 	} else if result.UpsertedCount > 0 {
@@ -178,7 +173,6 @@ func (r *PasswordRepository) Update(ctx context.Context, userId string, newPassw
 
 func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, userId string, currentPassword, newPassword string) (int64, error) {
 	pass := make(map[string]interface{})
-	pass["_id"] = userId
 	pass[r.PasswordName] = newPassword
 	if len(r.ChangedTimeName) > 0 {
 		pass[r.ChangedTimeName] = time.Now()
@@ -195,14 +189,27 @@ func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, user
 		}
 	}
 	idQuery := bson.M{"_id": userId}
+	setTenant(ctx, r.TenantKey, r.TenantName, idQuery)
+	setTenant(ctx, r.TenantKey, r.TenantName, pass)
 
+	// the replaced password is pushed to the front of the history, so that the latest is the first one
+	pushQuery := bson.M{
+		r.HistoryName: bson.M{"$each": []bson.M{{r.PasswordName: currentPassword, r.TimestampName: time.Now()}}, "$position": 0},
+	}
 	updateQuery := bson.M{
 		"$set": pass,
-		"$addToSet": bson.M{
-			r.HistoryName: bson.M{r.PasswordName: currentPassword, r.TimestampName: time.Now()},
-		},
 	}
-	result, err := r.HistoryCollection.UpdateOne(ctx, idQuery, updateQuery)
+	if r.HistoryCollection.Name() == r.PasswordCollection.Name() {
+		updateQuery["$push"] = pushQuery
+	} else {
+		if _, err := r.HistoryCollection.UpdateOne(ctx, idQuery, bson.M{"$push": pushQuery}, options.Update().SetUpsert(true)); err != nil {
+			return 0, err
+		}
+	}
+	result, err := r.PasswordCollection.UpdateOne(ctx, idQuery, updateQuery, options.Update().SetUpsert(true))
+	if err != nil {
+		return 0, err
+	}
 	if result.ModifiedCount > 0 {
 		return result.ModifiedCount, err
 	} else if result.UpsertedCount > 0 {
@@ -214,45 +221,38 @@ func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, user
 
 func (r *PasswordRepository) GetHistory(ctx context.Context, userId string, max int) ([]string, error) {
 	history := make([]string, 0)
-	if ctx.Value(r.HistoryName) != nil {
-		history = ctx.Value(r.HistoryName).([]string)
-	} else {
-		findOptions := options.FindOne()
-		findOptions.SetProjection(map[string]int{r.HistoryName: 1, "_id": 0})
-		query := bson.M{"_id": userId}
-		x := r.PasswordCollection.FindOne(ctx, query, findOptions)
-		er1 := x.Err()
-		if er1 != nil {
-			if strings.Compare(fmt.Sprint(er1), "mongo: no documents in result") == 0 {
-				return history, nil
-			}
-			return history, er1
+	findOptions := options.FindOne()
+	findOptions.SetProjection(map[string]int{r.HistoryName: 1, "_id": 0})
+	query := bson.M{"_id": userId}
+	setTenant(ctx, r.TenantKey, r.TenantName, query)
+	x := r.HistoryCollection.FindOne(ctx, query, findOptions)
+	er1 := x.Err()
+	if er1 != nil {
+		if strings.Compare(fmt.Sprint(er1), "mongo: no documents in result") == 0 {
+			return history, nil
 		}
-		k, er2 := x.DecodeBytes()
-		if er2 != nil {
-			return history, er2
+		return history, er1
+	}
+	k, er2 := x.DecodeBytes()
+	if er2 != nil {
+		return history, er2
+	}
+	rawValue := k.Lookup(r.HistoryName)
+	if rawValue.Type == bsontype.Array {
+		// found column HistoryName
+		rawValues, er3 := rawValue.Array().Values()
+		if er3 != nil {
+			return history, er3
 		}
-		rawValue := k.Lookup(r.HistoryName)
-		if rawValue.Type == bsontype.Array {
-			// found column HistoryName
-			rawValues, er3 := rawValue.Array().Values()
-			if er3 != nil {
-				return history, er3
-			}
-			for i := range rawValues {
-				if password, ok := rawValues[i].Document().Lookup(r.PasswordName).StringValueOK(); ok {
-					history = append(history, password)
-				}
+		for i := range rawValues {
+			if password, ok := rawValues[i].Document().Lookup(r.PasswordName).StringValueOK(); ok {
+				history = append(history, password)
 			}
 		}
 	}
-	if len(history) > 0 {
-		start := len(history) - 1 - max
-		end := len(history) - 1
-		if start < 0 {
-			start = 0
-		}
-		return history[start:end], nil
+	// the history is the replaced passwords, the latest first
+	if max > 0 && len(history) > max {
+		return history[:max], nil
 	}
 	return history, nil
 
@@ -0,0 +1,75 @@
+//go:build integration
+
+package mongo
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/testkit"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// openDatabase connects to MONGO_URI, and drops the database of the test before and after each case.
+// Run with: MONGO_URI=mongodb://localhost:27017 go test -tags integration ./mongo
+func openDatabase(t *testing.T) *mongo.Database {
+	uri := os.Getenv("MONGO_URI")
+	if len(uri) == 0 {
+		t.Skip("MONGO_URI is not set")
+	}
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := client.Database("password_test")
+	if err = db.Drop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+	return db
+}
+
+func TestPasswordRepositoryContract(t *testing.T) {
+	testkit.RunPasswordRepositoryContract(t, testkit.PasswordRepositoryContract{
+		Key: "userId",
+		New: func(t *testing.T, users []testkit.User) p.PasswordRepository {
+			db := openDatabase(t)
+			ctx := context.Background()
+			for _, u := range users {
+				if _, err := db.Collection("users").InsertOne(ctx, bson.M{"_id": u.Id, "username": u.Username, "email": u.Email}); err != nil {
+					t.Fatal(err)
+				}
+				if len(u.Password) > 0 {
+					if _, err := db.Collection("passwords").InsertOne(ctx, bson.M{"_id": u.Id, "password": u.Password, "failCount": u.FailCount}); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			return NewPasswordRepository(db, "users", "passwords", "passwords", "userId", "password", "email", "username", "changedTime", "failCount", "changedBy", "history", "timestamp")
+		},
+		Inspect: func(t *testing.T, repository p.PasswordRepository, id string) (string, int) {
+			var doc struct {
+				ChangedBy string `bson:"changedBy"`
+				FailCount int    `bson:"failCount"`
+			}
+			if err := repository.(*PasswordRepository).PasswordCollection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&doc); err != nil {
+				t.Fatal(err)
+			}
+			return doc.ChangedBy, doc.FailCount
+		},
+	})
+}
+
+func TestVerificationCodeRepositoryContract(t *testing.T) {
+	testkit.RunVerificationCodeRepositoryContract(t, func(t *testing.T) p.VerificationCodeRepository {
+		return NewDefaultVerificationCodeRepository(openDatabase(t), "codes")
+	})
+}
@@ -0,0 +1,85 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RecoveryCodeRepository struct {
+	Collection *mongo.Collection
+	CodeName   string
+	TenantKey  string // Tenant Id from context
+	TenantName string // the tenant field; when it is set, the codes are filtered by the tenant of the context, and the ids must be unique across the tenants
+}
+
+func NewDefaultRecoveryCodeRepository(db *mongo.Database, collectionName string) *RecoveryCodeRepository {
+	return NewRecoveryCodeRepository(db, collectionName, "codes")
+}
+
+func NewRecoveryCodeRepository(db *mongo.Database, collectionName, codeName string) *RecoveryCodeRepository {
+	if len(codeName) == 0 {
+		codeName = "codes"
+	}
+	return &RecoveryCodeRepository{Collection: db.Collection(collectionName), CodeName: codeName}
+}
+
+func (r *RecoveryCodeRepository) Save(ctx context.Context, id string, codes []string) (int64, error) {
+	query := bson.M{"_id": id}
+	doc := bson.M{"_id": id, r.CodeName: codes}
+	setTenant(ctx, r.TenantKey, r.TenantName, query)
+	setTenant(ctx, r.TenantKey, r.TenantName, doc)
+	_, err := r.Collection.ReplaceOne(ctx, query, doc, options.Replace().SetUpsert(true))
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(codes)), nil
+}
+
+func (r *RecoveryCodeRepository) Load(ctx context.Context, id string) ([]string, error) {
+	codes := make([]string, 0)
+	query := bson.M{"_id": id}
+	setTenant(ctx, r.TenantKey, r.TenantName, query)
+	x := r.Collection.FindOne(ctx, query)
+	er1 := x.Err()
+	if er1 != nil {
+		if strings.Compare(fmt.Sprint(er1), "mongo: no documents in result") == 0 {
+			return codes, nil
+		}
+		return codes, er1
+	}
+	k, er2 := x.DecodeBytes()
+	if er2 != nil {
+		return codes, er2
+	}
+	rawValue := k.Lookup(r.CodeName)
+	if rawValue.Type != bsontype.Array {
+		return codes, nil
+	}
+	rawValues, er3 := rawValue.Array().Values()
+	if er3 != nil {
+		return codes, er3
+	}
+	for i := range rawValues {
+		if code, ok := rawValues[i].StringValueOK(); ok {
+			codes = append(codes, code)
+		}
+	}
+	return codes, nil
+}
+
+func (r *RecoveryCodeRepository) Delete(ctx context.Context, id string, code string) (int64, error) {
+	query := bson.M{"_id": id, r.CodeName: code}
+	setTenant(ctx, r.TenantKey, r.TenantName, query)
+	update := bson.M{"$pull": bson.M{r.CodeName: code}}
+	result, err := r.Collection.UpdateOne(ctx, query, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,10 @@
+package mongo
+
+import "context"
+
+// setTenant sets the tenant of the context to the query or the document, when tenantName is set, which means the collection is multi-tenant.
+func setTenant(ctx context.Context, tenantKey string, tenantName string, m map[string]interface{}) {
+	if len(tenantName) > 0 {
+		m[tenantName] = getString(ctx, tenantKey)
+	}
+}
@@ -0,0 +1,95 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	p "github.com/core-go/password"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type VerificationCodeRepository struct {
+	Collection    *mongo.Collection
+	PasscodeName  string
+	ExpiredAtName string
+	TenantKey     string // Tenant Id from context
+	TenantName    string // the tenant field; when it is set, the codes are filtered by the tenant of the context, and the ids must be unique across the tenants
+}
+
+func NewVerificationCodeRepositoryByConfig(db *mongo.Database, collectionName string, c p.VerificationCodeSchemaConfig) *VerificationCodeRepository {
+	return NewVerificationCodeRepository(db, collectionName, c.Passcode, c.ExpiredAt)
+}
+
+func NewDefaultVerificationCodeRepository(db *mongo.Database, collectionName string) *VerificationCodeRepository {
+	return NewVerificationCodeRepository(db, collectionName, "passcode", "expiredAt")
+}
+
+// NewVerificationCodeRepository uses the id as _id.
+func NewVerificationCodeRepository(db *mongo.Database, collectionName, passcodeName, expiredAtName string) *VerificationCodeRepository {
+	if len(passcodeName) == 0 {
+		passcodeName = "passcode"
+	}
+	if len(expiredAtName) == 0 {
+		expiredAtName = "expiredAt"
+	}
+	return &VerificationCodeRepository{Collection: db.Collection(collectionName), PasscodeName: passcodeName, ExpiredAtName: expiredAtName}
+}
+
+// CreateTTLIndex creates the TTL index, so that MongoDB removes the codes after they expire.
+// MongoDB removes the expired documents once a minute, so Load may still return an expired code.
+func (r *VerificationCodeRepository) CreateTTLIndex(ctx context.Context) (string, error) {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: r.ExpiredAtName, Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+	return r.Collection.Indexes().CreateOne(ctx, index)
+}
+
+func (r *VerificationCodeRepository) Save(ctx context.Context, id string, passcode string, expireAt time.Time) (int64, error) {
+	query := bson.M{"_id": id}
+	doc := bson.M{"_id": id, r.PasscodeName: passcode, r.ExpiredAtName: expireAt}
+	setTenant(ctx, r.TenantKey, r.TenantName, query)
+	setTenant(ctx, r.TenantKey, r.TenantName, doc)
+	_, err := r.Collection.ReplaceOne(ctx, query, doc, options.Replace().SetUpsert(true))
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (r *VerificationCodeRepository) Load(ctx context.Context, id string) (string, time.Time, error) {
+	query := bson.M{"_id": id}
+	setTenant(ctx, r.TenantKey, r.TenantName, query)
+	x := r.Collection.FindOne(ctx, query)
+	er1 := x.Err()
+	if er1 != nil {
+		if strings.Compare(fmt.Sprint(er1), "mongo: no documents in result") == 0 {
+			return "", time.Time{}, nil
+		}
+		return "", time.Time{}, er1
+	}
+	k, er2 := x.DecodeBytes()
+	if er2 != nil {
+		return "", time.Time{}, er2
+	}
+	passcode, _ := k.Lookup(r.PasscodeName).StringValueOK()
+	var expiredAt time.Time
+	if t, ok := k.Lookup(r.ExpiredAtName).TimeOK(); ok {
+		expiredAt = t
+	}
+	return passcode, expiredAt, nil
+}
+
+func (r *VerificationCodeRepository) Delete(ctx context.Context, id string) (int64, error) {
+	query := bson.M{"_id": id}
+	setTenant(ctx, r.TenantKey, r.TenantName, query)
+	result, err := r.Collection.DeleteOne(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,16 @@
+package mux
+
+import (
+	p "github.com/core-go/password"
+	"github.com/gorilla/mux"
+)
+
+// RegisterRoutes registers the actions of the net/http handler under the prefix, such as "/password":
+// POST change, GET forgot/{contact}, POST forgot, POST reset and POST deny.
+func RegisterRoutes(r *mux.Router, prefix string, h *p.PasswordHandler) {
+	r.HandleFunc(prefix+"/change", h.ChangePassword).Methods("POST")
+	r.HandleFunc(prefix+"/forgot/{contact}", h.ForgotPassword).Methods("GET")
+	r.HandleFunc(prefix+"/forgot", h.ForgotPassword).Methods("POST")
+	r.HandleFunc(prefix+"/reset", h.ResetPassword).Methods("POST")
+	r.HandleFunc(prefix+"/deny", h.DenyChange).Methods("POST")
+}
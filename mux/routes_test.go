@@ -0,0 +1,19 @@
+package mux
+
+import (
+	"testing"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/testkit"
+	"github.com/gorilla/mux"
+)
+
+func TestHandlerContract(t *testing.T) {
+	testkit.RunHandlerContract(t, testkit.HandlerContract{
+		New: func(t *testing.T, service p.PasswordService, options ...p.HandlerOption) testkit.Handle {
+			r := mux.NewRouter()
+			RegisterRoutes(r, "/password", p.NewHandler(service, options...))
+			return testkit.ServeHTTP(r)
+		},
+	})
+}
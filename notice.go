@@ -0,0 +1,98 @@
+package password
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// notify sends the "your password was changed" notice with the one-time "this wasn't me" link, when NoticeCodeRepository and Delivery are set.
+// The notice is sent to the email, or to the contacts of Delivery when the email is empty.
+// The failure of the notice does not fail the operation, since the password has already been changed; it is logged and audited as NoticeFailed.
+func (s PasswordUseCase) notify(ctx context.Context, userId string, username string, email string) {
+	if !s.noticing() {
+		return
+	}
+	code := generateRecoveryCode(20)
+	hashedCode, er1 := s.PasswordComparator.Hash(code)
+	if er1 != nil {
+		log.Println(er1)
+		return
+	}
+	expires := s.NoticeExpires
+	if expires <= 0 {
+		expires = 7 * 24 * 60 * 60
+	}
+	expiredAt := addSeconds(time.Now(), expires)
+	if _, er2 := s.NoticeCodeRepository.Save(ctx, userId, hashedCode, expiredAt); er2 != nil {
+		log.Println(er2)
+		return
+	}
+	message := DeliveryMessage{UserId: userId, Username: username, Destination: email, Code: code, ExpireAt: expiredAt, Locale: GetLocale(ctx), Purpose: PurposeNotice}
+	if s.NoticeLink != nil {
+		message.Link = s.NoticeLink(ctx, username, code)
+	}
+	if _, er3 := s.Delivery.Deliver(ctx, message); er3 != nil {
+		if len(email) == 0 {
+			log.Printf("cannot send the notice to user %s, who has no email: %v", userId, er3)
+		} else {
+			log.Printf("cannot send the notice to user %s: %v", userId, er3)
+		}
+		s.audit(ctx, EventNoticeFailed, userId, er3.Error())
+	}
+}
+
+// noticing reports whether the notice is sent after the password is changed or reset.
+func (s PasswordUseCase) noticing() bool {
+	return s.NoticeCodeRepository != nil && s.Delivery != nil
+}
+
+// DenyChange handles the "this wasn't me" link: it locks the account, revokes all tokens and sends a reset code to start a forced reset.
+// The code can be used only once, and is deleted when a wrong or expired code is given.
+func (s PasswordUseCase) DenyChange(ctx context.Context, deny PasswordDeny) (PasswordResult, error) {
+	s = s.tenant(ctx)
+	if s.NoticeCodeRepository == nil || len(deny.Passcode) == 0 {
+		return s.result(ctx, 0, MessageInvalid), nil
+	}
+	userId, username, email, _, er0 := s.PasswordRepository.GetUser(ctx, deny.Username)
+	if len(userId) == 0 || er0 != nil {
+		return s.result(ctx, 0, MessageInvalid), er0
+	}
+	ctx = s.withLocale(ctx, userId)
+	code, expiredAt, er1 := s.NoticeCodeRepository.Load(ctx, userId)
+	if er1 != nil || len(code) == 0 {
+		return s.result(ctx, 0, MessageInvalid), er1
+	}
+	checkingCode(ctx)
+	if compareDate(expiredAt, time.Now()) < 0 {
+		deleteCode(ctx, s.NoticeCodeRepository, userId)
+		return s.result(ctx, 0, MessageExpired), nil
+	}
+	valid, er2 := s.PasswordComparator.Compare(deny.Passcode, code)
+	if !valid || er2 != nil {
+		if er2 == nil {
+			deleteCode(ctx, s.NoticeCodeRepository, userId)
+		}
+		return s.result(ctx, 0, MessageInvalid), er2
+	}
+	used, er3 := s.NoticeCodeRepository.Delete(ctx, userId)
+	if used <= 0 || er3 != nil {
+		return s.result(ctx, 0, MessageInvalid), er3
+	}
+	s.audit(ctx, EventChangeDenied, userId, "")
+	if s.Lock != nil {
+		if er4 := s.Lock(ctx, userId, "The user has denied the change of password."); er4 != nil {
+			return PasswordResult{Status: 0}, er4
+		}
+		s.audit(ctx, EventLockedOut, userId, EventChangeDenied)
+	}
+	if s.RevokeAllTokens != nil {
+		if er5 := s.RevokeAllTokens(ctx, userId, "The user has denied the change of password."); er5 != nil {
+			return PasswordResult{Status: 0}, er5
+		}
+	}
+	if _, er6 := s.sendResetCode(ctx, userId, username, email); er6 != nil {
+		return PasswordResult{Status: 0}, er6
+	}
+	return s.result(ctx, 1, MessageDenied), nil
+}
@@ -0,0 +1,78 @@
+package password_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	p "github.com/core-go/password"
+)
+
+func TestDenyChange(t *testing.T) {
+	tests := []struct {
+		name     string
+		saved    string
+		expireAt time.Time
+		deny     p.PasswordDeny
+		want     p.PasswordResult
+		denied   bool
+	}{
+		{"Valid", "h:abc", time.Now().Add(time.Hour), p.PasswordDeny{Username: "alice", Passcode: "abc"}, p.PasswordResult{Status: 1, Code: p.MessageDenied}, true},
+		{"Invalid", "h:abc", time.Now().Add(time.Hour), p.PasswordDeny{Username: "alice", Passcode: "xyz"}, p.PasswordResult{Code: p.MessageInvalid}, false},
+		{"Expired", "h:abc", time.Now().Add(-time.Minute), p.PasswordDeny{Username: "alice", Passcode: "abc"}, p.PasswordResult{Code: p.MessageExpired}, false},
+		{"WithoutCode", "", time.Time{}, p.PasswordDeny{Username: "alice", Passcode: "abc"}, p.PasswordResult{Code: p.MessageInvalid}, false},
+		{"UnknownUser", "h:abc", time.Now().Add(time.Hour), p.PasswordDeny{Username: "bob", Passcode: "abc"}, p.PasswordResult{Code: p.MessageInvalid}, false},
+		{"EmptyPasscode", "h:abc", time.Now().Add(time.Hour), p.PasswordDeny{Username: "alice"}, p.PasswordResult{Code: p.MessageInvalid}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			notices, resetCodes := newCodes(), newCodes()
+			if len(tt.saved) > 0 {
+				notices.Save(ctx, "u1", tt.saved, tt.expireAt)
+			}
+			box, locked, revoked := &outbox{}, &ids{}, &ids{}
+			s := p.PasswordUseCase{
+				PasswordComparator:      plain{},
+				PasswordRepository:      newUsers(user{id: "u1", username: "alice", email: "alice@example.com", password: "h:p0"}),
+				PasswordResetExpires:    600,
+				ResetPasscodeRepository: resetCodes,
+				SendResetCode:           box.send,
+				NoticeCodeRepository:    notices,
+				Lock:                    locked.add,
+				RevokeAllTokens:         revoked.add,
+			}
+			got, err := s.DenyChange(ctx, tt.deny)
+			if err != nil || got != tt.want {
+				t.Fatalf("DenyChange() = %+v, %v; want %+v", got, err, tt.want)
+			}
+			if !tt.denied {
+				if len(locked.list()) != 0 || len(revoked.list()) != 0 || len(box.codes()) != 0 {
+					t.Errorf("locked %v, revoked %v, sent %v; want nothing done", locked.list(), revoked.list(), box.codes())
+				}
+				if tt.deny.Username == "alice" && len(tt.deny.Passcode) > 0 && !notices.deleted("u1") {
+					t.Error("the notice code is kept; want it deleted after a wrong or expired code")
+				}
+				return
+			}
+			if want := []string{"u1"}; !reflect.DeepEqual(locked.list(), want) || !reflect.DeepEqual(revoked.list(), want) {
+				t.Errorf("locked %v, revoked %v; want %v", locked.list(), revoked.list(), want)
+			}
+			sent := box.codes()
+			if len(sent) != 1 || sent[0].to != "alice" {
+				t.Fatalf("sent %v; want a reset code to alice", sent)
+			}
+			if saved, _, _ := resetCodes.Load(ctx, "u1"); saved != "h:"+sent[0].code {
+				t.Errorf("reset code = %q; want the hash of the sent code %q", saved, sent[0].code)
+			}
+			// the code is used only once
+			if again, err := s.DenyChange(ctx, tt.deny); err != nil || again.Status != 0 {
+				t.Errorf("DenyChange() again = %+v, %v; want status 0", again, err)
+			}
+			if len(locked.list()) != 1 || len(box.codes()) != 1 {
+				t.Errorf("locked %v, sent %v; want the second call to do nothing", locked.list(), box.codes())
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package otel
+
+import (
+	"context"
+	"time"
+
+	p "github.com/core-go/password"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type Deliverer struct {
+	Deliverer       p.Deliverer
+	Instrumentation *Instrumentation
+}
+
+func NewDeliverer(deliverer p.Deliverer, instrumentation *Instrumentation) *Deliverer {
+	return &Deliverer{Deliverer: deliverer, Instrumentation: instrumentation}
+}
+
+// Deliver records the channel which was actually used; the destination and the code are never recorded.
+func (d *Deliverer) Deliver(ctx context.Context, message p.DeliveryMessage) (p.DeliveryMessage, error) {
+	ctx, span := d.Instrumentation.start(ctx, "Deliverer.Deliver", attribute.String("password.purpose", message.Purpose))
+	start := time.Now()
+	sent, err := d.Deliverer.Deliver(ctx, message)
+	attributes := []attribute.KeyValue{attribute.String("password.purpose", message.Purpose), attribute.String("password.channel", sent.Channel), attribute.String("password.outcome", outcomeOf(err))}
+	d.Instrumentation.DeliveryDuration.Record(ctx, seconds(start), metric.WithAttributes(attributes...))
+	span.SetAttributes(attribute.String("password.channel", sent.Channel))
+	end(span, OutcomeSuccess, err)
+	return sent, err
+}
@@ -0,0 +1,94 @@
+package otel
+
+import (
+	"context"
+	"time"
+
+	ot "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const InstrumentationName = "github.com/core-go/password"
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Instrumentation holds the tracer and the instruments shared by the decorators.
+type Instrumentation struct {
+	Tracer           trace.Tracer
+	ResetsRequested  metric.Int64Counter
+	CodesFailed      metric.Int64Counter
+	Operations       metric.Int64Counter
+	HashDuration     metric.Float64Histogram
+	DeliveryDuration metric.Float64Histogram
+}
+
+// NewInstrumentation uses the global providers when the providers are nil.
+func NewInstrumentation(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) (*Instrumentation, error) {
+	if tracerProvider == nil {
+		tracerProvider = ot.GetTracerProvider()
+	}
+	if meterProvider == nil {
+		meterProvider = ot.GetMeterProvider()
+	}
+	meter := meterProvider.Meter(InstrumentationName)
+	resetsRequested, err := meter.Int64Counter("password.reset.requested", metric.WithDescription("The number of the requested resets"))
+	if err != nil {
+		return nil, err
+	}
+	codesFailed, err := meter.Int64Counter("password.code.failed", metric.WithDescription("The number of the invalid or expired codes"))
+	if err != nil {
+		return nil, err
+	}
+	operations, err := meter.Int64Counter("password.operations", metric.WithDescription("The number of the operations by operation and outcome"))
+	if err != nil {
+		return nil, err
+	}
+	hashDuration, err := meter.Float64Histogram("password.hash.duration", metric.WithDescription("The duration of hashing and comparing"), metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	deliveryDuration, err := meter.Float64Histogram("password.delivery.duration", metric.WithDescription("The duration of the delivery of the codes"), metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	return &Instrumentation{
+		Tracer:           tracerProvider.Tracer(InstrumentationName),
+		ResetsRequested:  resetsRequested,
+		CodesFailed:      codesFailed,
+		Operations:       operations,
+		HashDuration:     hashDuration,
+		DeliveryDuration: deliveryDuration,
+	}, nil
+}
+
+func (i *Instrumentation) start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return i.Tracer.Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// end sets the outcome and the status of the span, and ends it.
+func end(span trace.Span, outcome string, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		outcome = OutcomeError
+	}
+	span.SetAttributes(attribute.String("password.outcome", outcome))
+	span.End()
+}
+
+func outcomeOf(err error) string {
+	if err != nil {
+		return OutcomeError
+	}
+	return OutcomeSuccess
+}
+
+func seconds(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
@@ -0,0 +1,68 @@
+package otel
+
+import (
+	"context"
+
+	p "github.com/core-go/password"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// PasswordRepository traces the calls of the repository, with the backend such as "sql", "mongo" or "cassandra".
+type PasswordRepository struct {
+	Repository      p.PasswordRepository
+	Backend         string
+	Instrumentation *Instrumentation
+}
+
+func NewPasswordRepository(repository p.PasswordRepository, backend string, instrumentation *Instrumentation) *PasswordRepository {
+	return &PasswordRepository{Repository: repository, Backend: backend, Instrumentation: instrumentation}
+}
+
+func (r *PasswordRepository) GetUserId(ctx context.Context, username string) (string, error) {
+	ctx, span := r.Instrumentation.start(ctx, "PasswordRepository.GetUserId", attribute.String("password.backend", r.Backend))
+	userId, err := r.Repository.GetUserId(ctx, username)
+	end(span, found(userId), err)
+	return userId, err
+}
+
+func (r *PasswordRepository) GetUser(ctx context.Context, usernameOrEmail string) (string, string, string, string, error) {
+	ctx, span := r.Instrumentation.start(ctx, "PasswordRepository.GetUser", attribute.String("password.backend", r.Backend))
+	userId, username, email, password, err := r.Repository.GetUser(ctx, usernameOrEmail)
+	end(span, found(userId), err)
+	return userId, username, email, password, err
+}
+
+func (r *PasswordRepository) Update(ctx context.Context, userId string, newPassword string) (int64, error) {
+	ctx, span := r.Instrumentation.start(ctx, "PasswordRepository.Update", attribute.String("password.backend", r.Backend))
+	count, err := r.Repository.Update(ctx, userId, newPassword)
+	end(span, affected(count), err)
+	return count, err
+}
+
+func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, userId string, currentPassword, newPassword string) (int64, error) {
+	ctx, span := r.Instrumentation.start(ctx, "PasswordRepository.UpdateWithCurrentPassword", attribute.String("password.backend", r.Backend))
+	count, err := r.Repository.UpdateWithCurrentPassword(ctx, userId, currentPassword, newPassword)
+	end(span, affected(count), err)
+	return count, err
+}
+
+func (r *PasswordRepository) GetHistory(ctx context.Context, userId string, max int) ([]string, error) {
+	ctx, span := r.Instrumentation.start(ctx, "PasswordRepository.GetHistory", attribute.String("password.backend", r.Backend), attribute.Int("password.history.max", max))
+	history, err := r.Repository.GetHistory(ctx, userId, max)
+	end(span, OutcomeSuccess, err)
+	return history, err
+}
+
+func found(id string) string {
+	if len(id) == 0 {
+		return "not_found"
+	}
+	return OutcomeSuccess
+}
+
+func affected(count int64) string {
+	if count <= 0 {
+		return "not_affected"
+	}
+	return OutcomeSuccess
+}
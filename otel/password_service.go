@@ -0,0 +1,110 @@
+package otel
+
+import (
+	"context"
+
+	p "github.com/core-go/password"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PasswordService traces the operations, with the step of the change and the outcome, and counts the requested resets and the failed codes.
+type PasswordService struct {
+	Service         p.PasswordService
+	Instrumentation *Instrumentation
+}
+
+func NewPasswordService(service p.PasswordService, instrumentation *Instrumentation) *PasswordService {
+	return &PasswordService{Service: service, Instrumentation: instrumentation}
+}
+
+func (s *PasswordService) ForgotPassword(ctx context.Context, email string) (bool, error) {
+	ctx, span := s.Instrumentation.start(ctx, "PasswordService.ForgotPassword")
+	sent, err := s.Service.ForgotPassword(ctx, email)
+	outcome := OutcomeSuccess
+	if !sent {
+		outcome = "not_sent"
+	} else if err == nil {
+		s.Instrumentation.ResetsRequested.Add(ctx, 1)
+	}
+	s.count(ctx, "forgot", outcome, err)
+	end(span, outcome, err)
+	return sent, err
+}
+
+func (s *PasswordService) ResetPassword(ctx context.Context, pass p.PasswordReset) (int32, error) {
+	result, err := s.ResetPasswordWithResult(ctx, pass)
+	return result.Status, err
+}
+
+func (s *PasswordService) ResetPasswordWithResult(ctx context.Context, pass p.PasswordReset) (p.PasswordResult, error) {
+	ctx, span := s.Instrumentation.start(ctx, "PasswordService.ResetPassword", attribute.String("password.factor", pass.Factor))
+	ctx, check := p.WithCodeCheck(ctx)
+	result, err := p.ResultService(s.Service).ResetPasswordWithResult(ctx, pass)
+	s.record(ctx, span, "reset", result, err, check.Checked)
+	return result, err
+}
+
+func (s *PasswordService) ChangePassword(ctx context.Context, pass p.PasswordChange) (int32, error) {
+	result, err := s.ChangePasswordWithResult(ctx, pass)
+	return result.Status, err
+}
+
+func (s *PasswordService) ChangePasswordWithResult(ctx context.Context, pass p.PasswordChange) (p.PasswordResult, error) {
+	ctx, span := s.Instrumentation.start(ctx, "PasswordService.ChangePassword", attribute.Int("password.step", pass.Step), attribute.String("password.factor", pass.Factor))
+	ctx, check := p.WithCodeCheck(ctx)
+	result, err := p.ResultService(s.Service).ChangePasswordWithResult(ctx, pass)
+	s.record(ctx, span, "change", result, err, check.Checked)
+	return result, err
+}
+
+func (s *PasswordService) DenyChange(ctx context.Context, deny p.PasswordDeny) (p.PasswordResult, error) {
+	ctx, span := s.Instrumentation.start(ctx, "PasswordService.DenyChange")
+	ctx, check := p.WithCodeCheck(ctx)
+	result, err := p.ResultService(s.Service).DenyChange(ctx, deny)
+	s.record(ctx, span, "deny", result, err, check.Checked)
+	return result, err
+}
+
+// ValidatePassword forwards to the service, if it is a PasswordValidator.
+func (s *PasswordService) ValidatePassword(ctx context.Context, username string, password string) (p.PasswordResult, error) {
+	if v, ok := s.Service.(p.PasswordValidator); ok {
+		return v.ValidatePassword(ctx, username, password)
+	}
+	return p.PasswordResult{Status: 0}, p.ErrValidationNotSupported
+}
+
+// record counts the failed codes only when the service has checked a passcode, and not for the wrong current passwords or the unknown users.
+func (s *PasswordService) record(ctx context.Context, span trace.Span, operation string, result p.PasswordResult, err error, passcode bool) {
+	outcome := Outcome(result)
+	span.SetAttributes(attribute.Int("password.status", int(result.Status)), attribute.String("password.code", result.Code))
+	if err == nil && passcode && (result.Code == p.MessageInvalid || result.Code == p.MessageExpired) {
+		s.Instrumentation.CodesFailed.Add(ctx, 1, metric.WithAttributes(attribute.String("password.operation", operation), attribute.String("password.reason", result.Code)))
+	}
+	s.count(ctx, operation, outcome, err)
+	end(span, outcome, err)
+}
+
+func (s *PasswordService) count(ctx context.Context, operation string, outcome string, err error) {
+	if err != nil {
+		outcome = OutcomeError
+	}
+	s.Instrumentation.Operations.Add(ctx, 1, metric.WithAttributes(attribute.String("password.operation", operation), attribute.String("password.outcome", outcome)))
+}
+
+// Outcome returns the outcome of the status of the result: success, challenged, failed, duplicate or policy.
+func Outcome(result p.PasswordResult) string {
+	switch result.Status {
+	case 1:
+		return OutcomeSuccess
+	case 2:
+		return "challenged"
+	case -1:
+		return "duplicate"
+	case -2:
+		return "policy"
+	default:
+		return "failed"
+	}
+}
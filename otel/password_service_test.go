@@ -0,0 +1,237 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	p "github.com/core-go/password"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type plain struct{}
+
+func (plain) Hash(plaintext string) (string, error) {
+	return "h:" + plaintext, nil
+}
+func (plain) Compare(plaintext string, hashed string) (bool, error) {
+	return "h:"+plaintext == hashed, nil
+}
+
+// repository is a PasswordRepository of the user alice, which fails with err when it is set.
+type repository struct {
+	err error
+}
+
+func (r *repository) GetUserId(ctx context.Context, username string) (string, error) {
+	if r.err != nil || username != "alice" {
+		return "", r.err
+	}
+	return "u1", nil
+}
+func (r *repository) GetUser(ctx context.Context, usernameOrEmail string) (string, string, string, string, error) {
+	userId, err := r.GetUserId(ctx, usernameOrEmail)
+	return userId, usernameOrEmail, "", "h:p0", err
+}
+func (r *repository) Update(ctx context.Context, userId string, newPassword string) (int64, error) {
+	return 1, r.err
+}
+func (r *repository) UpdateWithCurrentPassword(ctx context.Context, userId string, currentPassword, newPassword string) (int64, error) {
+	return 1, r.err
+}
+func (r *repository) GetHistory(ctx context.Context, userId string, max int) ([]string, error) {
+	return nil, r.err
+}
+
+// codeRepository is a VerificationCodeRepository in a map, which is safe for the codes deleted in the background.
+type codeRepository struct {
+	mutex sync.Mutex
+	codes map[string]string
+}
+
+func (r *codeRepository) Save(ctx context.Context, id string, passcode string, expireAt time.Time) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.codes[id] = passcode
+	return 1, nil
+}
+func (r *codeRepository) Load(ctx context.Context, id string) (string, time.Time, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if code, ok := r.codes[id]; ok {
+		return code, time.Now().Add(time.Hour), nil
+	}
+	return "", time.Time{}, nil
+}
+func (r *codeRepository) Delete(ctx context.Context, id string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.codes, id)
+	return 1, nil
+}
+
+// instrument returns the Instrumentation with a span recorder and a manual reader.
+func instrument(t *testing.T) (*Instrumentation, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	instrumentation, err := NewInstrumentation(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return instrumentation, recorder, reader
+}
+
+// sum returns the sum of the data points of the counter which have all the attributes.
+func sum(t *testing.T, reader *sdkmetric.ManualReader, name string, attributes ...attribute.KeyValue) int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	var total int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				matched := true
+				for _, a := range attributes {
+					if v, ok := dp.Attributes.Value(a.Key); !ok || v != a.Value {
+						matched = false
+					}
+				}
+				if matched {
+					total += dp.Value
+				}
+			}
+		}
+	}
+	return total
+}
+
+func attributeOf(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, a := range span.Attributes() {
+		if a.Key == key {
+			return a.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestPasswordServiceResetPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		passcode string
+		err      error
+		status   int32
+		outcome  string
+		code     codes.Code
+		failed   int64
+	}{
+		{"Success", "123456", nil, 1, OutcomeSuccess, codes.Unset, 0},
+		{"InvalidCode", "000000", nil, 0, "failed", codes.Unset, 1},
+		{"RepositoryError", "123456", errors.New("connection refused"), 0, OutcomeError, codes.Error, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instrumentation, recorder, reader := instrument(t)
+			resetCodes := &codeRepository{codes: map[string]string{"u1": "h:123456"}}
+			service := NewPasswordService(&p.PasswordUseCase{
+				PasswordComparator:      plain{},
+				PasswordRepository:      NewPasswordRepository(&repository{err: tt.err}, "memory", instrumentation),
+				ResetPasscodeRepository: NewVerificationCodeRepository(resetCodes, "memory", instrumentation),
+			}, instrumentation)
+			result, err := service.ResetPasswordWithResult(context.Background(), p.PasswordReset{Username: "alice", Passcode: tt.passcode, Password: "p1"})
+			if result.Status != tt.status || !errors.Is(err, tt.err) {
+				t.Fatalf("ResetPasswordWithResult() = %+v, %v; want status %d, %v", result, err, tt.status, tt.err)
+			}
+
+			var root sdktrace.ReadOnlySpan
+			for _, span := range recorder.Ended() {
+				if span.Name() == "PasswordService.ResetPassword" {
+					root = span
+				}
+			}
+			if root == nil {
+				t.Fatalf("no span PasswordService.ResetPassword in %d spans", len(recorder.Ended()))
+			}
+			if got := attributeOf(root, "password.outcome").AsString(); got != tt.outcome {
+				t.Errorf("password.outcome = %q; want %q", got, tt.outcome)
+			}
+			if got := attributeOf(root, "password.status").AsInt64(); got != int64(tt.status) {
+				t.Errorf("password.status = %d; want %d", got, tt.status)
+			}
+			if root.Status().Code != tt.code {
+				t.Errorf("status = %v; want %v", root.Status().Code, tt.code)
+			}
+			for _, span := range recorder.Ended() {
+				if span != root && span.Parent().SpanID() != root.SpanContext().SpanID() {
+					t.Errorf("the span %s is not a child of the span of the service", span.Name())
+				}
+				if span.Name() == "PasswordRepository.GetUserId" && attributeOf(span, "password.backend").AsString() != "memory" {
+					t.Errorf("password.backend of %s = %v; want memory", span.Name(), attributeOf(span, "password.backend"))
+				}
+			}
+
+			if got := sum(t, reader, "password.operations", attribute.String("password.operation", "reset"), attribute.String("password.outcome", tt.outcome)); got != 1 {
+				t.Errorf("password.operations{reset,%s} = %d; want 1", tt.outcome, got)
+			}
+			if got := sum(t, reader, "password.code.failed", attribute.String("password.operation", "reset"), attribute.String("password.reason", p.MessageInvalid)); got != tt.failed {
+				t.Errorf("password.code.failed = %d; want %d", got, tt.failed)
+			}
+		})
+	}
+}
+
+func TestPasswordServiceCodeFailedOnlyWhenChecked(t *testing.T) {
+	instrumentation, _, reader := instrument(t)
+	service := NewPasswordService(&p.PasswordUseCase{
+		PasswordComparator:      plain{},
+		PasswordRepository:      &repository{},
+		ResetPasscodeRepository: &codeRepository{codes: map[string]string{}},
+	}, instrumentation)
+	ctx := context.Background()
+	// the unknown user and the user without a code have no passcode to check
+	for _, username := range []string{"bob", "alice"} {
+		if result, err := service.ResetPasswordWithResult(ctx, p.PasswordReset{Username: username, Passcode: "123456", Password: "p1"}); err != nil || result.Status != 0 {
+			t.Fatalf("ResetPasswordWithResult(%s) = %+v, %v; want status 0", username, result, err)
+		}
+	}
+	if got := sum(t, reader, "password.code.failed"); got != 0 {
+		t.Errorf("password.code.failed = %d; want 0 when no passcode was checked", got)
+	}
+	if got := sum(t, reader, "password.operations", attribute.String("password.outcome", "failed")); got != 2 {
+		t.Errorf("password.operations{failed} = %d; want 2", got)
+	}
+}
+
+func TestPasswordServiceForgotPassword(t *testing.T) {
+	instrumentation, recorder, reader := instrument(t)
+	service := NewPasswordService(&p.PasswordUseCase{
+		PasswordComparator:      plain{},
+		PasswordRepository:      &repository{},
+		PasswordResetExpires:    600,
+		ResetPasscodeRepository: &codeRepository{codes: map[string]string{}},
+		SendResetCode: func(ctx context.Context, to string, code string, expireAt time.Time, params interface{}) error {
+			return nil
+		},
+	}, instrumentation)
+	if sent, err := service.ForgotPassword(context.Background(), "alice"); !sent || err != nil {
+		t.Fatalf("ForgotPassword() = %v, %v", sent, err)
+	}
+	if got := sum(t, reader, "password.reset.requested"); got != 1 {
+		t.Errorf("password.reset.requested = %d; want 1", got)
+	}
+	if spans := recorder.Ended(); len(spans) != 1 || spans[0].Name() != "PasswordService.ForgotPassword" {
+		t.Errorf("spans = %v; want PasswordService.ForgotPassword", spans)
+	}
+}
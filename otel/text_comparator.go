@@ -0,0 +1,39 @@
+package otel
+
+import (
+	"context"
+	"time"
+
+	p "github.com/core-go/password"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// TextComparator records the duration of hashing and comparing, which is usually the slowest part of the operations.
+// TextComparator has no context, so there is no span.
+type TextComparator struct {
+	Comparator      p.TextComparator
+	Instrumentation *Instrumentation
+}
+
+func NewTextComparator(comparator p.TextComparator, instrumentation *Instrumentation) *TextComparator {
+	return &TextComparator{Comparator: comparator, Instrumentation: instrumentation}
+}
+
+func (c *TextComparator) Compare(plaintext string, hashed string) (bool, error) {
+	start := time.Now()
+	valid, err := c.Comparator.Compare(plaintext, hashed)
+	c.record(start, "compare", err)
+	return valid, err
+}
+
+func (c *TextComparator) Hash(plaintext string) (string, error) {
+	start := time.Now()
+	hashed, err := c.Comparator.Hash(plaintext)
+	c.record(start, "hash", err)
+	return hashed, err
+}
+
+func (c *TextComparator) record(start time.Time, operation string, err error) {
+	c.Instrumentation.HashDuration.Record(context.Background(), seconds(start), metric.WithAttributes(attribute.String("password.operation", operation), attribute.String("password.outcome", outcomeOf(err))))
+}
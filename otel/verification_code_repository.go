@@ -0,0 +1,54 @@
+package otel
+
+import (
+	"context"
+	"time"
+
+	p "github.com/core-go/password"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type VerificationCodeRepository struct {
+	Repository      p.VerificationCodeRepository
+	Backend         string
+	Instrumentation *Instrumentation
+}
+
+func NewVerificationCodeRepository(repository p.VerificationCodeRepository, backend string, instrumentation *Instrumentation) *VerificationCodeRepository {
+	return &VerificationCodeRepository{Repository: repository, Backend: backend, Instrumentation: instrumentation}
+}
+
+func (r *VerificationCodeRepository) Save(ctx context.Context, id string, passcode string, expireAt time.Time) (int64, error) {
+	ctx, span := r.Instrumentation.start(ctx, "VerificationCodeRepository.Save", attribute.String("password.backend", r.Backend))
+	count, err := r.Repository.Save(ctx, id, passcode, expireAt)
+	end(span, affected(count), err)
+	return count, err
+}
+
+func (r *VerificationCodeRepository) Load(ctx context.Context, id string) (string, time.Time, error) {
+	ctx, span := r.Instrumentation.start(ctx, "VerificationCodeRepository.Load", attribute.String("password.backend", r.Backend))
+	code, expireAt, err := r.Repository.Load(ctx, id)
+	end(span, found(code), err)
+	return code, expireAt, err
+}
+
+func (r *VerificationCodeRepository) Delete(ctx context.Context, id string) (int64, error) {
+	ctx, span := r.Instrumentation.start(ctx, "VerificationCodeRepository.Delete", attribute.String("password.backend", r.Backend))
+	count, err := r.Repository.Delete(ctx, id)
+	end(span, affected(count), err)
+	return count, err
+}
+
+// Consume keeps the single use of the codes of a CodeConsumer; for the other repositories, it deletes the code.
+func (r *VerificationCodeRepository) Consume(ctx context.Context, id string, passcode string) (int64, error) {
+	ctx, span := r.Instrumentation.start(ctx, "VerificationCodeRepository.Consume", attribute.String("password.backend", r.Backend))
+	var count int64
+	var err error
+	if consumer, ok := r.Repository.(p.CodeConsumer); ok {
+		count, err = consumer.Consume(ctx, id, passcode)
+	} else {
+		count, err = r.Repository.Delete(ctx, id)
+	}
+	end(span, affected(count), err)
+	return count, err
+}
@@ -0,0 +1,140 @@
+package password
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// PasswordDependencies are the dependencies of NewPasswordServiceFromConfig, which are not in the config.
+type PasswordDependencies struct {
+	Comparator          TextComparator
+	Repository          PasswordRepository
+	ResetCodeRepository VerificationCodeRepository
+	// ChangeCodeRepository keeps the codes of the second factor. It must not be ResetCodeRepository, so that a code of a change cannot be used for a reset, and the reverse.
+	ChangeCodeRepository VerificationCodeRepository
+	SendResetCode        func(ctx context.Context, to string, code string, expireAt time.Time, params interface{}) error
+	// SendChangeCode sends the codes of the second factor; SendResetCode is used when it is nil.
+	SendChangeCode func(ctx context.Context, to string, code string, expireAt time.Time, params interface{}) error
+	// Delivery sends the codes instead of SendResetCode and SendChangeCode.
+	Delivery          Deliverer
+	RevokeAllTokens   func(ctx context.Context, id string, reason string) error
+	RequireTwoFactors func(ctx context.Context, id string) (bool, error)
+	ResolveFactors    func(ctx context.Context, id string) ([]Factor, error)
+	// Generate generates the codes; when it is nil, the codes have the length of Policy.Passcode, or 6 digits.
+	Generate func() string
+	// PolicyResolver overrides the policy of the config by the user; GetPolicyUser loads the roles and the groups of the user for it.
+	PolicyResolver PolicyResolver
+	GetPolicyUser  func(ctx context.Context, id string) (*PolicyUser, error)
+	// Blocklist rejects the passwords, in addition to the blocklist of Policy.
+	Blocklist Blocklist
+	AuditSink AuditSink
+	Key       string // User Id from context, as the actor of the audit events
+}
+
+// NewPasswordServiceFromConfig builds the service from the config and the dependencies.
+// It returns ValidationErrors, with the keys of the config or the names of the dependencies, when the combination is not valid.
+// ChangeExpires defaults to ResetExpires, and the TTLs of Policy.Passcode are used when the expiries are not set.
+func NewPasswordServiceFromConfig(c PasswordConfig, d PasswordDependencies) (*PasswordUseCase, error) {
+	var errs ValidationErrors
+	add := func(key string, message string) {
+		errs = append(errs, ValidationError{Key: key, Message: message})
+	}
+	s := &PasswordUseCase{
+		PasswordComparator:       d.Comparator,
+		PasswordRepository:       d.Repository,
+		PasswordResetExpires:     c.ResetExpires,
+		ResetPasscodeRepository:  d.ResetCodeRepository,
+		SendResetCode:            d.SendResetCode,
+		RevokeAllTokens:          d.RevokeAllTokens,
+		RequireTwoFactors:        d.RequireTwoFactors,
+		PasswordChangeExpires:    c.ChangeExpires,
+		ChangePasscodeRepository: d.ChangeCodeRepository,
+		SendChangeCode:           d.SendChangeCode,
+		ResolveFactors:           d.ResolveFactors,
+		Delivery:                 d.Delivery,
+		PolicyResolver:           d.PolicyResolver,
+		GetPolicyUser:            d.GetPolicyUser,
+		AuditSink:                d.AuditSink,
+		Key:                      d.Key,
+	}
+	if !reflect.DeepEqual(c.Policy, PolicyConfig{}) {
+		policy, err := c.Policy.Resolve("policy")
+		if err != nil {
+			if e, ok := err.(ValidationErrors); ok {
+				errs = append(errs, e...)
+			} else {
+				add("policy", err.Error())
+			}
+		} else if err := policy.Apply(s); err != nil {
+			add("policy", err.Error())
+		}
+	}
+	if d.Blocklist != nil {
+		s.Blocklist = joinBlocklists(s.Blocklist, d.Blocklist)
+	}
+	regExps := make([]regexp.Regexp, 0)
+	for i, expression := range []string{c.Exp1, c.Exp2, c.Exp3, c.Exp4, c.Exp5, c.Exp6} {
+		if len(expression) > 0 {
+			regExp, err := regexp.Compile(expression)
+			if err != nil {
+				add("exp"+strconv.Itoa(i+1), err.Error())
+				continue
+			}
+			regExps = append(regExps, *regExp)
+		}
+	}
+	s.Regexps = append(regExps, s.Regexps...)
+	if d.Generate != nil {
+		s.Generate = d.Generate
+	}
+	if s.PasswordChangeExpires <= 0 {
+		s.PasswordChangeExpires = s.PasswordResetExpires
+	}
+	if s.SendChangeCode == nil {
+		s.SendChangeCode = s.SendResetCode
+	}
+	if s.PasswordComparator == nil {
+		add("Comparator", "is required")
+	}
+	if s.PasswordRepository == nil {
+		add("Repository", "is required")
+	}
+	if s.ResetPasscodeRepository == nil {
+		add("ResetCodeRepository", "is required")
+	}
+	if s.SendResetCode == nil && s.Delivery == nil {
+		add("SendResetCode", "is required when Delivery is nil")
+	}
+	if s.PasswordResetExpires <= 0 {
+		add("reset_expires", "must be greater than 0")
+	}
+	if s.ChangePasscodeRepository != nil && sameRepository(s.ChangePasscodeRepository, s.ResetPasscodeRepository) {
+		add("ChangeCodeRepository", "must not be ResetCodeRepository")
+	}
+	if s.RequireTwoFactors != nil || s.ResolveFactors != nil {
+		if s.ChangePasscodeRepository == nil {
+			add("ChangeCodeRepository", "is required when RequireTwoFactors or ResolveFactors is set")
+		}
+		if s.SendChangeCode == nil && s.Delivery == nil {
+			add("SendChangeCode", "is required when RequireTwoFactors or ResolveFactors is set and Delivery is nil")
+		}
+		if s.PasswordChangeExpires <= 0 {
+			add("change_expires", "must be greater than 0 when RequireTwoFactors or ResolveFactors is set")
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return s, nil
+}
+
+// sameRepository returns true if the repositories are the same value, such as the same pointer.
+func sameRepository(a VerificationCodeRepository, b VerificationCodeRepository) bool {
+	if a == nil || b == nil || reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
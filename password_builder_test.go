@@ -0,0 +1,130 @@
+package password_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/memory"
+)
+
+func TestNewPasswordServiceFromConfig(t *testing.T) {
+	users := memory.NewPasswordRepository("userId", 5)
+	resetCodes := memory.NewVerificationCodeRepository()
+	changeCodes := memory.NewVerificationCodeRepository()
+	var sent outbox
+	twoFactors := func(ctx context.Context, id string) (bool, error) {
+		return true, nil
+	}
+	valid := p.PasswordDependencies{Comparator: plain{}, Repository: users, ResetCodeRepository: resetCodes, SendResetCode: sent.send}
+	tests := []struct {
+		name   string
+		config p.PasswordConfig
+		deps   func(d p.PasswordDependencies) p.PasswordDependencies
+		keys   []string
+	}{
+		{"Valid", p.PasswordConfig{ResetExpires: 600, Exp1: `.{8,}`}, nil, nil},
+		{"MissingComparator", p.PasswordConfig{ResetExpires: 600}, func(d p.PasswordDependencies) p.PasswordDependencies {
+			d.Comparator = nil
+			return d
+		}, []string{"Comparator"}},
+		{"MissingRepositories", p.PasswordConfig{ResetExpires: 600}, func(d p.PasswordDependencies) p.PasswordDependencies {
+			d.Repository = nil
+			d.ResetCodeRepository = nil
+			return d
+		}, []string{"Repository", "ResetCodeRepository"}},
+		{"MissingSender", p.PasswordConfig{ResetExpires: 600}, func(d p.PasswordDependencies) p.PasswordDependencies {
+			d.SendResetCode = nil
+			return d
+		}, []string{"SendResetCode"}},
+		{"MissingExpires", p.PasswordConfig{}, nil, []string{"reset_expires"}},
+		{"SharedResetAndChangeRepository", p.PasswordConfig{ResetExpires: 600}, func(d p.PasswordDependencies) p.PasswordDependencies {
+			d.ChangeCodeRepository = d.ResetCodeRepository
+			return d
+		}, []string{"ChangeCodeRepository"}},
+		{"TwoFactorsWithoutChangeRepository", p.PasswordConfig{ResetExpires: 600}, func(d p.PasswordDependencies) p.PasswordDependencies {
+			d.RequireTwoFactors = twoFactors
+			return d
+		}, []string{"ChangeCodeRepository"}},
+		{"TwoFactorsWithoutSender", p.PasswordConfig{ResetExpires: 600}, func(d p.PasswordDependencies) p.PasswordDependencies {
+			d.RequireTwoFactors = twoFactors
+			d.ChangeCodeRepository = changeCodes
+			d.SendResetCode = nil
+			return d
+		}, []string{"SendResetCode", "SendChangeCode"}},
+		{"TwoFactors", p.PasswordConfig{ResetExpires: 600}, func(d p.PasswordDependencies) p.PasswordDependencies {
+			d.RequireTwoFactors = twoFactors
+			d.ChangeCodeRepository = changeCodes
+			return d
+		}, nil},
+		{"BadExpression", p.PasswordConfig{ResetExpires: 600, Exp1: `.{8,}`, Exp3: `[a-`}, nil, []string{"exp3"}},
+		{"BadPolicy", p.PasswordConfig{ResetExpires: 600, Policy: p.PolicyConfig{Preset: "strong"}}, nil, []string{"policy.preset"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := valid
+			if tt.deps != nil {
+				d = tt.deps(d)
+			}
+			s, err := p.NewPasswordServiceFromConfig(tt.config, d)
+			if tt.keys == nil {
+				if err != nil || s == nil {
+					t.Fatalf("NewPasswordServiceFromConfig() = %v, %v; want a service", s, err)
+				}
+				return
+			}
+			if s != nil {
+				t.Errorf("NewPasswordServiceFromConfig() = %v; want no service", s)
+			}
+			var errs p.ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("NewPasswordServiceFromConfig() error = %v; want ValidationErrors", err)
+			}
+			keys := make([]string, len(errs))
+			for i, e := range errs {
+				keys[i] = e.Key
+			}
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("keys = %v; want %v", keys, tt.keys)
+			}
+		})
+	}
+}
+
+func TestNewPasswordServiceFromConfigDefaults(t *testing.T) {
+	var sent outbox
+	d := p.PasswordDependencies{
+		Comparator:           plain{},
+		Repository:           memory.NewPasswordRepository("userId", 5),
+		ResetCodeRepository:  memory.NewVerificationCodeRepository(),
+		ChangeCodeRepository: memory.NewVerificationCodeRepository(),
+		SendResetCode:        sent.send,
+	}
+	s, err := p.NewPasswordServiceFromConfig(p.PasswordConfig{ResetExpires: 600}, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.PasswordChangeExpires != 600 {
+		t.Errorf("PasswordChangeExpires = %d; want the reset expiry 600", s.PasswordChangeExpires)
+	}
+	if s.SendChangeCode == nil {
+		t.Error("SendChangeCode is nil; want SendResetCode")
+	}
+
+	s, err = p.NewPasswordServiceFromConfig(p.PasswordConfig{ResetExpires: 600, Exp1: `\d`, Policy: p.PolicyConfig{Preset: p.PresetLegacy}}, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.PasswordChangeExpires != 900 {
+		t.Errorf("PasswordChangeExpires = %d; want 900, the change TTL of the preset, since change_expires is not set", s.PasswordChangeExpires)
+	}
+	if s.DuplicateCount != 5 {
+		t.Errorf("DuplicateCount = %d; want 5 of the preset", s.DuplicateCount)
+	}
+	// the expressions of the config come first, then the ones of the policy
+	if len(s.Regexps) != 6 || s.Regexps[0].String() != `\d` {
+		t.Errorf("Regexps = %v; want the expression of the config and the 5 of the policy", s.Regexps)
+	}
+}
@@ -7,4 +7,5 @@ type PasswordChange struct {
 	CurrentPassword string `mapstructure:"current_password" json:"currentPassword,omitempty" gorm:"column:currentpassword" bson:"currentPassword,omitempty" dynamodbav:"currentPassword,omitempty" firestore:"currentPassword,omitempty"`
 	Password        string `mapstructure:"password" json:"password,omitempty" gorm:"column:password" bson:"password,omitempty" dynamodbav:"password,omitempty" firestore:"password,omitempty"`
 	Sender          string `mapstructure:"sender" json:"sender,omitempty" gorm:"column:sender" bson:"sender,omitempty" dynamodbav:"sender,omitempty" firestore:"sender,omitempty"`
+	Factor          string `mapstructure:"factor" json:"factor,omitempty" gorm:"column:factor" bson:"factor,omitempty" dynamodbav:"factor,omitempty" firestore:"factor,omitempty"`
 }
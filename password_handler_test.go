@@ -0,0 +1,24 @@
+package password_test
+
+import (
+	"net/http"
+	"testing"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/testkit"
+)
+
+func TestHandlerContract(t *testing.T) {
+	testkit.RunHandlerContract(t, testkit.HandlerContract{
+		New: func(t *testing.T, service p.PasswordService, options ...p.HandlerOption) testkit.Handle {
+			h := p.NewHandler(service, options...)
+			mux := http.NewServeMux()
+			mux.HandleFunc("/password/change", h.ChangePassword)
+			mux.HandleFunc("/password/forgot/", h.ForgotPassword)
+			mux.HandleFunc("/password/forgot", h.ForgotPassword)
+			mux.HandleFunc("/password/reset", h.ResetPassword)
+			mux.HandleFunc("/password/deny", h.DenyChange)
+			return testkit.ServeHTTP(mux)
+		},
+	})
+}
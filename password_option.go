@@ -0,0 +1,151 @@
+package password
+
+import (
+	"context"
+	"regexp"
+	"time"
+)
+
+// Option sets a concern of PasswordUseCase, for New.
+type Option func(*PasswordUseCase)
+
+// New returns the use case of the repository and the comparator, with the options.
+func New(repository PasswordRepository, comparator TextComparator, options ...Option) *PasswordUseCase {
+	s := &PasswordUseCase{PasswordRepository: repository, PasswordComparator: comparator, Regexps: make([]regexp.Regexp, 0)}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// WithReset sets the repository, the sender and the expiry in seconds of the reset codes.
+func WithReset(expires int, repository VerificationCodeRepository, send func(ctx context.Context, to string, code string, expireAt time.Time, params interface{}) error) Option {
+	return func(s *PasswordUseCase) {
+		s.PasswordResetExpires = expires
+		s.ResetPasscodeRepository = repository
+		s.SendResetCode = send
+	}
+}
+
+// WithChange sets the repository, the sender and the expiry in seconds of the codes of the second factor.
+func WithChange(expires int, repository VerificationCodeRepository, send func(ctx context.Context, to string, code string, expireAt time.Time, params interface{}) error) Option {
+	return func(s *PasswordUseCase) {
+		s.PasswordChangeExpires = expires
+		s.ChangePasscodeRepository = repository
+		s.SendChangeCode = send
+	}
+}
+
+func WithRequireTwoFactors(requireTwoFactors func(ctx context.Context, id string) (bool, error)) Option {
+	return func(s *PasswordUseCase) {
+		s.RequireTwoFactors = requireTwoFactors
+	}
+}
+
+func WithFactors(resolveFactors func(ctx context.Context, id string) ([]Factor, error), verifyFactor func(ctx context.Context, id string, factor Factor, code string) (bool, error)) Option {
+	return func(s *PasswordUseCase) {
+		s.ResolveFactors = resolveFactors
+		s.VerifyFactor = verifyFactor
+	}
+}
+
+func WithRevokeAllTokens(revokeAllTokens func(ctx context.Context, id string, reason string) error) Option {
+	return func(s *PasswordUseCase) {
+		s.RevokeAllTokens = revokeAllTokens
+	}
+}
+
+// WithExpressions sets the expressions of the policy; it panics if an expression cannot be compiled, like NewPasswordService.
+func WithExpressions(expressions ...string) Option {
+	return func(s *PasswordUseCase) {
+		regExps := make([]regexp.Regexp, 0)
+		for _, expression := range expressions {
+			if len(expression) > 0 {
+				regExp := regexp.MustCompile(expression)
+				regExps = append(regExps, *regExp)
+			}
+		}
+		s.Regexps = regExps
+	}
+}
+
+func WithDuplicateCount(duplicateCount int) Option {
+	return func(s *PasswordUseCase) {
+		s.DuplicateCount = duplicateCount
+	}
+}
+
+func WithBlocklist(blocklist Blocklist) Option {
+	return func(s *PasswordUseCase) {
+		s.Blocklist = blocklist
+	}
+}
+
+func WithPolicyResolver(resolver PolicyResolver, getPolicyUser func(ctx context.Context, id string) (*PolicyUser, error)) Option {
+	return func(s *PasswordUseCase) {
+		s.PolicyResolver = resolver
+		s.GetPolicyUser = getPolicyUser
+	}
+}
+
+func WithGenerate(generate func() string) Option {
+	return func(s *PasswordUseCase) {
+		s.Generate = generate
+	}
+}
+
+func WithDelivery(delivery Deliverer) Option {
+	return func(s *PasswordUseCase) {
+		s.Delivery = delivery
+	}
+}
+
+func WithRecoveryCodes(repository RecoveryCodeRepository) Option {
+	return func(s *PasswordUseCase) {
+		s.RecoveryCodeRepository = repository
+	}
+}
+
+func WithLocalizer(localizer Localizer, resolveLocale func(ctx context.Context, id string) (string, error)) Option {
+	return func(s *PasswordUseCase) {
+		s.Localizer = localizer
+		s.ResolveLocale = resolveLocale
+	}
+}
+
+// WithAudit sets the sink of the audit events, and the key of the actor in the context.
+func WithAudit(sink AuditSink, key string) Option {
+	return func(s *PasswordUseCase) {
+		s.AuditSink = sink
+		s.Key = key
+	}
+}
+
+// WithNotice sets the repository of the "this wasn't me" codes, their expiry in seconds and the link.
+func WithNotice(repository VerificationCodeRepository, expires int, link func(ctx context.Context, username string, code string) string) Option {
+	return func(s *PasswordUseCase) {
+		s.NoticeCodeRepository = repository
+		s.NoticeExpires = expires
+		s.NoticeLink = link
+	}
+}
+
+func WithLock(lock func(ctx context.Context, id string, reason string) error) Option {
+	return func(s *PasswordUseCase) {
+		s.Lock = lock
+	}
+}
+
+func WithRateLimiter(rateLimiter RateLimiter) Option {
+	return func(s *PasswordUseCase) {
+		s.RateLimiter = rateLimiter
+	}
+}
+
+// WithTenants sets the key of the tenant in the context, and the settings of the tenants.
+func WithTenants(key string, tenants map[string]*TenantConfig) Option {
+	return func(s *PasswordUseCase) {
+		s.TenantKey = key
+		s.Tenants = tenants
+	}
+}
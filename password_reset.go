@@ -4,4 +4,5 @@ type PasswordReset struct {
 	Username string `mapstructure:"username" json:"username,omitempty" gorm:"column:username" bson:"username,omitempty" dynamodbav:"username,omitempty" firestore:"username,omitempty"`
 	Passcode string `mapstructure:"passcode" json:"passcode,omitempty" gorm:"column:passcode" bson:"passcode,omitempty" dynamodbav:"passcode,omitempty" firestore:"passcode,omitempty"`
 	Password string `mapstructure:"password" json:"password,omitempty" gorm:"column:password" bson:"password,omitempty" dynamodbav:"password,omitempty" firestore:"password,omitempty"`
+	Factor   string `mapstructure:"factor" json:"factor,omitempty" gorm:"column:factor" bson:"factor,omitempty" dynamodbav:"factor,omitempty" firestore:"factor,omitempty"`
 }
@@ -0,0 +1,17 @@
+package password
+
+type PasswordResult struct {
+	Status      int32  `mapstructure:"status" json:"status" gorm:"column:status" bson:"status" dynamodbav:"status" firestore:"status"`
+	Code        string `mapstructure:"code" json:"code,omitempty" gorm:"column:code" bson:"code,omitempty" dynamodbav:"code,omitempty" firestore:"code,omitempty"`
+	Message     string `mapstructure:"message" json:"message,omitempty" gorm:"column:message" bson:"message,omitempty" dynamodbav:"message,omitempty" firestore:"message,omitempty"`
+	Factor      string `mapstructure:"factor" json:"factor,omitempty" gorm:"column:factor" bson:"factor,omitempty" dynamodbav:"factor,omitempty" firestore:"factor,omitempty"`
+	Destination string `mapstructure:"destination" json:"destination,omitempty" gorm:"column:destination" bson:"destination,omitempty" dynamodbav:"destination,omitempty" firestore:"destination,omitempty"`
+}
+
+// Response returns the bare status, as the legacy clients expect, unless the result describes a challenge or has a message.
+func (r PasswordResult) Response() interface{} {
+	if len(r.Factor) > 0 || len(r.Message) > 0 {
+		return r
+	}
+	return r.Status
+}
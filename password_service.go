@@ -1,9 +1,48 @@
 package password
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrDenyNotSupported is returned by ResultService when the service cannot deny the changes.
+var ErrDenyNotSupported = errors.New("the denial of the changes is not supported by the service")
 
 type PasswordService interface {
 	ForgotPassword(ctx context.Context, email string) (bool, error)
 	ResetPassword(ctx context.Context, pass PasswordReset) (int32, error)
 	ChangePassword(ctx context.Context, pass PasswordChange) (int32, error)
 }
+
+// PasswordResultService returns the results with the code, the message and the challenge, and denies the changes.
+type PasswordResultService interface {
+	ResetPasswordWithResult(ctx context.Context, pass PasswordReset) (PasswordResult, error)
+	ChangePasswordWithResult(ctx context.Context, pass PasswordChange) (PasswordResult, error)
+	DenyChange(ctx context.Context, deny PasswordDeny) (PasswordResult, error)
+}
+
+// ResultService returns the service if it is a PasswordResultService; otherwise, the results have the status of the service only, and DenyChange returns ErrDenyNotSupported.
+func ResultService(service PasswordService) PasswordResultService {
+	if s, ok := service.(PasswordResultService); ok {
+		return s
+	}
+	return statusService{service}
+}
+
+type statusService struct {
+	PasswordService
+}
+
+func (s statusService) ResetPasswordWithResult(ctx context.Context, pass PasswordReset) (PasswordResult, error) {
+	status, err := s.ResetPassword(ctx, pass)
+	return PasswordResult{Status: status}, err
+}
+
+func (s statusService) ChangePasswordWithResult(ctx context.Context, pass PasswordChange) (PasswordResult, error) {
+	status, err := s.ChangePassword(ctx, pass)
+	return PasswordResult{Status: status}, err
+}
+
+func (s statusService) DenyChange(ctx context.Context, deny PasswordDeny) (PasswordResult, error) {
+	return PasswordResult{Status: 0}, ErrDenyNotSupported
+}
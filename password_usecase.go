@@ -3,8 +3,10 @@ package password
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"regexp"
+	"strings"
 	"time"
 )
 
@@ -22,26 +24,57 @@ type PasswordUseCase struct {
 	ChangePasscodeRepository VerificationCodeRepository
 	SendChangeCode           func(ctx context.Context, to string, code string, expireAt time.Time, params interface{}) error
 	Generate                 func() string
+	// ResolveFactors returns the factors available to the user, in the order of preference.
+	// When it is set, it is used instead of RequireTwoFactors, and SendChangeCode receives the selected Factor as params.
+	ResolveFactors func(ctx context.Context, id string) ([]Factor, error)
+	// VerifyFactor verifies the code of a factor which does not send a code, such as TOTP.
+	VerifyFactor func(ctx context.Context, id string, factor Factor, code string) (bool, error)
+	// RecoveryCodeRepository keeps the hashed one-time recovery codes, which can be used instead of a reset code or a second factor.
+	RecoveryCodeRepository RecoveryCodeRepository
+	// Delivery sends the codes instead of SendResetCode and SendChangeCode, with fallback to the other channels of the user.
+	Delivery Deliverer
+	// Localizer localizes the messages of the results; when it is nil, the results have no message.
+	Localizer Localizer
+	// ResolveLocale returns the locale of the user record, which takes precedence over the locale of the request.
+	ResolveLocale func(ctx context.Context, id string) (string, error)
+	AuditSink     AuditSink
+	Key           string // User Id from context, as the actor of the audit events
+	// NoticeCodeRepository keeps the hashed codes of the "this wasn't me" links, which are sent by Delivery after the password is changed or reset.
+	NoticeCodeRepository VerificationCodeRepository
+	NoticeExpires        int // in seconds, 7 days by default
+	// NoticeLink returns the "this wasn't me" link, to the page which posts the username and the code to DenyChange.
+	NoticeLink func(ctx context.Context, username string, code string) string
+	// Lock locks the account when the user denies the change; the application should unlock it when the password is reset.
+	Lock func(ctx context.Context, id string, reason string) error
+	// RateLimiter limits ForgotPassword by the contact; ForgotPassword returns ErrTooManyRequests when it is not allowed.
+	RateLimiter RateLimiter
+	TenantKey   string // Tenant Id from context
+	// Tenants holds the settings of the tenants, by the tenant id; the other tenants use the settings of PasswordUseCase.
+	Tenants map[string]*TenantConfig
+	// PolicyResolver picks the policy of the user, such as by the role, the group or the tenant; the policy overrides Regexps and DuplicateCount.
+	PolicyResolver PolicyResolver
+	// GetPolicyUser loads the user record for PolicyResolver; when it is nil, the user has the id, the username and the tenant of the context only.
+	GetPolicyUser func(ctx context.Context, id string) (*PolicyUser, error)
+	// Blocklist rejects the common or compromised passwords, after Regexps.
+	Blocklist Blocklist
 }
 
 func NewPasswordService(passwordComparator TextComparator, passwordRepossitory PasswordRepository, passwordResetExpires int, resetPasscodeService VerificationCodeRepository, sendResetCode func(context.Context, string, string, time.Time, interface{}) error, removeAllTokens func(context.Context, string, string) error, expressions []string, duplicateCount int, requireTwoFactors func(ctx context.Context, id string) (bool, error), passwordChangeExpires int, changePasscodeService VerificationCodeRepository, sendChangeCode func(context.Context, string, string, time.Time, interface{}) error, options ...func() string) *PasswordUseCase {
 	if requireTwoFactors != nil && (changePasscodeService == nil || sendChangeCode == nil || passwordChangeExpires <= 0) {
 		panic(errors.New("when requireTwoFactors is not nil, changePasscodeService and sendChangeCode must not be nil, and passwordChangeExpires must be greater than 0"))
 	}
-	regExps := make([]regexp.Regexp, 0)
-	if len(expressions) > 0 {
-		for _, expression := range expressions {
-			if len(expression) > 0 {
-				regExp := regexp.MustCompile(expression)
-				regExps = append(regExps, *regExp)
-			}
-		}
-	}
 	var generate func() string
 	if len(options) >= 1 {
 		generate = options[0]
 	}
-	return &PasswordUseCase{passwordComparator, passwordRepossitory, passwordResetExpires, resetPasscodeService, sendResetCode, removeAllTokens, regExps, duplicateCount, requireTwoFactors, passwordChangeExpires, changePasscodeService, sendChangeCode, generate}
+	return New(passwordRepossitory, passwordComparator,
+		WithReset(passwordResetExpires, resetPasscodeService, sendResetCode),
+		WithRevokeAllTokens(removeAllTokens),
+		WithExpressions(expressions...),
+		WithDuplicateCount(duplicateCount),
+		WithRequireTwoFactors(requireTwoFactors),
+		WithChange(passwordChangeExpires, changePasscodeService, sendChangeCode),
+		WithGenerate(generate))
 }
 
 func NewDefaultPasswordService(passwordComparator TextComparator, passwordRepossitory PasswordRepository, passwordResetExpires int, resetPasscodeService VerificationCodeRepository, sendCode func(context.Context, string, string, time.Time, interface{}) error, removeAllTokens func(context.Context, string, string) error, expressions []string, duplicateCount int, requireTwoFactors func(ctx context.Context, id string) (bool, error)) *PasswordUseCase {
@@ -49,44 +82,93 @@ func NewDefaultPasswordService(passwordComparator TextComparator, passwordReposs
 }
 
 func (s PasswordUseCase) ChangePassword(ctx context.Context, passwordChange PasswordChange) (int32, error) {
-	if len(s.Regexps) > 0 {
-		for _, exp := range s.Regexps {
-			if !exp.MatchString(passwordChange.Password) {
-				return -2, nil
-			}
+	result, err := s.ChangePasswordWithResult(ctx, passwordChange)
+	return result.Status, err
+}
+
+func (s PasswordUseCase) ChangePasswordWithResult(ctx context.Context, passwordChange PasswordChange) (PasswordResult, error) {
+	s = s.tenant(ctx)
+	if s.PolicyResolver == nil {
+		if result, ok := s.checkPolicy(ctx, "", passwordChange.Password); !ok {
+			return result, nil
 		}
 	}
 	if passwordChange.Step > 0 && len(passwordChange.Passcode) == 0 {
-		return 0, nil
+		return s.result(ctx, 0, MessageInvalid), nil
 	}
 
 	userId, username, email, password, er0 := s.PasswordRepository.GetUser(ctx, passwordChange.Username)
-	if er0 != nil || len(userId) == 0 {
-		return 0, er0
+	if er0 != nil {
+		return s.result(ctx, 0, MessageInvalid), er0
+	}
+	// a missing user is forbidden too, so that the authenticated users cannot find the usernames of the other users
+	if !owns(ctx, userId) {
+		if len(userId) > 0 {
+			s.audit(ctx, EventChangeFailed, userId, "forbidden")
+		}
+		return s.result(ctx, 0, MessageInvalid), ErrForbidden
+	}
+	if len(userId) == 0 {
+		return s.result(ctx, 0, MessageInvalid), nil
+	}
+	ctx = s.withLocale(ctx, userId)
+	if s.PolicyResolver != nil {
+		var er1 error
+		if s, er1 = s.policy(ctx, userId, username); er1 != nil {
+			return PasswordResult{Status: 0}, er1
+		}
+		if result, ok := s.checkPolicy(ctx, userId, passwordChange.Password); !ok {
+			return result, nil
+		}
 	}
 	validPassword, er2 := s.PasswordComparator.Compare(passwordChange.CurrentPassword, password)
 	if !validPassword || er2 != nil {
-		return 0, er2
+		if er2 == nil {
+			s.audit(ctx, EventChangeFailed, userId, MessageInvalid)
+		}
+		return s.result(ctx, 0, MessageInvalid), er2
 	}
 
 	if s.DuplicateCount > 0 {
 		histories, er3 := s.PasswordRepository.GetHistory(ctx, userId, s.DuplicateCount-1)
 		if er3 != nil {
-			return 0, er3
+			return PasswordResult{Status: 0}, er3
 		}
 		duplicate, er4 := duplicate(ctx, s.PasswordComparator, passwordChange.Password, password, histories, s.DuplicateCount)
 		if er4 != nil {
-			return 0, er4
+			return PasswordResult{Status: 0}, er4
 		}
 		if duplicate {
-			return -1, nil
+			s.audit(ctx, EventPolicyRejected, userId, MessageDuplicate)
+			return s.result(ctx, -1, MessageDuplicate), nil
 		}
 	}
 
-	if s.RequireTwoFactors != nil {
+	if s.ResolveFactors != nil {
+		factors, er4 := s.ResolveFactors(ctx, userId)
+		if er4 != nil {
+			return PasswordResult{Status: 0}, er4
+		}
+		if len(factors) > 0 {
+			factor, ok := selectFactor(factors, passwordChange.Factor)
+			if !ok {
+				return s.result(ctx, 0, MessageInvalid), nil
+			}
+			if passwordChange.Step <= 0 {
+				return s.challenge(ctx, userId, username, factor)
+			}
+			failure, er5 := s.verifyFactor(ctx, userId, factor, passwordChange.Passcode)
+			if len(failure) > 0 || er5 != nil {
+				if er5 == nil {
+					s.audit(ctx, EventChangeFailed, userId, failure)
+				}
+				return s.result(ctx, 0, failure), er5
+			}
+		}
+	} else if s.RequireTwoFactors != nil {
 		required, er4 := s.RequireTwoFactors(ctx, userId)
 		if er4 != nil {
-			return 0, er4
+			return PasswordResult{Status: 0}, er4
 		}
 		if required {
 			if passwordChange.Step <= 0 {
@@ -99,46 +181,185 @@ func (s PasswordUseCase) ChangePassword(ctx context.Context, passwordChange Pass
 
 				codeSave, er5 := s.PasswordComparator.Hash(codeSend)
 				if er5 != nil {
-					return 0, er5
+					return PasswordResult{Status: 0}, er5
 				}
 				expiredAt := addSeconds(time.Now(), s.PasswordChangeExpires)
 				count, er6 := s.ChangePasscodeRepository.Save(ctx, userId, codeSave, expiredAt)
 				if count > 0 && er6 == nil {
-					er7 := s.SendChangeCode(ctx, username, codeSend, expiredAt, email)
-					return 2, er7
+					message := DeliveryMessage{UserId: userId, Username: username, Destination: email, Code: codeSend, ExpireAt: expiredAt, Locale: GetLocale(ctx), Purpose: PurposeChange}
+					_, er7 := s.deliver(ctx, message, s.SendChangeCode, email)
+					if er7 == nil {
+						s.audit(ctx, EventChangeChallenged, userId, ChannelEmail)
+					}
+					return s.result(ctx, 2, MessageChallenged), er7
 				}
 			}
-			code, expiredAt, er8 := s.ChangePasscodeRepository.Load(ctx, userId)
-			if er8 != nil || len(code) == 0 {
-				return 0, er8
-			}
-			if compareDate(expiredAt, time.Now()) < 0 {
-				deleteCode(ctx, s.ChangePasscodeRepository, userId)
-				return 0, nil
-			}
-			valid, er9 := s.PasswordComparator.Compare(passwordChange.Passcode, code)
-			if er9 == nil {
-				deleteCode(ctx, s.ChangePasscodeRepository, userId)
-			}
-			if !valid || er9 != nil {
-				return 0, er9
+			failure, er8 := s.verifyChangeCode(ctx, userId, passwordChange.Passcode)
+			if len(failure) > 0 || er8 != nil {
+				if er8 == nil {
+					s.audit(ctx, EventChangeFailed, userId, failure)
+				}
+				return s.result(ctx, 0, failure), er8
 			}
 		}
 	}
 
 	newPassword, er6 := s.PasswordComparator.Hash(passwordChange.Password)
 	if er6 != nil {
-		return 0, er6
+		return PasswordResult{Status: 0}, er6
 	}
-	count, er7 := s.PasswordRepository.UpdateWithCurrentPassword(ctx, userId, password, newPassword)
+	count, er7 := s.PasswordRepository.UpdateWithCurrentPassword(WithEventType(ctx, EventChangeCompleted), userId, password, newPassword)
 	if count > 0 && er7 == nil {
+		s.audit(ctx, EventChangeCompleted, userId, "")
+		s.notify(ctx, userId, username, email)
 		if s.RevokeAllTokens != nil {
 			er8 := s.RevokeAllTokens(ctx, userId, "The user has changed password.")
-			return 1, er8
+			return s.result(ctx, 1, MessageChanged), er8
+		}
+		return s.result(ctx, 1, MessageChanged), er7
+	}
+	return PasswordResult{Status: 0}, er7
+}
+
+func (s PasswordUseCase) challenge(ctx context.Context, userId string, username string, factor Factor) (PasswordResult, error) {
+	result := s.result(ctx, 2, MessageChallenged)
+	result.Factor = factor.Type
+	result.Destination = Mask(factor.Type, factor.Destination)
+	if !factor.SendsCode() {
+		if s.ChangePasscodeRepository != nil {
+			count, er0 := s.ChangePasscodeRepository.Save(ctx, userId, factorCode(factor.Type, ""), addSeconds(time.Now(), s.PasswordChangeExpires))
+			if count <= 0 || er0 != nil {
+				return PasswordResult{Status: 0}, er0
+			}
 		}
-		return 1, er7
+		s.audit(ctx, EventChangeChallenged, userId, factor.Type)
+		return result, nil
+	}
+	var codeSend string
+	if s.Generate != nil {
+		codeSend = s.Generate()
+	} else {
+		codeSend = generate(6)
 	}
-	return 0, er7
+	codeSave, er1 := s.PasswordComparator.Hash(codeSend)
+	if er1 != nil {
+		return PasswordResult{Status: 0}, er1
+	}
+	expiredAt := addSeconds(time.Now(), s.PasswordChangeExpires)
+	count, er2 := s.ChangePasscodeRepository.Save(ctx, userId, factorCode(factor.Type, codeSave), expiredAt)
+	if count <= 0 || er2 != nil {
+		return PasswordResult{Status: 0}, er2
+	}
+	// the code is sent by the selected factor only: a fallback to another channel would replace the factor of the user, and the code would not match it at the second step
+	message := DeliveryMessage{UserId: userId, Username: username, Channel: factor.Type, Destination: factor.Destination, Code: codeSend, ExpireAt: expiredAt, Locale: GetLocale(ctx), Purpose: PurposeChange}
+	sent, er3 := s.deliver(WithStrictChannel(ctx), message, s.SendChangeCode, factor)
+	if er3 == nil && sent.Channel != factor.Type {
+		er3 = fmt.Errorf("the %s code of user %s was delivered by %s instead of %s", PurposeChange, userId, sent.Channel, factor.Type)
+	}
+	if er3 != nil {
+		deleteCode(ctx, s.ChangePasscodeRepository, userId)
+		return PasswordResult{Status: 0}, er3
+	}
+	result.Destination = Mask(sent.Channel, sent.Destination)
+	s.audit(ctx, EventChangeChallenged, userId, sent.Channel)
+	return result, nil
+}
+
+func (s PasswordUseCase) deliver(ctx context.Context, message DeliveryMessage, send func(context.Context, string, string, time.Time, interface{}) error, params interface{}) (DeliveryMessage, error) {
+	if s.Delivery != nil {
+		return s.Delivery.Deliver(ctx, message)
+	}
+	return message, send(ctx, message.Username, message.Code, message.ExpireAt, params)
+}
+
+// verifyFactor returns the key of the message of the failure, or an empty string if the code is valid.
+// The factor must be the one selected at the first step, which is saved with the code.
+func (s PasswordUseCase) verifyFactor(ctx context.Context, userId string, factor Factor, code string) (string, error) {
+	if s.ChangePasscodeRepository != nil {
+		saved, expiredAt, er0 := s.ChangePasscodeRepository.Load(ctx, userId)
+		if er0 != nil || len(saved) == 0 {
+			return MessageInvalid, er0
+		}
+		factorType, hash := splitFactorCode(saved)
+		if factorType != factor.Type {
+			deleteCode(ctx, s.ChangePasscodeRepository, userId)
+			return MessageInvalid, nil
+		}
+		if factor.SendsCode() {
+			return s.checkChangeCode(ctx, userId, code, saved, hash, expiredAt)
+		}
+		deleteCode(ctx, s.ChangePasscodeRepository, userId)
+		if compareDate(expiredAt, time.Now()) < 0 {
+			return MessageExpired, nil
+		}
+	} else if factor.SendsCode() {
+		return MessageInvalid, nil
+	}
+	var valid bool
+	var err error
+	checkingCode(ctx)
+	if factor.Type == FactorRecovery && s.RecoveryCodeRepository != nil {
+		valid, err = useRecoveryCode(ctx, s.PasswordComparator, s.RecoveryCodeRepository, userId, code)
+	} else if s.VerifyFactor != nil {
+		valid, err = s.VerifyFactor(ctx, userId, factor, code)
+	}
+	if !valid || err != nil {
+		return MessageInvalid, err
+	}
+	return "", nil
+}
+
+func (s PasswordUseCase) verifyChangeCode(ctx context.Context, userId string, passcode string) (string, error) {
+	code, expiredAt, er1 := s.ChangePasscodeRepository.Load(ctx, userId)
+	if er1 != nil || len(code) == 0 {
+		return MessageInvalid, er1
+	}
+	return s.checkChangeCode(ctx, userId, passcode, code, code, expiredAt)
+}
+
+// checkChangeCode compares the passcode with the hash of the saved code, and consumes the saved code if they match.
+func (s PasswordUseCase) checkChangeCode(ctx context.Context, userId string, passcode string, saved string, hash string, expiredAt time.Time) (string, error) {
+	checkingCode(ctx)
+	if compareDate(expiredAt, time.Now()) < 0 {
+		deleteCode(ctx, s.ChangePasscodeRepository, userId)
+		return MessageExpired, nil
+	}
+	valid, er2 := s.PasswordComparator.Compare(passcode, hash)
+	if er2 == nil {
+		if valid {
+			valid, er2 = consumeCode(ctx, s.ChangePasscodeRepository, userId, saved)
+		} else {
+			deleteCode(ctx, s.ChangePasscodeRepository, userId)
+		}
+	}
+	if !valid || er2 != nil {
+		return MessageInvalid, er2
+	}
+	return "", nil
+}
+
+func (s PasswordUseCase) withLocale(ctx context.Context, userId string) context.Context {
+	if s.ResolveLocale == nil {
+		return ctx
+	}
+	locale, err := s.ResolveLocale(ctx, userId)
+	if err != nil {
+		log.Println(err)
+		return ctx
+	}
+	return WithLocale(ctx, locale)
+}
+
+func (s PasswordUseCase) result(ctx context.Context, status int32, code string) PasswordResult {
+	result := PasswordResult{Status: status, Code: code}
+	if s.Localizer != nil && len(code) > 0 {
+		locale := GetLocale(ctx)
+		result.Message = s.Localizer.Localize(locale, code)
+		if len(result.Message) == 0 && status == -2 {
+			result.Message = s.Localizer.Localize(locale, MessagePolicy)
+		}
+	}
+	return result
 }
 
 func duplicate(ctx context.Context, comparator TextComparator, newPassword, currentPassword string, histories []string, count int) (bool, error) {
@@ -164,11 +385,25 @@ func min(n1, n2 int) int {
 }
 
 func (s PasswordUseCase) ForgotPassword(ctx context.Context, emailTo string) (bool, error) {
+	s = s.tenant(ctx)
+	if s.RateLimiter != nil {
+		allowed, er0 := s.RateLimiter.Allow(ctx, "forgot:"+s.tenantKey(ctx, strings.ToLower(emailTo)))
+		if er0 != nil {
+			return false, er0
+		}
+		if !allowed {
+			return false, ErrTooManyRequests
+		}
+	}
 	userId, username, email, _, er1 := s.PasswordRepository.GetUser(ctx, emailTo)
 	if len(userId) == 0 || er1 != nil {
 		return false, er1
 	}
+	ctx = s.withLocale(ctx, userId)
+	return s.sendResetCode(ctx, userId, username, email)
+}
 
+func (s PasswordUseCase) sendResetCode(ctx context.Context, userId string, username string, email string) (bool, error) {
 	var codeSend string
 	if s.Generate != nil {
 		codeSend = s.Generate()
@@ -183,83 +418,147 @@ func (s PasswordUseCase) ForgotPassword(ctx context.Context, emailTo string) (bo
 	expiredAt := addSeconds(time.Now(), s.PasswordResetExpires)
 	count, er1 := s.ResetPasscodeRepository.Save(ctx, userId, codeSave, expiredAt)
 	if count > 0 && er1 == nil {
-		expiredAt := addSeconds(time.Now(), s.PasswordResetExpires)
-		er2 := s.SendResetCode(ctx, username, codeSend, expiredAt, email)
+		message := DeliveryMessage{UserId: userId, Username: username, Destination: email, Code: codeSend, ExpireAt: expiredAt, Locale: GetLocale(ctx), Purpose: PurposeReset}
+		sent, er2 := s.deliver(ctx, message, s.SendResetCode, email)
 		if er2 != nil {
 			return false, er2
 		}
+		s.audit(ctx, EventResetRequested, userId, sent.Channel)
 		return true, nil
 	}
 	return false, er1
 }
 
 func (s PasswordUseCase) ResetPassword(ctx context.Context, passwordReset PasswordReset) (int32, error) {
-	if len(s.Regexps) > 0 {
-		for _, exp := range s.Regexps {
-			if !exp.MatchString(passwordReset.Password) {
-				return -2, nil
-			}
+	result, err := s.ResetPasswordWithResult(ctx, passwordReset)
+	return result.Status, err
+}
+
+func (s PasswordUseCase) ResetPasswordWithResult(ctx context.Context, passwordReset PasswordReset) (PasswordResult, error) {
+	s = s.tenant(ctx)
+	if s.PolicyResolver == nil {
+		if result, ok := s.checkPolicy(ctx, "", passwordReset.Password); !ok {
+			return result, nil
 		}
 	}
-	var userId, password string
+	var userId, username, email, password string
 	var er0 error
 	if s.DuplicateCount <= 0 {
 		userId, er0 = s.PasswordRepository.GetUserId(ctx, passwordReset.Username)
 	} else {
-		userId, _, _, password, er0 = s.PasswordRepository.GetUser(ctx, passwordReset.Username)
+		userId, username, email, password, er0 = s.PasswordRepository.GetUser(ctx, passwordReset.Username)
 	}
 	if len(userId) == 0 || er0 != nil {
-		return 0, er0
+		return s.result(ctx, 0, MessageInvalid), er0
 	}
-
-	passcode, expiredAt, er2 := s.ResetPasscodeRepository.Load(ctx, userId)
-	if er2 != nil {
-		return 0, er2
+	ctx = s.withLocale(ctx, userId)
+	if s.PolicyResolver != nil {
+		var er1 error
+		if s, er1 = s.policy(ctx, userId, passwordReset.Username); er1 != nil {
+			return PasswordResult{Status: 0}, er1
+		}
+		if result, ok := s.checkPolicy(ctx, userId, passwordReset.Password); !ok {
+			return result, nil
+		}
 	}
-	if compareDate(expiredAt, time.Now()) < 0 {
-		deleteCode(ctx, s.ResetPasscodeRepository, userId)
-		return 0, nil
+
+	var valid bool
+	var er3 error
+	var recoveryCode, passcode string
+	recovery := passwordReset.Factor == FactorRecovery
+	if recovery {
+		if s.RecoveryCodeRepository == nil {
+			return s.result(ctx, 0, MessageInvalid), nil
+		}
+		checkingCode(ctx)
+		recoveryCode, er3 = findRecoveryCode(ctx, s.PasswordComparator, s.RecoveryCodeRepository, userId, passwordReset.Passcode)
+		valid = len(recoveryCode) > 0
+	} else {
+		var expiredAt time.Time
+		var er2 error
+		passcode, expiredAt, er2 = s.ResetPasscodeRepository.Load(ctx, userId)
+		if er2 != nil {
+			return PasswordResult{Status: 0}, er2
+		}
+		if len(passcode) > 0 {
+			checkingCode(ctx)
+		}
+		if compareDate(expiredAt, time.Now()) < 0 {
+			deleteCode(ctx, s.ResetPasscodeRepository, userId)
+			s.audit(ctx, EventResetCodeFailed, userId, MessageExpired)
+			return s.result(ctx, 0, MessageExpired), nil
+		}
+		valid, er3 = s.PasswordComparator.Compare(passwordReset.Passcode, passcode)
 	}
-	valid, er3 := s.PasswordComparator.Compare(passwordReset.Passcode, passcode)
 	if s.DuplicateCount > 0 && valid && er3 == nil {
 		histories, er3 := s.PasswordRepository.GetHistory(ctx, userId, s.DuplicateCount-1)
 		if er3 != nil {
-			return 0, er3
+			return PasswordResult{Status: 0}, er3
 		}
 		duplicate, er4 := duplicate(ctx, s.PasswordComparator, passwordReset.Password, password, histories, s.DuplicateCount)
 		if er4 != nil {
-			return 0, er4
+			return PasswordResult{Status: 0}, er4
 		}
 		if duplicate {
-			return -1, nil
+			s.audit(ctx, EventPolicyRejected, userId, MessageDuplicate)
+			return s.result(ctx, -1, MessageDuplicate), nil
 		}
 	}
 
-	if er3 == nil {
-		deleteCode(ctx, s.ResetPasscodeRepository, userId)
+	if er3 == nil && !recovery {
+		if valid {
+			valid, er3 = consumeCode(ctx, s.ResetPasscodeRepository, userId, passcode)
+		} else {
+			deleteCode(ctx, s.ResetPasscodeRepository, userId)
+		}
 	}
 	if !valid || er3 != nil {
-		return 0, er3
+		if er3 == nil {
+			s.audit(ctx, EventResetCodeFailed, userId, MessageInvalid)
+		}
+		return s.result(ctx, 0, MessageInvalid), er3
+	}
+	if recovery {
+		used, er5 := s.RecoveryCodeRepository.Delete(ctx, userId, recoveryCode)
+		if used <= 0 || er5 != nil {
+			if er5 == nil {
+				s.audit(ctx, EventResetCodeFailed, userId, MessageInvalid)
+			}
+			return s.result(ctx, 0, MessageInvalid), er5
+		}
 	}
 	newPassword, er4 := s.PasswordComparator.Hash(passwordReset.Password)
 	if er4 != nil {
-		return 0, er4
+		return PasswordResult{Status: 0}, er4
 	}
 	var count int64
+	updateCtx := WithEventType(ctx, EventResetCompleted)
 	if s.DuplicateCount <= 0 {
-		count, er0 = s.PasswordRepository.Update(ctx, userId, newPassword)
+		count, er0 = s.PasswordRepository.Update(updateCtx, userId, newPassword)
 	} else {
-		count, er0 = s.PasswordRepository.UpdateWithCurrentPassword(ctx, userId, password, newPassword)
+		count, er0 = s.PasswordRepository.UpdateWithCurrentPassword(updateCtx, userId, password, newPassword)
 	}
 
 	if count > 0 && er0 == nil {
+		s.audit(ctx, EventResetCompleted, userId, passwordReset.Factor)
+		if len(email) == 0 && s.noticing() {
+			if _, name, address, _, er5 := s.PasswordRepository.GetUser(ctx, passwordReset.Username); er5 != nil {
+				log.Println(er5)
+			} else {
+				username, email = name, address
+			}
+		}
+		if len(username) == 0 {
+			username = passwordReset.Username
+		}
+		s.notify(ctx, userId, username, email)
 		if s.RevokeAllTokens != nil {
 			er6 := s.RevokeAllTokens(ctx, userId, "The user has reset password.")
-			return 1, er6
+			return s.result(ctx, 1, MessageReset), er6
 		}
-		return 1, er0
+		return s.result(ctx, 1, MessageReset), er0
 	}
-	return 0, er0
+	return PasswordResult{Status: 0}, er0
 }
 
 func deleteCode(ctx context.Context, codeService VerificationCodeRepository, id string) {
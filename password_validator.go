@@ -0,0 +1,37 @@
+package password
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrValidationNotSupported is returned by the decorators of PasswordService when the service is not a PasswordValidator.
+var ErrValidationNotSupported = errors.New("password validation is not supported by the service")
+
+// PasswordValidator checks a new password against the policy of the user, without changing it, such as for the form of the new password.
+type PasswordValidator interface {
+	ValidatePassword(ctx context.Context, username string, password string) (PasswordResult, error)
+}
+
+// ValidatePassword returns the status 1 if the password meets the policy of the user, or -2 and the code of the first violation.
+// The policy of PolicyResolver is used when the user exists; the other users have the policy of the tenant.
+func (s PasswordUseCase) ValidatePassword(ctx context.Context, username string, password string) (PasswordResult, error) {
+	s = s.tenant(ctx)
+	if s.PolicyResolver != nil && len(username) > 0 {
+		userId, name, _, _, er0 := s.PasswordRepository.GetUser(ctx, username)
+		if er0 != nil {
+			return PasswordResult{Status: 0}, er0
+		}
+		if len(userId) > 0 {
+			ctx = s.withLocale(ctx, userId)
+			var er1 error
+			if s, er1 = s.policy(ctx, userId, name); er1 != nil {
+				return PasswordResult{Status: 0}, er1
+			}
+		}
+	}
+	if code := s.violation(password); len(code) > 0 {
+		return s.result(ctx, -2, code), nil
+	}
+	return PasswordResult{Status: 1}, nil
+}
@@ -0,0 +1,224 @@
+package password
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Policy is a password policy, which applies to the users of the tenant, the role and the group; an empty criterion matches all the users.
+// When several policies match, the one with the highest priority is used.
+type Policy struct {
+	Id             string   `mapstructure:"id" json:"id,omitempty" gorm:"column:id;primary_key" bson:"_id,omitempty" dynamodbav:"id,omitempty" firestore:"id,omitempty"`
+	Tenant         string   `mapstructure:"tenant" json:"tenant,omitempty" gorm:"column:tenant" bson:"tenant,omitempty" dynamodbav:"tenant,omitempty" firestore:"tenant,omitempty"`
+	Role           string   `mapstructure:"role" json:"role,omitempty" gorm:"column:role" bson:"role,omitempty" dynamodbav:"role,omitempty" firestore:"role,omitempty"`
+	Group          string   `mapstructure:"group" json:"group,omitempty" gorm:"column:groupname" bson:"group,omitempty" dynamodbav:"group,omitempty" firestore:"group,omitempty"`
+	Priority       int      `mapstructure:"priority" json:"priority,omitempty" gorm:"column:priority" bson:"priority,omitempty" dynamodbav:"priority,omitempty" firestore:"priority,omitempty"`
+	Expressions    []string `mapstructure:"expressions" json:"expressions,omitempty" gorm:"column:expressions" bson:"expressions,omitempty" dynamodbav:"expressions,omitempty" firestore:"expressions,omitempty"`
+	DuplicateCount int      `mapstructure:"duplicate_count" json:"duplicateCount,omitempty" gorm:"column:duplicatecount" bson:"duplicateCount,omitempty" dynamodbav:"duplicateCount,omitempty" firestore:"duplicateCount,omitempty"`
+	regexps        []regexp.Regexp
+}
+
+// Compile compiles the expressions of the policy; it is called by PolicySelector when the policies are loaded.
+func (p *Policy) Compile() error {
+	regexps := make([]regexp.Regexp, 0)
+	for _, expression := range p.Expressions {
+		if len(expression) > 0 {
+			regExp, err := regexp.Compile(expression)
+			if err != nil {
+				return err
+			}
+			regexps = append(regexps, *regExp)
+		}
+	}
+	p.regexps = regexps
+	return nil
+}
+
+// Matches returns true if the policy applies to the user.
+func (p Policy) Matches(user PolicyUser) bool {
+	if len(p.Tenant) > 0 && p.Tenant != user.Tenant {
+		return false
+	}
+	if len(p.Role) > 0 && !contains(user.Roles, p.Role) {
+		return false
+	}
+	if len(p.Group) > 0 && !contains(user.Groups, p.Group) {
+		return false
+	}
+	return true
+}
+
+// PolicyUser is the user record which the policy is resolved for.
+type PolicyUser struct {
+	Id       string   `mapstructure:"id" json:"id,omitempty" gorm:"column:id;primary_key" bson:"_id,omitempty" dynamodbav:"id,omitempty" firestore:"id,omitempty"`
+	Username string   `mapstructure:"username" json:"username,omitempty" gorm:"column:username" bson:"username,omitempty" dynamodbav:"username,omitempty" firestore:"username,omitempty"`
+	Tenant   string   `mapstructure:"tenant" json:"tenant,omitempty" gorm:"column:tenant" bson:"tenant,omitempty" dynamodbav:"tenant,omitempty" firestore:"tenant,omitempty"`
+	Roles    []string `mapstructure:"roles" json:"roles,omitempty" gorm:"column:roles" bson:"roles,omitempty" dynamodbav:"roles,omitempty" firestore:"roles,omitempty"`
+	Groups   []string `mapstructure:"groups" json:"groups,omitempty" gorm:"column:groups" bson:"groups,omitempty" dynamodbav:"groups,omitempty" firestore:"groups,omitempty"`
+}
+
+// PolicyResolver returns the policy of the user, or nil to use Regexps and DuplicateCount of PasswordUseCase.
+// PasswordUseCase knows the roles and the groups of the user only from GetPolicyUser: without it, the user has the id, the username and the tenant only,
+// so the policies by role or by group never match, and only the policies by tenant and the default ones apply.
+type PolicyResolver interface {
+	Resolve(ctx context.Context, user PolicyUser) (*Policy, error)
+}
+
+// PolicyStore loads all the policies, such as from a table or a file.
+type PolicyStore interface {
+	Load(ctx context.Context) ([]Policy, error)
+}
+
+// PolicySelector resolves the policy of the user from the policies of the store, which are cached for Expires, or forever if Expires is 0.
+// A policy whose expressions do not compile is skipped, and the policies which were loaded before are kept when the store fails; both are reported to Error.
+type PolicySelector struct {
+	Store    PolicyStore
+	Expires  time.Duration
+	Error    func(context.Context, string, ...map[string]interface{})
+	mutex    sync.RWMutex
+	policies []Policy
+	loadedAt time.Time
+}
+
+func NewPolicySelector(store PolicyStore, options ...time.Duration) *PolicySelector {
+	expires := 5 * time.Minute
+	if len(options) > 0 {
+		expires = options[0]
+	}
+	return &PolicySelector{Store: store, Expires: expires}
+}
+
+func (s *PolicySelector) Resolve(ctx context.Context, user PolicyUser) (*Policy, error) {
+	policies, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var policy *Policy
+	for i := range policies {
+		if policies[i].Matches(user) && (policy == nil || policies[i].Priority > policy.Priority) {
+			policy = &policies[i]
+		}
+	}
+	return policy, nil
+}
+
+// Refresh removes the cached policies, so that they are loaded again by the next Resolve.
+func (s *PolicySelector) Refresh() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.policies = nil
+}
+
+func (s *PolicySelector) load(ctx context.Context) ([]Policy, error) {
+	s.mutex.RLock()
+	policies, loadedAt := s.policies, s.loadedAt
+	s.mutex.RUnlock()
+	if policies != nil && (s.Expires <= 0 || time.Since(loadedAt) < s.Expires) {
+		return policies, nil
+	}
+	loaded, err := s.Store.Load(ctx)
+	if err != nil {
+		if policies != nil {
+			s.report(ctx, "cannot load the policies, the policies which were loaded before are kept: "+err.Error(), nil)
+			return policies, nil
+		}
+		return nil, err
+	}
+	policies = make([]Policy, 0, len(loaded))
+	for i := range loaded {
+		if err := loaded[i].Compile(); err != nil {
+			s.report(ctx, "the policy is skipped, because its expressions cannot be compiled: "+err.Error(), map[string]interface{}{"policy": loaded[i].Id})
+			continue
+		}
+		policies = append(policies, loaded[i])
+	}
+	s.mutex.Lock()
+	s.policies, s.loadedAt = policies, time.Now()
+	s.mutex.Unlock()
+	return policies, nil
+}
+
+func (s *PolicySelector) report(ctx context.Context, msg string, fields map[string]interface{}) {
+	if s.Error == nil {
+		return
+	}
+	if fields == nil {
+		s.Error(ctx, msg)
+	} else {
+		s.Error(ctx, msg, fields)
+	}
+}
+
+// policy returns the use case with Regexps and DuplicateCount of the policy of the user, if any.
+func (s PasswordUseCase) policy(ctx context.Context, userId string, username string) (PasswordUseCase, error) {
+	user := PolicyUser{Id: userId, Username: username}
+	if s.GetPolicyUser != nil {
+		u, err := s.GetPolicyUser(ctx, userId)
+		if err != nil {
+			return s, err
+		}
+		if u != nil {
+			user = *u
+			if len(user.Id) == 0 {
+				user.Id = userId
+			}
+			if len(user.Username) == 0 {
+				user.Username = username
+			}
+		}
+	}
+	if len(user.Tenant) == 0 {
+		user.Tenant = getString(ctx, s.TenantKey)
+	}
+	policy, err := s.PolicyResolver.Resolve(ctx, user)
+	if err != nil || policy == nil {
+		return s, err
+	}
+	if policy.Expressions != nil {
+		if policy.regexps == nil {
+			p := *policy
+			if err := p.Compile(); err != nil {
+				return s, err
+			}
+			policy = &p
+		}
+		s.Regexps = policy.regexps
+	}
+	if policy.DuplicateCount > 0 {
+		s.DuplicateCount = policy.DuplicateCount
+	}
+	return s, nil
+}
+
+// checkPolicy returns false and the result of the first expression which the password does not match, or of the blocklist.
+func (s PasswordUseCase) checkPolicy(ctx context.Context, userId string, password string) (PasswordResult, bool) {
+	if code := s.violation(password); len(code) > 0 {
+		s.audit(ctx, EventPolicyRejected, userId, code)
+		return s.result(ctx, -2, code), false
+	}
+	return PasswordResult{}, true
+}
+
+// violation returns the code of the message of the first expression which the password does not match, or of the blocklist, or "".
+func (s PasswordUseCase) violation(password string) string {
+	for i, exp := range s.Regexps {
+		if !exp.MatchString(password) {
+			return PolicyMessage(i)
+		}
+	}
+	if s.Blocklist != nil && s.Blocklist.Contains(password) {
+		return MessageBlocked
+	}
+	return ""
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
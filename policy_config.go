@@ -0,0 +1,317 @@
+package password
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	PresetNIST   = "nist-800-63b"
+	PresetOWASP  = "owasp-asvs-l2"
+	PresetLegacy = "legacy-complex"
+)
+
+const (
+	ClassUpper  = "upper"
+	ClassLower  = "lower"
+	ClassDigit  = "digit"
+	ClassSymbol = "symbol"
+)
+
+var classExpressions = map[string]string{
+	ClassUpper:  `\p{Lu}`,
+	ClassLower:  `\p{Ll}`,
+	ClassDigit:  `\p{Nd}`,
+	ClassSymbol: `[^\p{L}\p{Nd}]`,
+}
+
+// PolicyConfig is the declarative password policy, such as the "policy" key of PasswordConfig.
+// The fields which are set override the fields of the preset: MinLength, MaxLength and History.Count are pointers, so that 0 overrides the preset too,
+// and Classes overrides the classes of the preset when it is not nil, so an empty list requires no class.
+// PasswordUseCase enforces the length, the classes, the blocklist, the history count and the passcode. It does not enforce Age, Lockout and History.Days,
+// which are not in the presets, so Validate rejects them when they are set.
+type PolicyConfig struct {
+	Preset        string         `mapstructure:"preset" json:"preset,omitempty" gorm:"column:preset" bson:"preset,omitempty" dynamodbav:"preset,omitempty" firestore:"preset,omitempty"`
+	MinLength     *int           `mapstructure:"min_length" json:"minLength,omitempty" gorm:"column:minlength" bson:"minLength,omitempty" dynamodbav:"minLength,omitempty" firestore:"minLength,omitempty"`
+	MaxLength     *int           `mapstructure:"max_length" json:"maxLength,omitempty" gorm:"column:maxlength" bson:"maxLength,omitempty" dynamodbav:"maxLength,omitempty" firestore:"maxLength,omitempty"`
+	Classes       []string       `mapstructure:"classes" json:"classes,omitempty" gorm:"column:classes" bson:"classes,omitempty" dynamodbav:"classes,omitempty" firestore:"classes,omitempty"`
+	Blocklist     []string       `mapstructure:"blocklist" json:"blocklist,omitempty" gorm:"column:blocklist" bson:"blocklist,omitempty" dynamodbav:"blocklist,omitempty" firestore:"blocklist,omitempty"`
+	BlocklistFile string         `mapstructure:"blocklist_file" json:"blocklistFile,omitempty" gorm:"column:blocklistfile" bson:"blocklistFile,omitempty" dynamodbav:"blocklistFile,omitempty" firestore:"blocklistFile,omitempty"`
+	History       HistoryConfig  `mapstructure:"history" json:"history,omitempty" gorm:"column:history" bson:"history,omitempty" dynamodbav:"history,omitempty" firestore:"history,omitempty"`
+	Age           AgeConfig      `mapstructure:"age" json:"age,omitempty" gorm:"column:age" bson:"age,omitempty" dynamodbav:"age,omitempty" firestore:"age,omitempty"`
+	Lockout       LockoutConfig  `mapstructure:"lockout" json:"lockout,omitempty" gorm:"column:lockout" bson:"lockout,omitempty" dynamodbav:"lockout,omitempty" firestore:"lockout,omitempty"`
+	Passcode      PasscodeConfig `mapstructure:"passcode" json:"passcode,omitempty" gorm:"column:passcode" bson:"passcode,omitempty" dynamodbav:"passcode,omitempty" firestore:"passcode,omitempty"`
+}
+
+type HistoryConfig struct {
+	Count *int `mapstructure:"count" json:"count,omitempty" gorm:"column:count" bson:"count,omitempty" dynamodbav:"count,omitempty" firestore:"count,omitempty"`
+	Days  int  `mapstructure:"days" json:"days,omitempty" gorm:"column:days" bson:"days,omitempty" dynamodbav:"days,omitempty" firestore:"days,omitempty"`
+}
+
+// AgeConfig is in days: the password cannot be changed before Min, expires after Max, and the user is warned Warning days before the expiry.
+type AgeConfig struct {
+	Min     int `mapstructure:"min" json:"min,omitempty" gorm:"column:min" bson:"min,omitempty" dynamodbav:"min,omitempty" firestore:"min,omitempty"`
+	Max     int `mapstructure:"max" json:"max,omitempty" gorm:"column:max" bson:"max,omitempty" dynamodbav:"max,omitempty" firestore:"max,omitempty"`
+	Warning int `mapstructure:"warning" json:"warning,omitempty" gorm:"column:warning" bson:"warning,omitempty" dynamodbav:"warning,omitempty" firestore:"warning,omitempty"`
+}
+
+// LockoutConfig locks the account after Threshold consecutive failures, for Duration seconds, or until the password is reset if Duration is 0.
+type LockoutConfig struct {
+	Threshold int `mapstructure:"threshold" json:"threshold,omitempty" gorm:"column:threshold" bson:"threshold,omitempty" dynamodbav:"threshold,omitempty" firestore:"threshold,omitempty"`
+	Duration  int `mapstructure:"duration" json:"duration,omitempty" gorm:"column:duration" bson:"duration,omitempty" dynamodbav:"duration,omitempty" firestore:"duration,omitempty"`
+}
+
+// PasscodeConfig is the number of digits of the reset and change codes, and their TTL in seconds.
+type PasscodeConfig struct {
+	Length    int `mapstructure:"length" json:"length,omitempty" gorm:"column:length" bson:"length,omitempty" dynamodbav:"length,omitempty" firestore:"length,omitempty"`
+	ResetTTL  int `mapstructure:"reset_ttl" json:"resetTTL,omitempty" gorm:"column:resetttl" bson:"resetTTL,omitempty" dynamodbav:"resetTTL,omitempty" firestore:"resetTTL,omitempty"`
+	ChangeTTL int `mapstructure:"change_ttl" json:"changeTTL,omitempty" gorm:"column:changettl" bson:"changeTTL,omitempty" dynamodbav:"changeTTL,omitempty" firestore:"changeTTL,omitempty"`
+}
+
+// Presets are the named policies of PolicyConfig.Preset, with the settings which PasswordUseCase enforces only.
+// nist-800-63b: 8 to 64 characters, no composition rules and a blocklist.
+// owasp-asvs-l2: 12 to 128 characters, no composition rules and a blocklist.
+// legacy-complex: 8 characters with the 4 classes, and 5 previous passwords.
+var Presets = map[string]PolicyConfig{
+	PresetNIST: {
+		MinLength: newInt(8),
+		MaxLength: newInt(64),
+		Classes:   []string{},
+		Blocklist: CommonPasswords,
+		Passcode:  PasscodeConfig{Length: 6, ResetTTL: 600, ChangeTTL: 600},
+	},
+	PresetOWASP: {
+		MinLength: newInt(12),
+		MaxLength: newInt(128),
+		Classes:   []string{},
+		Blocklist: CommonPasswords,
+		Passcode:  PasscodeConfig{Length: 6, ResetTTL: 600, ChangeTTL: 600},
+	},
+	PresetLegacy: {
+		MinLength: newInt(8),
+		Classes:   []string{ClassUpper, ClassLower, ClassDigit, ClassSymbol},
+		History:   HistoryConfig{Count: newInt(5)},
+		Passcode:  PasscodeConfig{Length: 6, ResetTTL: 900, ChangeTTL: 900},
+	},
+}
+
+// ValidationError is an invalid value of the config, with the path of its key, such as "policy.max_length".
+type ValidationError struct {
+	Key     string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Key + ": " + e.Message
+}
+
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Resolve returns the policy of the preset, overridden by the fields which are set, and validates it.
+// The keys of the errors are prefixed with the option, such as "password.policy".
+func (c PolicyConfig) Resolve(options ...string) (PolicyConfig, error) {
+	prefix := ""
+	if len(options) > 0 && len(options[0]) > 0 {
+		prefix = options[0] + "."
+	}
+	if len(c.Preset) == 0 {
+		return c, c.Validate(options...)
+	}
+	preset, ok := Presets[c.Preset]
+	if !ok {
+		return c, ValidationErrors{{Key: prefix + "preset", Message: fmt.Sprintf("unknown preset %q", c.Preset)}}
+	}
+	r := preset
+	r.Preset = c.Preset
+	if c.MinLength != nil {
+		r.MinLength = c.MinLength
+	}
+	if c.MaxLength != nil {
+		r.MaxLength = c.MaxLength
+	}
+	if c.Classes != nil {
+		r.Classes = c.Classes
+	}
+	if c.Blocklist != nil {
+		r.Blocklist = c.Blocklist
+	}
+	if len(c.BlocklistFile) > 0 {
+		r.BlocklistFile = c.BlocklistFile
+	}
+	if c.History.Count != nil {
+		r.History.Count = c.History.Count
+	}
+	r.History.Days = c.History.Days
+	r.Age = c.Age
+	r.Lockout = c.Lockout
+	override(&r.Passcode.Length, c.Passcode.Length)
+	override(&r.Passcode.ResetTTL, c.Passcode.ResetTTL)
+	override(&r.Passcode.ChangeTTL, c.Passcode.ChangeTTL)
+	return r, r.Validate(options...)
+}
+
+// Validate returns ValidationErrors with all the invalid values, or nil.
+func (c PolicyConfig) Validate(options ...string) error {
+	prefix := ""
+	if len(options) > 0 && len(options[0]) > 0 {
+		prefix = options[0] + "."
+	}
+	var errs ValidationErrors
+	add := func(key string, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Key: prefix + key, Message: fmt.Sprintf(format, args...)})
+	}
+	if len(c.Preset) > 0 {
+		if _, ok := Presets[c.Preset]; !ok {
+			add("preset", "unknown preset %q", c.Preset)
+		}
+	}
+	minLength, maxLength := intValue(c.MinLength), intValue(c.MaxLength)
+	if minLength < 0 {
+		add("min_length", "must not be negative")
+	}
+	if maxLength < 0 {
+		add("max_length", "must not be negative")
+	} else if maxLength > 0 && maxLength < minLength {
+		add("max_length", "must not be less than min_length (%d)", minLength)
+	}
+	for i, class := range c.Classes {
+		if _, ok := classExpressions[class]; !ok {
+			add("classes["+strconv.Itoa(i)+"]", "unknown class %q, must be upper, lower, digit or symbol", class)
+		}
+	}
+	if len(c.BlocklistFile) > 0 {
+		if _, err := os.Stat(c.BlocklistFile); err != nil {
+			add("blocklist_file", "%s", err.Error())
+		}
+	}
+	if intValue(c.History.Count) < 0 {
+		add("history.count", "must not be negative")
+	}
+	// PasswordUseCase does not enforce the age, the lockout and the days of the history, so they are rejected, instead of being ignored silently.
+	if c.History.Days != 0 {
+		add("history.days", "is not supported, the history is limited by history.count")
+	}
+	if c.Age.Min != 0 {
+		add("age.min", "is not supported")
+	}
+	if c.Age.Max != 0 {
+		add("age.max", "is not supported")
+	}
+	if c.Age.Warning != 0 {
+		add("age.warning", "is not supported")
+	}
+	if c.Lockout.Threshold != 0 {
+		add("lockout.threshold", "is not supported, the lockout is done by the authenticator")
+	}
+	if c.Lockout.Duration != 0 {
+		add("lockout.duration", "is not supported, the lockout is done by the authenticator")
+	}
+	if c.Passcode.Length != 0 && (c.Passcode.Length < 4 || c.Passcode.Length > 10) {
+		add("passcode.length", "must be between 4 and 10")
+	}
+	if c.Passcode.ResetTTL < 0 {
+		add("passcode.reset_ttl", "must not be negative")
+	}
+	if c.Passcode.ChangeTTL < 0 {
+		add("passcode.change_ttl", "must not be negative")
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Expressions returns the expressions of the length and the classes, in this order, for the Regexps of PasswordUseCase.
+func (c PolicyConfig) Expressions() []string {
+	expressions := make([]string, 0)
+	minLength, maxLength := intValue(c.MinLength), intValue(c.MaxLength)
+	if minLength > 0 || maxLength > 0 {
+		if maxLength > 0 {
+			expressions = append(expressions, fmt.Sprintf(`^[\s\S]{%d,%d}$`, minLength, maxLength))
+		} else {
+			expressions = append(expressions, fmt.Sprintf(`^[\s\S]{%d,}$`, minLength))
+		}
+	}
+	for _, class := range c.Classes {
+		if exp, ok := classExpressions[class]; ok {
+			expressions = append(expressions, exp)
+		}
+	}
+	return expressions
+}
+
+// LoadBlocklist returns the blocklist of Blocklist and BlocklistFile, or nil if there is none.
+func (c PolicyConfig) LoadBlocklist() (Blocklist, error) {
+	if len(c.BlocklistFile) > 0 {
+		return LoadBlocklist(c.BlocklistFile, c.Blocklist...)
+	}
+	if len(c.Blocklist) > 0 {
+		return NewBlocklist(c.Blocklist...), nil
+	}
+	return nil, nil
+}
+
+// Apply sets the policy, the history count and the passcode of the use case; the config should be resolved first.
+// A history count of 0 disables the check of the previous passwords, and the TTLs of the passcode are used only when the expiries of the use case are not set.
+func (c PolicyConfig) Apply(s *PasswordUseCase) error {
+	regExps := make([]regexp.Regexp, 0)
+	for _, expression := range c.Expressions() {
+		regExp, err := regexp.Compile(expression)
+		if err != nil {
+			return err
+		}
+		regExps = append(regExps, *regExp)
+	}
+	s.Regexps = regExps
+	blocklist, err := c.LoadBlocklist()
+	if err != nil {
+		return err
+	}
+	if blocklist != nil {
+		s.Blocklist = blocklist
+	}
+	if c.History.Count != nil {
+		s.DuplicateCount = *c.History.Count
+	}
+	if c.Passcode.ResetTTL > 0 && s.PasswordResetExpires <= 0 {
+		s.PasswordResetExpires = c.Passcode.ResetTTL
+	}
+	if c.Passcode.ChangeTTL > 0 && s.PasswordChangeExpires <= 0 {
+		s.PasswordChangeExpires = c.Passcode.ChangeTTL
+	}
+	if c.Passcode.Length > 0 {
+		length := c.Passcode.Length
+		s.Generate = func() string {
+			return generate(length)
+		}
+	}
+	return nil
+}
+
+func override(target *int, value int) {
+	if value != 0 {
+		*target = value
+	}
+}
+
+func newInt(value int) *int {
+	return &value
+}
+
+func intValue(value *int) int {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
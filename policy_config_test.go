@@ -0,0 +1,102 @@
+package password_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	p "github.com/core-go/password"
+)
+
+func newInt(value int) *int {
+	return &value
+}
+
+func TestPolicyConfigResolve(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      p.PolicyConfig
+		expressions []string
+		history     int
+		blocked     string
+	}{
+		{"NIST", p.PolicyConfig{Preset: p.PresetNIST}, []string{`^[\s\S]{8,64}$`}, 0, "password"},
+		{"OWASP", p.PolicyConfig{Preset: p.PresetOWASP}, []string{`^[\s\S]{12,128}$`}, 0, "password"},
+		{"Legacy", p.PolicyConfig{Preset: p.PresetLegacy}, []string{`^[\s\S]{8,}$`, `\p{Lu}`, `\p{Ll}`, `\p{Nd}`, `[^\p{L}\p{Nd}]`}, 5, ""},
+		{"OverrideLength", p.PolicyConfig{Preset: p.PresetNIST, MinLength: newInt(10)}, []string{`^[\s\S]{10,64}$`}, 0, "password"},
+		{"ZeroOverridesPreset", p.PolicyConfig{Preset: p.PresetLegacy, MinLength: newInt(0), History: p.HistoryConfig{Count: newInt(0)}}, []string{`\p{Lu}`, `\p{Ll}`, `\p{Nd}`, `[^\p{L}\p{Nd}]`}, 0, ""},
+		{"EmptyClassesOverridePreset", p.PolicyConfig{Preset: p.PresetLegacy, Classes: []string{}}, []string{`^[\s\S]{8,}$`}, 5, ""},
+		{"WithoutPreset", p.PolicyConfig{MinLength: newInt(6), Classes: []string{p.ClassDigit}}, []string{`^[\s\S]{6,}$`, `\p{Nd}`}, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := tt.config.Resolve("policy")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := policy.Expressions(); !reflect.DeepEqual(got, tt.expressions) {
+				t.Errorf("Expressions() = %v; want %v", got, tt.expressions)
+			}
+			var s p.PasswordUseCase
+			if err := policy.Apply(&s); err != nil {
+				t.Fatal(err)
+			}
+			if s.DuplicateCount != tt.history {
+				t.Errorf("DuplicateCount = %d; want %d", s.DuplicateCount, tt.history)
+			}
+			if len(tt.blocked) > 0 && (s.Blocklist == nil || !s.Blocklist.Contains(tt.blocked)) {
+				t.Errorf("the blocklist does not contain %q", tt.blocked)
+			}
+		})
+	}
+}
+
+func TestPolicyConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config p.PolicyConfig
+		keys   []string
+	}{
+		{"Valid", p.PolicyConfig{MinLength: newInt(8), MaxLength: newInt(64), Classes: []string{p.ClassUpper}, Passcode: p.PasscodeConfig{Length: 6}}, nil},
+		{"UnknownPreset", p.PolicyConfig{Preset: "strong"}, []string{"policy.preset"}},
+		{"NegativeLength", p.PolicyConfig{MinLength: newInt(-1)}, []string{"policy.min_length"}},
+		{"MaxLessThanMin", p.PolicyConfig{MinLength: newInt(12), MaxLength: newInt(8)}, []string{"policy.max_length"}},
+		{"UnknownClass", p.PolicyConfig{Classes: []string{p.ClassDigit, "emoji"}}, []string{"policy.classes[1]"}},
+		{"MissingBlocklistFile", p.PolicyConfig{BlocklistFile: "missing.txt"}, []string{"policy.blocklist_file"}},
+		{"NegativeHistory", p.PolicyConfig{History: p.HistoryConfig{Count: newInt(-1)}}, []string{"policy.history.count"}},
+		{"PasscodeLength", p.PolicyConfig{Passcode: p.PasscodeConfig{Length: 3, ResetTTL: -1}}, []string{"policy.passcode.length", "policy.passcode.reset_ttl"}},
+		{"HistoryDaysNotSupported", p.PolicyConfig{History: p.HistoryConfig{Count: newInt(5), Days: 365}}, []string{"policy.history.days"}},
+		{"AgeNotSupported", p.PolicyConfig{Age: p.AgeConfig{Min: 1, Max: 90, Warning: 7}}, []string{"policy.age.min", "policy.age.max", "policy.age.warning"}},
+		{"LockoutNotSupported", p.PolicyConfig{Lockout: p.LockoutConfig{Threshold: 5, Duration: 900}}, []string{"policy.lockout.threshold", "policy.lockout.duration"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate("policy")
+			if tt.keys == nil {
+				if err != nil {
+					t.Fatalf("Validate() = %v; want no error", err)
+				}
+				return
+			}
+			var errs p.ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Validate() = %v; want ValidationErrors", err)
+			}
+			keys := make([]string, len(errs))
+			for i, e := range errs {
+				keys[i] = e.Key
+			}
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("keys = %v; want %v", keys, tt.keys)
+			}
+		})
+	}
+}
+
+func TestPolicyConfigResolveRejectsUnsupported(t *testing.T) {
+	_, err := p.PolicyConfig{Preset: p.PresetNIST, Lockout: p.LockoutConfig{Threshold: 5}}.Resolve("policy")
+	var errs p.ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Key != "policy.lockout.threshold" {
+		t.Errorf("Resolve() = %v; want the error of policy.lockout.threshold", err)
+	}
+}
@@ -0,0 +1,28 @@
+package password
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+)
+
+// FilePolicyStore loads the policies from a JSON file, which holds an array of policies.
+type FilePolicyStore struct {
+	Path string
+}
+
+func NewFilePolicyStore(path string) *FilePolicyStore {
+	return &FilePolicyStore{Path: path}
+}
+
+func (s *FilePolicyStore) Load(ctx context.Context) ([]Policy, error) {
+	b, err := ioutil.ReadFile(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	var policies []Policy
+	if err := json.Unmarshal(b, &policies); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
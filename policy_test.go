@@ -0,0 +1,214 @@
+package password_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	p "github.com/core-go/password"
+)
+
+func TestPolicyMatches(t *testing.T) {
+	user := p.PolicyUser{Id: "u1", Tenant: "acme", Roles: []string{"admin"}, Groups: []string{"finance"}}
+	tests := []struct {
+		name   string
+		policy p.Policy
+		want   bool
+	}{
+		{"Default", p.Policy{}, true},
+		{"Tenant", p.Policy{Tenant: "acme"}, true},
+		{"OtherTenant", p.Policy{Tenant: "other"}, false},
+		{"Role", p.Policy{Role: "admin"}, true},
+		{"OtherRole", p.Policy{Role: "auditor"}, false},
+		{"Group", p.Policy{Tenant: "acme", Group: "finance"}, true},
+		{"OtherGroup", p.Policy{Role: "admin", Group: "sales"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.Matches(user); got != tt.want {
+			t.Errorf("%s: Matches() = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// policyStore returns the policies, or fails with err, and counts the loads.
+type policyStore struct {
+	mutex    sync.Mutex
+	policies []p.Policy
+	err      error
+	loads    int
+}
+
+func (s *policyStore) Load(ctx context.Context) ([]p.Policy, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.loads++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return append([]p.Policy(nil), s.policies...), nil
+}
+
+func (s *policyStore) set(policies []p.Policy, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.policies, s.err = policies, err
+}
+
+func (s *policyStore) count() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.loads
+}
+
+func resolve(t *testing.T, selector *p.PolicySelector, user p.PolicyUser) string {
+	t.Helper()
+	policy, err := selector.Resolve(context.Background(), user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy == nil {
+		return ""
+	}
+	return policy.Id
+}
+
+func TestPolicySelectorResolve(t *testing.T) {
+	store := &policyStore{policies: []p.Policy{
+		{Id: "default", Expressions: []string{".{8,}"}},
+		{Id: "acme", Tenant: "acme", Priority: 1},
+		{Id: "admin", Role: "admin", Priority: 2, Expressions: []string{".{12,}"}},
+		{Id: "broken", Role: "admin", Priority: 3, Expressions: []string{"("}},
+	}}
+	var reported []string
+	selector := p.NewPolicySelector(store)
+	selector.Error = func(ctx context.Context, msg string, fields ...map[string]interface{}) {
+		reported = append(reported, msg)
+	}
+	tests := []struct {
+		user p.PolicyUser
+		want string
+	}{
+		{p.PolicyUser{Id: "u1"}, "default"},
+		{p.PolicyUser{Id: "u1", Tenant: "acme"}, "acme"},
+		// the broken policy has the highest priority, but it is skipped
+		{p.PolicyUser{Id: "u1", Tenant: "acme", Roles: []string{"admin"}}, "admin"},
+	}
+	for _, tt := range tests {
+		if got := resolve(t, selector, tt.user); got != tt.want {
+			t.Errorf("Resolve(%+v) = %q; want %q", tt.user, got, tt.want)
+		}
+	}
+	if len(reported) != 1 || !strings.Contains(reported[0], "skipped") {
+		t.Errorf("reported %v; want the broken policy reported once", reported)
+	}
+	if store.count() != 1 {
+		t.Errorf("%d loads; want the policies cached", store.count())
+	}
+	if _, err := p.NewPolicySelector(&policyStore{}).Resolve(context.Background(), p.PolicyUser{Id: "u1"}); err != nil {
+		t.Errorf("Resolve() without policies = %v; want nil", err)
+	}
+}
+
+func TestPolicySelectorCache(t *testing.T) {
+	user := p.PolicyUser{Id: "u1"}
+	store := &policyStore{policies: []p.Policy{{Id: "v1"}}}
+	var reported []string
+	selector := p.NewPolicySelector(store, 50*time.Millisecond)
+	selector.Error = func(ctx context.Context, msg string, fields ...map[string]interface{}) {
+		reported = append(reported, msg)
+	}
+	if got := resolve(t, selector, user); got != "v1" {
+		t.Fatalf("Resolve() = %q; want v1", got)
+	}
+	store.set([]p.Policy{{Id: "v2"}}, nil)
+	if got := resolve(t, selector, user); got != "v1" || store.count() != 1 {
+		t.Errorf("Resolve() = %q after %d loads; want the cached v1", got, store.count())
+	}
+	time.Sleep(60 * time.Millisecond)
+	if got := resolve(t, selector, user); got != "v2" || store.count() != 2 {
+		t.Errorf("Resolve() = %q after %d loads; want v2 loaded after the expiry", got, store.count())
+	}
+
+	// the policies which were loaded before are kept when the store fails
+	store.set(nil, errors.New("connection refused"))
+	time.Sleep(60 * time.Millisecond)
+	if got := resolve(t, selector, user); got != "v2" {
+		t.Errorf("Resolve() = %q; want the kept v2 when the store fails", got)
+	}
+	if len(reported) != 1 || !strings.Contains(reported[0], "connection refused") {
+		t.Errorf("reported %v; want the failure of the store", reported)
+	}
+
+	store.set([]p.Policy{{Id: "v3"}}, nil)
+	selector.Refresh()
+	if got := resolve(t, selector, user); got != "v3" {
+		t.Errorf("Resolve() after Refresh = %q; want v3", got)
+	}
+
+	never := p.NewPolicySelector(store, 0)
+	resolve(t, never, user)
+	resolve(t, never, user)
+	loads := store.count()
+	time.Sleep(10 * time.Millisecond)
+	resolve(t, never, user)
+	if store.count() != loads {
+		t.Errorf("%d loads; want the policies cached forever when Expires is 0", store.count()-loads)
+	}
+
+	failing := p.NewPolicySelector(&policyStore{err: errors.New("connection refused")})
+	if _, err := failing.Resolve(context.Background(), user); err == nil {
+		t.Error("Resolve() with a failing store and no policies = nil; want an error")
+	}
+}
+
+func TestFilePolicyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.json")
+	if err := ioutil.WriteFile(path, []byte(`[{"id":"admin","role":"admin","priority":1,"expressions":[".{12,}"],"duplicateCount":5}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	policies, err := p.NewFilePolicyStore(path).Load(context.Background())
+	if err != nil || len(policies) != 1 || policies[0].Role != "admin" || policies[0].DuplicateCount != 5 || len(policies[0].Expressions) != 1 {
+		t.Errorf("Load() = %+v, %v; want the admin policy", policies, err)
+	}
+	if _, err := p.NewFilePolicyStore(filepath.Join(t.TempDir(), "missing.json")).Load(context.Background()); err == nil {
+		t.Error("Load() of a missing file = nil; want an error")
+	}
+}
+
+func TestChangePasswordByPolicy(t *testing.T) {
+	selector := p.NewPolicySelector(&policyStore{policies: []p.Policy{
+		{Id: "default", Expressions: []string{".{4,}"}},
+		{Id: "admin", Role: "admin", Priority: 1, Expressions: []string{".{12,}"}},
+	}})
+	tests := []struct {
+		name     string
+		roles    []string
+		password string
+		want     int32
+	}{
+		{"User", nil, "secret", 1},
+		{"UserTooShort", nil, "abc", -2},
+		{"AdminTooShort", []string{"admin"}, "secret", -2},
+		{"Admin", []string{"admin"}, "secret-secret", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := p.PasswordUseCase{
+				PasswordComparator: plain{},
+				PasswordRepository: newUsers(user{id: "u1", username: "alice", password: "h:p0"}),
+				PolicyResolver:     selector,
+				GetPolicyUser: func(ctx context.Context, id string) (*p.PolicyUser, error) {
+					return &p.PolicyUser{Roles: tt.roles}, nil
+				},
+			}
+			if status, err := s.ChangePassword(context.Background(), p.PasswordChange{Username: "alice", CurrentPassword: "p0", Password: tt.password}); status != tt.want || err != nil {
+				t.Errorf("ChangePassword() = %d, %v; want %d", status, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package password
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrForbidden is returned by ChangePassword when the authenticated user changes the password of another user, and is not an admin.
+var ErrForbidden = errors.New("the password of another user cannot be changed")
+
+type principalKey struct{}
+
+type principal struct {
+	id    string
+	admin bool
+}
+
+// WithPrincipal adds the authenticated user to the context, with the id of the user and whether the user can act on behalf of the other users.
+// ChangePassword of PasswordUseCase checks the user of the request against it.
+func WithPrincipal(ctx context.Context, id string, admin bool) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal{id: id, admin: admin})
+}
+
+// GetPrincipal returns the id of the authenticated user, whether the user is an admin, and whether the context has an authenticated user.
+func GetPrincipal(ctx context.Context) (string, bool, bool) {
+	if p, ok := ctx.Value(principalKey{}).(principal); ok {
+		return p.id, p.admin, true
+	}
+	return "", false, false
+}
+
+// owns returns true if the context has no authenticated user, or if the authenticated user is the user, by the id only, or an admin.
+func owns(ctx context.Context, userId string) bool {
+	id, admin, ok := GetPrincipal(ctx)
+	if !ok || admin {
+		return true
+	}
+	return len(id) > 0 && id == userId
+}
@@ -0,0 +1,121 @@
+package password_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/memory"
+)
+
+func newPrincipalUsers() *memory.PasswordRepository {
+	return memory.NewPasswordRepository("userId", 5,
+		memory.User{Id: "u1", Username: "alice", Password: "h:p0"},
+		memory.User{Id: "u2", Username: "bob", Password: "h:p0"})
+}
+
+func TestChangePasswordPrincipal(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      func(ctx context.Context) context.Context
+		username string
+		status   int32
+		err      error
+	}{
+		{"WithoutPrincipal", func(ctx context.Context) context.Context { return ctx }, "bob", 1, nil},
+		{"Owner", func(ctx context.Context) context.Context { return p.WithPrincipal(ctx, "u1", false) }, "alice", 1, nil},
+		{"OtherUser", func(ctx context.Context) context.Context { return p.WithPrincipal(ctx, "u1", false) }, "bob", 0, p.ErrForbidden},
+		// the username is not the id, so that a user cannot take the username of another user as the id
+		{"UsernameAsId", func(ctx context.Context) context.Context { return p.WithPrincipal(ctx, "bob", false) }, "bob", 0, p.ErrForbidden},
+		{"UnknownUser", func(ctx context.Context) context.Context { return p.WithPrincipal(ctx, "u1", false) }, "carol", 0, p.ErrForbidden},
+		{"UnknownWithoutPrincipal", func(ctx context.Context) context.Context { return ctx }, "carol", 0, nil},
+		{"Admin", func(ctx context.Context) context.Context { return p.WithPrincipal(ctx, "u9", true) }, "bob", 1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := p.New(newPrincipalUsers(), plain{})
+			status, err := s.ChangePassword(tt.ctx(context.Background()), p.PasswordChange{Username: tt.username, CurrentPassword: "p0", Password: "p1"})
+			if status != tt.status || !errors.Is(err, tt.err) {
+				t.Errorf("ChangePassword() = %d, %v; want %d, %v", status, err, tt.status, tt.err)
+			}
+		})
+	}
+	if status := p.ErrorStatus(p.ErrForbidden); status != http.StatusForbidden {
+		t.Errorf("ErrorStatus(ErrForbidden) = %d; want 403", status)
+	}
+}
+
+func TestHandlerWithRequestUser(t *testing.T) {
+	getUser := func(r *http.Request) string {
+		return r.Header.Get("X-User-Id")
+	}
+	isAdmin := func(r *http.Request) bool {
+		return r.Header.Get("X-User-Id") == "u9"
+	}
+	tests := []struct {
+		name      string
+		user      string
+		username  string
+		status    int
+		changedBy string
+	}{
+		{"WithoutUser", "", "alice", http.StatusUnauthorized, ""},
+		{"Owner", "u1", "alice", http.StatusOK, "u1"},
+		{"OtherUser", "u1", "bob", http.StatusForbidden, ""},
+		{"UnknownUser", "u1", "carol", http.StatusForbidden, ""},
+		{"AdminOnBehalf", "u9", "bob", http.StatusOK, "u9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newPrincipalUsers()
+			h := p.NewHandler(p.New(users, plain{}), p.WithRequestUser("userId", getUser, isAdmin))
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/password/change", strings.NewReader(`{"username":"`+tt.username+`","currentPassword":"p0","password":"p1"}`))
+			r.Header.Set("X-User-Id", tt.user)
+			h.ChangePassword(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d %s; want %d", w.Code, w.Body, tt.status)
+			}
+			for _, id := range []string{"u1", "u2"} {
+				user, _ := users.User(id)
+				if changed := user.Password == "h:p1"; changed != (tt.status == http.StatusOK && user.Username == tt.username) {
+					t.Errorf("%s: password = %q; want it changed only for %s when allowed", id, user.Password, tt.username)
+				} else if changed && user.ChangedBy != tt.changedBy {
+					t.Errorf("%s: ChangedBy = %q; want %q", id, user.ChangedBy, tt.changedBy)
+				}
+			}
+		})
+	}
+}
+
+func TestHandlerWithRequestUserFromContext(t *testing.T) {
+	users := newPrincipalUsers()
+	h := p.NewHandler(p.New(users, plain{}), p.WithRequestUser("userId", nil, nil))
+	tests := []struct {
+		user   string
+		status int
+	}{
+		{"", http.StatusUnauthorized},
+		{"u2", http.StatusForbidden},
+		{"u1", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/password/change", strings.NewReader(`{"username":"alice","currentPassword":"p0","password":"p1"}`))
+		if len(tt.user) > 0 {
+			// the authentication middleware puts the id of the user into the context with the key
+			r = r.WithContext(context.WithValue(r.Context(), "userId", tt.user))
+		}
+		h.ChangePassword(w, r)
+		if w.Code != tt.status {
+			t.Errorf("user %q: status = %d %s; want %d", tt.user, w.Code, w.Body, tt.status)
+		}
+	}
+	if user, _ := users.User("u1"); user.ChangedBy != "u1" {
+		t.Errorf("ChangedBy = %q; want u1", user.ChangedBy)
+	}
+}
@@ -0,0 +1,81 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+const (
+	ContentTypeProblem = "application/problem+json"
+	contentTypeJSON    = "application/json"
+)
+
+// The kinds of the problems, which are appended to ProblemBase of HandlerCore to make the type of the problem.
+const (
+	ProblemPolicy          = "policy"
+	ProblemDuplicate       = "duplicate"
+	ProblemInvalid         = "invalid"
+	ProblemExpired         = "expired"
+	ProblemTooManyRequests = "too-many-requests"
+	ProblemBadRequest      = "bad-request"
+	ProblemUnauthenticated = "unauthenticated"
+	ProblemForbidden       = "forbidden"
+	ProblemInternal        = "internal"
+)
+
+// Problem is the body of a failure, as defined by RFC 7807; Code is the code of the result, such as "password.exp1".
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code,omitempty"`
+}
+
+// HTTPStatus returns the HTTP status of the result: 200 for the successes and the challenges, 409 for a duplicate password,
+// 422 for a password which does not meet the policy, 410 for an expired code, and 401 for an invalid password or code.
+func HTTPStatus(result PasswordResult) int {
+	switch {
+	case result.Status > 0:
+		return http.StatusOK
+	case result.Status == -1:
+		return http.StatusConflict
+	case result.Status == -2:
+		return http.StatusUnprocessableEntity
+	case result.Code == MessageExpired:
+		return http.StatusGone
+	default:
+		return http.StatusUnauthorized
+	}
+}
+
+// ErrorStatus returns the HTTP status of the error of the service: 403 for ErrForbidden, 429 for ErrTooManyRequests, 501 for ErrDenyNotSupported, 504 for a timeout, and 500 for the other errors, such as the errors of the repositories.
+func ErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrTooManyRequests):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrDenyNotSupported):
+		return http.StatusNotImplemented
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func problemKind(status int) string {
+	switch status {
+	case http.StatusConflict:
+		return ProblemDuplicate
+	case http.StatusUnprocessableEntity:
+		return ProblemPolicy
+	case http.StatusGone:
+		return ProblemExpired
+	default:
+		return ProblemInvalid
+	}
+}
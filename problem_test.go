@@ -0,0 +1,106 @@
+package password_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/memory"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		result p.PasswordResult
+		want   int
+	}{
+		{p.PasswordResult{Status: 1, Code: p.MessageChanged}, http.StatusOK},
+		{p.PasswordResult{Status: 2, Code: p.MessageChallenged}, http.StatusOK},
+		{p.PasswordResult{Status: -1, Code: p.MessageDuplicate}, http.StatusConflict},
+		{p.PasswordResult{Status: -2, Code: p.PolicyMessage(0)}, http.StatusUnprocessableEntity},
+		{p.PasswordResult{Status: 0, Code: p.MessageExpired}, http.StatusGone},
+		{p.PasswordResult{Status: 0, Code: p.MessageInvalid}, http.StatusUnauthorized},
+		{p.PasswordResult{Status: 0}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if got := p.HTTPStatus(tt.result); got != tt.want {
+			t.Errorf("HTTPStatus(%+v) = %d; want %d", tt.result, got, tt.want)
+		}
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{p.ErrTooManyRequests, http.StatusTooManyRequests},
+		{fmt.Errorf("forgot: %w", p.ErrTooManyRequests), http.StatusTooManyRequests},
+		{p.ErrDenyNotSupported, http.StatusNotImplemented},
+		{fmt.Errorf("cannot load the user: %w", context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{errors.New("connection refused"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := p.ErrorStatus(tt.err); got != tt.want {
+			t.Errorf("ErrorStatus(%v) = %d; want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestHandlerProblem(t *testing.T) {
+	codes := memory.NewVerificationCodeRepository()
+	codes.Save(context.Background(), "u1", "h:expired", time.Now().Add(-time.Minute))
+	service := p.New(memory.NewPasswordRepository("userId", 5, memory.User{Id: "u1", Username: "alice", Password: "h:p0"}), plain{},
+		p.WithReset(600, codes, nil), p.WithExpressions(".{8,}"))
+	tests := []struct {
+		name    string
+		options []p.HandlerOption
+		path    string
+		body    string
+		status  int
+		problem p.Problem
+		legacy  string
+	}{
+		{"Expired", []p.HandlerOption{p.WithProblem("https://example.com/problems/")}, "/password/reset", `{"username":"alice","passcode":"expired","password":"password1"}`, http.StatusGone,
+			p.Problem{Type: "https://example.com/problems/expired", Title: "Gone", Status: http.StatusGone, Instance: "/password/reset", Code: p.MessageExpired}, ""},
+		{"Policy", []p.HandlerOption{p.WithProblem()}, "/password/change", `{"username":"alice","currentPassword":"p0","password":"short"}`, http.StatusUnprocessableEntity,
+			p.Problem{Type: "/problems/policy", Title: "Unprocessable Entity", Status: http.StatusUnprocessableEntity, Instance: "/password/change", Code: p.PolicyMessage(0)}, ""},
+		{"BadRequest", []p.HandlerOption{p.WithProblem()}, "/password/change", `{`, http.StatusBadRequest,
+			p.Problem{Type: "/problems/bad-request", Title: "Bad Request", Status: http.StatusBadRequest, Detail: "Cannot decode PasswordChange model", Instance: "/password/change"}, ""},
+		{"Legacy", nil, "/password/change", `{"username":"alice","currentPassword":"p0","password":"short"}`, http.StatusOK, p.Problem{}, "-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := p.NewHandler(service, tt.options...)
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			if strings.HasSuffix(tt.path, "reset") {
+				h.ResetPassword(w, r)
+			} else {
+				h.ChangePassword(w, r)
+			}
+			if w.Code != tt.status {
+				t.Fatalf("status = %d %s; want %d", w.Code, w.Body, tt.status)
+			}
+			if len(tt.legacy) > 0 {
+				if body := strings.TrimSpace(w.Body.String()); body != tt.legacy {
+					t.Errorf("body = %s; want %s", body, tt.legacy)
+				}
+				return
+			}
+			if contentType := w.Header().Get("Content-Type"); contentType != p.ContentTypeProblem {
+				t.Errorf("content type = %q; want %q", contentType, p.ContentTypeProblem)
+			}
+			var got p.Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || got != tt.problem {
+				t.Errorf("problem = %+v, %v; want %+v", got, err, tt.problem)
+			}
+		})
+	}
+}
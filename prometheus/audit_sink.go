@@ -0,0 +1,31 @@
+package prometheus
+
+import (
+	"context"
+
+	p "github.com/core-go/password"
+)
+
+// AuditSink counts the lockouts from the audit events, and writes the events to the next sink, if any.
+type AuditSink struct {
+	Metrics *Metrics
+	Next    p.AuditSink
+}
+
+func NewAuditSink(metrics *Metrics, options ...p.AuditSink) *AuditSink {
+	var next p.AuditSink
+	if len(options) >= 1 {
+		next = options[0]
+	}
+	return &AuditSink{Metrics: metrics, Next: next}
+}
+
+func (s *AuditSink) Write(ctx context.Context, event p.AuditEvent) error {
+	if event.Type == p.EventLockedOut {
+		s.Metrics.Lockouts.Inc()
+	}
+	if s.Next != nil {
+		return s.Next.Write(ctx, event)
+	}
+	return nil
+}
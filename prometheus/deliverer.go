@@ -0,0 +1,24 @@
+package prometheus
+
+import (
+	"context"
+
+	p "github.com/core-go/password"
+)
+
+type Deliverer struct {
+	Deliverer p.Deliverer
+	Metrics   *Metrics
+}
+
+func NewDeliverer(deliverer p.Deliverer, metrics *Metrics) *Deliverer {
+	return &Deliverer{Deliverer: deliverer, Metrics: metrics}
+}
+
+func (d *Deliverer) Deliver(ctx context.Context, message p.DeliveryMessage) (p.DeliveryMessage, error) {
+	sent, err := d.Deliverer.Deliver(ctx, message)
+	if err != nil {
+		d.Metrics.DeliveryFailures.WithLabelValues(message.Purpose).Inc()
+	}
+	return sent, err
+}
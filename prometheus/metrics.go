@@ -0,0 +1,105 @@
+package prometheus
+
+import (
+	"net/http"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	ActionChange = "change"
+	ActionReset  = "reset"
+	ActionForgot = "forgot"
+	ActionDeny   = "deny"
+
+	ResultError = "error"
+)
+
+// Metrics is a prometheus.Collector of the metrics of the password flows.
+type Metrics struct {
+	Operations       *prom.CounterVec
+	CodeFailures     *prom.CounterVec
+	Lockouts         prom.Counter
+	HashDuration     *prom.HistogramVec
+	DeliveryFailures *prom.CounterVec
+}
+
+func NewMetrics(options ...string) *Metrics {
+	namespace := "password"
+	if len(options) >= 1 && len(options[0]) > 0 {
+		namespace = options[0]
+	}
+	return &Metrics{
+		Operations: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "operations_total",
+			Help:      "The number of the operations by action and result code.",
+		}, []string{"action", "result"}),
+		CodeFailures: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "code_failures_total",
+			Help:      "The number of the invalid or expired passcodes by action and reason.",
+		}, []string{"action", "reason"}),
+		Lockouts: prom.NewCounter(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "lockouts_total",
+			Help:      "The number of the locked out accounts.",
+		}),
+		HashDuration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Name:      "hash_duration_seconds",
+			Help:      "The duration of hashing and comparing.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation"}),
+		DeliveryFailures: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "delivery_failures_total",
+			Help:      "The number of the codes which could not be delivered, by purpose.",
+		}, []string{"purpose"}),
+	}
+}
+
+// NewRegisteredMetrics creates the metrics and registers them to the registerer, or to the default registerer if it is nil.
+func NewRegisteredMetrics(registerer prom.Registerer, options ...string) (*Metrics, error) {
+	m := NewMetrics(options...)
+	if registerer == nil {
+		registerer = prom.DefaultRegisterer
+	}
+	if err := registerer.Register(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Metrics) collectors() []prom.Collector {
+	return []prom.Collector{m.Operations, m.CodeFailures, m.Lockouts, m.HashDuration, m.DeliveryFailures}
+}
+
+func (m *Metrics) Describe(ch chan<- *prom.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+func (m *Metrics) Collect(ch chan<- prom.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+// Handler returns the handler of the metrics of the gatherer, or of the default gatherer if it is nil.
+func Handler(gatherer prom.Gatherer) http.Handler {
+	if gatherer == nil {
+		return promhttp.Handler()
+	}
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+}
+
+// Mount serves the metrics at the path of the mux, "/metrics" by default.
+func Mount(mux *http.ServeMux, path string, gatherer prom.Gatherer) {
+	if len(path) == 0 {
+		path = "/metrics"
+	}
+	mux.Handle(path, Handler(gatherer))
+}
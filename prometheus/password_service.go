@@ -0,0 +1,99 @@
+package prometheus
+
+import (
+	"context"
+
+	p "github.com/core-go/password"
+)
+
+// PasswordService counts the operations by action and result code, and the passcode failures.
+type PasswordService struct {
+	Service p.PasswordService
+	Metrics *Metrics
+}
+
+func NewPasswordService(service p.PasswordService, metrics *Metrics) *PasswordService {
+	return &PasswordService{Service: service, Metrics: metrics}
+}
+
+func (s *PasswordService) ForgotPassword(ctx context.Context, email string) (bool, error) {
+	sent, err := s.Service.ForgotPassword(ctx, email)
+	result := "sent"
+	if err != nil {
+		result = ResultError
+	} else if !sent {
+		result = "not_sent"
+	}
+	s.Metrics.Operations.WithLabelValues(ActionForgot, result).Inc()
+	return sent, err
+}
+
+func (s *PasswordService) ResetPassword(ctx context.Context, pass p.PasswordReset) (int32, error) {
+	result, err := s.ResetPasswordWithResult(ctx, pass)
+	return result.Status, err
+}
+
+func (s *PasswordService) ResetPasswordWithResult(ctx context.Context, pass p.PasswordReset) (p.PasswordResult, error) {
+	ctx, check := p.WithCodeCheck(ctx)
+	result, err := p.ResultService(s.Service).ResetPasswordWithResult(ctx, pass)
+	s.count(ActionReset, result, err, check.Checked)
+	return result, err
+}
+
+func (s *PasswordService) ChangePassword(ctx context.Context, pass p.PasswordChange) (int32, error) {
+	result, err := s.ChangePasswordWithResult(ctx, pass)
+	return result.Status, err
+}
+
+// ChangePasswordWithResult counts the passcode failures only when the service has checked a code, since a wrong current password or an unknown user fails with the same code.
+func (s *PasswordService) ChangePasswordWithResult(ctx context.Context, pass p.PasswordChange) (p.PasswordResult, error) {
+	ctx, check := p.WithCodeCheck(ctx)
+	result, err := p.ResultService(s.Service).ChangePasswordWithResult(ctx, pass)
+	s.count(ActionChange, result, err, check.Checked)
+	return result, err
+}
+
+func (s *PasswordService) DenyChange(ctx context.Context, deny p.PasswordDeny) (p.PasswordResult, error) {
+	ctx, check := p.WithCodeCheck(ctx)
+	result, err := p.ResultService(s.Service).DenyChange(ctx, deny)
+	s.count(ActionDeny, result, err, check.Checked)
+	return result, err
+}
+
+// ValidatePassword forwards to the service, if it is a PasswordValidator.
+func (s *PasswordService) ValidatePassword(ctx context.Context, username string, password string) (p.PasswordResult, error) {
+	if v, ok := s.Service.(p.PasswordValidator); ok {
+		return v.ValidatePassword(ctx, username, password)
+	}
+	return p.PasswordResult{Status: 0}, p.ErrValidationNotSupported
+}
+
+func (s *PasswordService) count(action string, result p.PasswordResult, err error, passcode bool) {
+	if err != nil {
+		s.Metrics.Operations.WithLabelValues(action, ResultError).Inc()
+		return
+	}
+	s.Metrics.Operations.WithLabelValues(action, Result(result)).Inc()
+	if passcode && (result.Code == p.MessageInvalid || result.Code == p.MessageExpired) {
+		s.Metrics.CodeFailures.WithLabelValues(action, result.Code).Inc()
+	}
+}
+
+// Result returns the code of the result, or the status if there is no code.
+func Result(result p.PasswordResult) string {
+	if len(result.Code) > 0 {
+		return result.Code
+	}
+	switch result.Status {
+	case 1:
+		return "success"
+	case 2:
+		return "challenged"
+	case -1:
+		return "duplicate"
+	case -2:
+		return "policy"
+	default:
+		return "failed"
+	}
+}
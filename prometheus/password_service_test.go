@@ -0,0 +1,197 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	p "github.com/core-go/password"
+	po "github.com/core-go/password/otel"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+type plain struct{}
+
+func (plain) Hash(plaintext string) (string, error) {
+	return "h:" + plaintext, nil
+}
+func (plain) Compare(plaintext string, hashed string) (bool, error) {
+	return "h:"+plaintext == hashed, nil
+}
+
+// repository is a PasswordRepository of the user alice, which fails with err when it is set.
+type repository struct {
+	err error
+}
+
+func (r *repository) GetUserId(ctx context.Context, username string) (string, error) {
+	if r.err != nil || username != "alice" {
+		return "", r.err
+	}
+	return "u1", nil
+}
+func (r *repository) GetUser(ctx context.Context, usernameOrEmail string) (string, string, string, string, error) {
+	userId, err := r.GetUserId(ctx, usernameOrEmail)
+	return userId, usernameOrEmail, "", "h:p0", err
+}
+func (r *repository) Update(ctx context.Context, userId string, newPassword string) (int64, error) {
+	return 1, r.err
+}
+func (r *repository) UpdateWithCurrentPassword(ctx context.Context, userId string, currentPassword, newPassword string) (int64, error) {
+	return 1, r.err
+}
+func (r *repository) GetHistory(ctx context.Context, userId string, max int) ([]string, error) {
+	return nil, r.err
+}
+
+// codes is a VerificationCodeRepository in a map, which is safe for the codes deleted in the background.
+type codes struct {
+	mutex sync.Mutex
+	codes map[string]string
+}
+
+func (r *codes) Save(ctx context.Context, id string, passcode string, expireAt time.Time) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.codes[id] = passcode
+	return 1, nil
+}
+func (r *codes) Load(ctx context.Context, id string) (string, time.Time, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if code, ok := r.codes[id]; ok {
+		return code, time.Now().Add(time.Hour), nil
+	}
+	return "", time.Time{}, nil
+}
+func (r *codes) Delete(ctx context.Context, id string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.codes, id)
+	return 1, nil
+}
+
+func newUseCase(err error, comparator p.TextComparator) *p.PasswordUseCase {
+	return &p.PasswordUseCase{
+		PasswordComparator:      comparator,
+		PasswordRepository:      &repository{err: err},
+		ResetPasscodeRepository: &codes{codes: map[string]string{"u1": "h:123456"}},
+	}
+}
+
+func TestPasswordServiceResetPassword(t *testing.T) {
+	tests := []struct {
+		name       string
+		username   string
+		passcode   string
+		err        error
+		operations string
+		failures   string
+	}{
+		{"Success", "alice", "123456", nil, `password_operations_total{action="reset",result="password.reset"} 1`, ``},
+		{"InvalidCode", "alice", "000000", nil, `password_operations_total{action="reset",result="password.invalid"} 1`, `password_code_failures_total{action="reset",reason="password.invalid"} 1`},
+		// the unknown user fails with the same code, but no passcode was checked
+		{"UnknownUser", "bob", "123456", nil, `password_operations_total{action="reset",result="password.invalid"} 1`, ``},
+		{"RepositoryError", "alice", "123456", errors.New("connection refused"), `password_operations_total{action="reset",result="error"} 1`, ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := NewMetrics()
+			service := NewPasswordService(newUseCase(tt.err, NewTextComparator(plain{}, metrics)), metrics)
+			if _, err := service.ResetPasswordWithResult(context.Background(), p.PasswordReset{Username: tt.username, Passcode: tt.passcode, Password: "p1"}); !errors.Is(err, tt.err) {
+				t.Fatalf("ResetPasswordWithResult() error = %v; want %v", err, tt.err)
+			}
+			want := "# HELP password_operations_total The number of the operations by action and result code.\n# TYPE password_operations_total counter\n" + tt.operations + "\n"
+			if err := testutil.CollectAndCompare(metrics.Operations, strings.NewReader(want)); err != nil {
+				t.Error(err)
+			}
+			if len(tt.failures) == 0 {
+				if n := testutil.CollectAndCount(metrics.CodeFailures); n != 0 {
+					t.Errorf("%d code failures; want none", n)
+				}
+			} else {
+				want := "# HELP password_code_failures_total The number of the invalid or expired passcodes by action and reason.\n# TYPE password_code_failures_total counter\n" + tt.failures + "\n"
+				if err := testutil.CollectAndCompare(metrics.CodeFailures, strings.NewReader(want)); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+}
+
+func TestMetricsRegistry(t *testing.T) {
+	registry := prom.NewRegistry()
+	metrics, err := NewRegisteredMetrics(registry, "auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewPasswordService(newUseCase(nil, NewTextComparator(plain{}, metrics)), metrics)
+	if _, err := service.ResetPasswordWithResult(context.Background(), p.PasswordReset{Username: "alice", Passcode: "123456", Password: "p1"}); err != nil {
+		t.Fatal(err)
+	}
+	NewAuditSink(metrics).Write(context.Background(), p.AuditEvent{Type: p.EventLockedOut})
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := make(map[string]uint64)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			if h := m.GetHistogram(); h != nil {
+				samples[family.GetName()] += h.GetSampleCount()
+			} else if c := m.GetCounter(); c != nil {
+				samples[family.GetName()] += uint64(c.GetValue())
+			}
+		}
+	}
+	// the code is compared, and the new password is hashed
+	want := map[string]uint64{"auth_operations_total": 1, "auth_hash_duration_seconds": 2, "auth_lockouts_total": 1}
+	for name, count := range want {
+		if samples[name] != count {
+			t.Errorf("%s = %d; want %d", name, samples[name], count)
+		}
+	}
+	if _, err := NewRegisteredMetrics(registry, "auth"); err == nil {
+		t.Error("NewRegisteredMetrics() twice = nil; want an error")
+	}
+}
+
+// TestStackedDecorators checks that both decorators count the failed code, when the prometheus decorator wraps the otel one.
+func TestStackedDecorators(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	instrumentation, err := po.NewInstrumentation(nil, sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := NewMetrics()
+	service := NewPasswordService(po.NewPasswordService(newUseCase(nil, plain{}), instrumentation), metrics)
+	if result, err := service.ResetPasswordWithResult(context.Background(), p.PasswordReset{Username: "alice", Passcode: "000000", Password: "p1"}); err != nil || result.Code != p.MessageInvalid {
+		t.Fatalf("ResetPasswordWithResult() = %+v, %v; want %s", result, err, p.MessageInvalid)
+	}
+	if got := testutil.ToFloat64(metrics.CodeFailures.WithLabelValues(ActionReset, p.MessageInvalid)); got != 1 {
+		t.Errorf("prometheus code failures = %v; want 1", got)
+	}
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	var failed int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == "password.code.failed" {
+				for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+					failed += dp.Value
+				}
+			}
+		}
+	}
+	if failed != 1 {
+		t.Errorf("otel password.code.failed = %d; want 1", failed)
+	}
+}
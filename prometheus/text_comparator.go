@@ -0,0 +1,30 @@
+package prometheus
+
+import (
+	"time"
+
+	p "github.com/core-go/password"
+)
+
+type TextComparator struct {
+	Comparator p.TextComparator
+	Metrics    *Metrics
+}
+
+func NewTextComparator(comparator p.TextComparator, metrics *Metrics) *TextComparator {
+	return &TextComparator{Comparator: comparator, Metrics: metrics}
+}
+
+func (c *TextComparator) Compare(plaintext string, hashed string) (bool, error) {
+	start := time.Now()
+	valid, err := c.Comparator.Compare(plaintext, hashed)
+	c.Metrics.HashDuration.WithLabelValues("compare").Observe(time.Since(start).Seconds())
+	return valid, err
+}
+
+func (c *TextComparator) Hash(plaintext string) (string, error) {
+	start := time.Now()
+	hashed, err := c.Comparator.Hash(plaintext)
+	c.Metrics.HashDuration.WithLabelValues("hash").Observe(time.Since(start).Seconds())
+	return hashed, err
+}
@@ -0,0 +1,51 @@
+package password
+
+import (
+	"context"
+	"sync"
+)
+
+type Publisher interface {
+	Publish(ctx context.Context, data []byte, attributes map[string]string) error
+}
+
+type PublishedMessage struct {
+	Data       []byte
+	Attributes map[string]string
+}
+
+// MemoryPublisher keeps the published messages in memory, for testing and local development.
+// When Error is set, Publish fails with it, to test the redelivery.
+type MemoryPublisher struct {
+	Error    error
+	mutex    sync.Mutex
+	messages []PublishedMessage
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, data []byte, attributes map[string]string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.Error != nil {
+		return p.Error
+	}
+	p.messages = append(p.messages, PublishedMessage{Data: data, Attributes: attributes})
+	return nil
+}
+
+func (p *MemoryPublisher) Messages() []PublishedMessage {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	messages := make([]PublishedMessage, len(p.messages))
+	copy(messages, p.messages)
+	return messages
+}
+
+func (p *MemoryPublisher) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.messages = nil
+}
@@ -1,7 +1,9 @@
 package password
 
 import (
+	cr "crypto/rand"
 	"math"
+	"math/big"
 	"math/rand"
 	"strconv"
 )
@@ -23,3 +25,19 @@ func generate(length int) string {
 	max := int(math.Pow(float64(10), float64(length))) - 1
 	return padLeft(strconv.Itoa(rand.Intn(max)), length, "0")
 }
+
+const recoveryAlphabet = "23456789abcdefghjkmnpqrstuvwxyz"
+
+func generateRecoveryCode(length int) string {
+	b := make([]byte, length)
+	max := big.NewInt(int64(len(recoveryAlphabet)))
+	for i := range b {
+		n, err := cr.Int(cr.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		b[i] = recoveryAlphabet[n.Int64()]
+	}
+	half := length / 2
+	return string(b[:half]) + "-" + string(b[half:])
+}
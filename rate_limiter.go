@@ -0,0 +1,14 @@
+package password
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrTooManyRequests is returned when the RateLimiter does not allow the request.
+var ErrTooManyRequests = errors.New("too many requests")
+
+// RateLimiter returns false when the key has been used too many times in the current window.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string) (bool, error)
+}
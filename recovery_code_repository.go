@@ -0,0 +1,9 @@
+package password
+
+import "context"
+
+type RecoveryCodeRepository interface {
+	Save(ctx context.Context, id string, codes []string) (int64, error)
+	Load(ctx context.Context, id string) ([]string, error)
+	Delete(ctx context.Context, id string, code string) (int64, error)
+}
@@ -0,0 +1,9 @@
+package password
+
+import "context"
+
+type RecoveryCodeService interface {
+	Regenerate(ctx context.Context, id string) ([]string, error)
+	Count(ctx context.Context, id string) (int64, error)
+	Verify(ctx context.Context, id string, code string) (bool, error)
+}
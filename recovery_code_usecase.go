@@ -0,0 +1,97 @@
+package password
+
+import (
+	"context"
+	"strings"
+)
+
+type RecoveryCodeUseCase struct {
+	Comparator TextComparator
+	Repository RecoveryCodeRepository
+	Size       int
+	Generate   func() string
+}
+
+func NewRecoveryCodeService(comparator TextComparator, repository RecoveryCodeRepository, options ...int) *RecoveryCodeUseCase {
+	size := 10
+	if len(options) >= 1 && options[0] > 0 {
+		size = options[0]
+	}
+	return &RecoveryCodeUseCase{Comparator: comparator, Repository: repository, Size: size}
+}
+
+// Regenerate replaces all codes of the user, and returns the new codes in plain text; only their hashes are stored.
+func (s RecoveryCodeUseCase) Regenerate(ctx context.Context, id string) ([]string, error) {
+	codes := make([]string, s.Size)
+	hashes := make([]string, s.Size)
+	for i := 0; i < s.Size; i++ {
+		var code string
+		if s.Generate != nil {
+			code = s.Generate()
+		} else {
+			code = generateRecoveryCode(10)
+		}
+		hash, err := s.Comparator.Hash(normalizeRecoveryCode(code))
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hash
+	}
+	_, err := s.Repository.Save(ctx, id, hashes)
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s RecoveryCodeUseCase) Count(ctx context.Context, id string) (int64, error) {
+	codes, err := s.Repository.Load(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(codes)), nil
+}
+
+func (s RecoveryCodeUseCase) Verify(ctx context.Context, id string, code string) (bool, error) {
+	return useRecoveryCode(ctx, s.Comparator, s.Repository, id, code)
+}
+
+func findRecoveryCode(ctx context.Context, comparator TextComparator, repository RecoveryCodeRepository, id string, code string) (string, error) {
+	code = normalizeRecoveryCode(code)
+	if len(code) == 0 {
+		return "", nil
+	}
+	hashes, er1 := repository.Load(ctx, id)
+	if er1 != nil {
+		return "", er1
+	}
+	for _, hash := range hashes {
+		valid, er2 := comparator.Compare(code, hash)
+		if er2 != nil {
+			return "", er2
+		}
+		if valid {
+			return hash, nil
+		}
+	}
+	return "", nil
+}
+
+func useRecoveryCode(ctx context.Context, comparator TextComparator, repository RecoveryCodeRepository, id string, code string) (bool, error) {
+	hash, er1 := findRecoveryCode(ctx, comparator, repository, id, code)
+	if er1 != nil || len(hash) == 0 {
+		return false, er1
+	}
+	count, er2 := repository.Delete(ctx, id, hash)
+	if er2 != nil {
+		return false, er2
+	}
+	return count > 0, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.Replace(code, "-", "", -1)
+	return strings.Replace(code, " ", "", -1)
+}
@@ -0,0 +1,153 @@
+package password_test
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	p "github.com/core-go/password"
+)
+
+// recoveryCodes is a RecoveryCodeRepository in a map.
+type recoveryCodes struct {
+	mutex sync.Mutex
+	codes map[string][]string
+}
+
+func (r *recoveryCodes) Save(ctx context.Context, id string, codes []string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.codes[id] = append([]string(nil), codes...)
+	return int64(len(codes)), nil
+}
+func (r *recoveryCodes) Load(ctx context.Context, id string) ([]string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]string(nil), r.codes[id]...), nil
+}
+func (r *recoveryCodes) Delete(ctx context.Context, id string, code string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for i, c := range r.codes[id] {
+		if c == code {
+			r.codes[id] = append(r.codes[id][:i], r.codes[id][i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// sequence returns a Generate function of the codes with the prefix, numbered from 1.
+func sequence(prefix string) func() string {
+	i := 0
+	return func() string {
+		i++
+		return prefix + strconv.Itoa(i)
+	}
+}
+
+func TestRecoveryCodeRegenerate(t *testing.T) {
+	ctx := context.Background()
+	repository := &recoveryCodes{codes: make(map[string][]string)}
+	s := p.NewRecoveryCodeService(plain{}, repository, 3)
+	s.Generate = sequence("ABCD-EFG")
+	codes, err := s.Regenerate(ctx, "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ABCD-EFG1", "ABCD-EFG2", "ABCD-EFG3"}; !reflect.DeepEqual(codes, want) {
+		t.Errorf("Regenerate() = %v; want %v", codes, want)
+	}
+	// only the hashes of the normalized codes are stored
+	if want := []string{"h:abcdefg1", "h:abcdefg2", "h:abcdefg3"}; !reflect.DeepEqual(repository.codes["u1"], want) {
+		t.Errorf("stored = %v; want %v", repository.codes["u1"], want)
+	}
+	s.Generate = sequence("new")
+	if _, err := s.Regenerate(ctx, "u1"); err != nil {
+		t.Fatal(err)
+	}
+	if valid, _ := s.Verify(ctx, "u1", "ABCD-EFG1"); valid {
+		t.Error("Verify() of a replaced code = true; want false")
+	}
+	if count, err := s.Count(ctx, "u1"); count != 3 || err != nil {
+		t.Errorf("Count() = %d, %v; want 3", count, err)
+	}
+	if size := p.NewRecoveryCodeService(plain{}, repository).Size; size != 10 {
+		t.Errorf("Size = %d; want 10 by default", size)
+	}
+}
+
+func TestRecoveryCodeVerify(t *testing.T) {
+	tests := []struct {
+		name  string
+		code  string
+		valid bool
+		count int64
+	}{
+		{"Exact", "abcdefg1", true, 1},
+		{"Formatted", "ABCD-EFG1", true, 1},
+		{"Spaces", " abcd efg2 ", true, 1},
+		{"Wrong", "abcdefg9", false, 2},
+		{"Empty", "--", false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := &recoveryCodes{codes: map[string][]string{"u1": {"h:abcdefg1", "h:abcdefg2"}}}
+			s := p.NewRecoveryCodeService(plain{}, repository)
+			if valid, err := s.Verify(ctx, "u1", tt.code); valid != tt.valid || err != nil {
+				t.Fatalf("Verify(%q) = %v, %v; want %v", tt.code, valid, err, tt.valid)
+			}
+			if count, _ := s.Count(ctx, "u1"); count != tt.count {
+				t.Errorf("Count() = %d; want %d", count, tt.count)
+			}
+			// each code is used only once
+			if valid, _ := s.Verify(ctx, "u1", tt.code); valid {
+				t.Errorf("Verify(%q) again = true; want false", tt.code)
+			}
+		})
+	}
+}
+
+func TestResetPasswordWithRecoveryCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		passcode string
+		want     int32
+	}{
+		{"Valid", "ABCD-EFG1", 1},
+		{"Wrong", "abcdefg9", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := newUsers(user{id: "u1", username: "alice", email: "alice@example.com", password: "h:p0"})
+			recovery := &recoveryCodes{codes: map[string][]string{"u1": {"h:abcdefg1"}}}
+			s := p.PasswordUseCase{
+				PasswordComparator:      plain{},
+				PasswordRepository:      repository,
+				ResetPasscodeRepository: newCodes(),
+				RecoveryCodeRepository:  recovery,
+			}
+			reset := p.PasswordReset{Username: "alice", Passcode: tt.passcode, Password: "p1", Factor: p.FactorRecovery}
+			if status, err := s.ResetPassword(ctx, reset); status != tt.want || err != nil {
+				t.Fatalf("ResetPassword() = %d, %v; want %d", status, err, tt.want)
+			}
+			if changed := repository.password("u1") == "h:p1"; changed != (tt.want == 1) {
+				t.Errorf("password changed %v; want %v", changed, tt.want == 1)
+			}
+			if tt.want != 1 {
+				return
+			}
+			if codes, _ := recovery.Load(ctx, "u1"); len(codes) != 0 {
+				t.Errorf("recovery codes = %v; want the used code deleted", codes)
+			}
+			reset.Password = "p2"
+			if status, _ := s.ResetPassword(ctx, reset); status != 0 {
+				t.Errorf("ResetPassword() with a used code = %d; want 0", status)
+			}
+		})
+	}
+}
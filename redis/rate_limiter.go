@@ -0,0 +1,54 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript removes the requests which are out of the window, and adds the request if the limit is not reached.
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+if redis.call('ZCARD', KEYS[1]) >= limit then
+	return 0
+end
+redis.call('ZADD', KEYS[1], now, ARGV[4])
+redis.call('PEXPIRE', KEYS[1], window)
+return 1
+`)
+
+// RateLimiter allows at most Limit requests of a key in any Window, with a sorted set of the times of the requests.
+type RateLimiter struct {
+	Client redis.UniversalClient
+	Prefix string
+	Limit  int
+	Window time.Duration
+}
+
+func NewRateLimiter(client redis.UniversalClient, limit int, window time.Duration, options ...string) *RateLimiter {
+	prefix := "ratelimit:"
+	if len(options) > 0 && len(options[0]) > 0 {
+		prefix = options[0]
+	}
+	return &RateLimiter{Client: client, Prefix: prefix, Limit: limit, Window: window}
+}
+
+func (l *RateLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return false, err
+	}
+	now := time.Now().UnixMilli()
+	member := hex.EncodeToString(b)
+	allowed, err := slidingWindowScript.Run(ctx, l.Client, []string{l.Prefix + key}, now, l.Window.Milliseconds(), l.Limit, member).Int64()
+	if err != nil {
+		return false, err
+	}
+	return allowed == 1, nil
+}
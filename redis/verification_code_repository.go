@@ -0,0 +1,146 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	p "github.com/core-go/password"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	passcodeField  = "passcode"
+	expiredAtField = "expiredAt"
+	attemptsField  = "attempts"
+)
+
+// loadScript counts the attempt and returns the code, the expiry in milliseconds and the number of attempts.
+var loadScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+local attempts = redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+local values = redis.call('HMGET', KEYS[1], 'passcode', 'expiredAt')
+return {values[1], values[2], attempts}
+`)
+
+// consumeScript deletes the code only if it is still the given one.
+var consumeScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'passcode') == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// VerificationCodeRepository keeps each code in a hash, which Redis removes when the code expires.
+type VerificationCodeRepository struct {
+	Client redis.UniversalClient
+	Prefix string
+	// MaxAttempts is the number of times a code can be loaded for verification; when it is exceeded, Load returns ErrTooManyRequests until the code expires or is replaced. 0 means no limit.
+	MaxAttempts int64
+	TenantKey   string // Tenant Id from context; when it is set, the keys are prefixed by the tenant, such as "passcode:tenant#userid"
+}
+
+func NewDefaultVerificationCodeRepository(client redis.UniversalClient, prefix string) *VerificationCodeRepository {
+	return NewVerificationCodeRepository(client, prefix, 0)
+}
+
+func NewVerificationCodeRepository(client redis.UniversalClient, prefix string, maxAttempts int64) *VerificationCodeRepository {
+	if len(prefix) == 0 {
+		prefix = "passcode:"
+	}
+	return &VerificationCodeRepository{Client: client, Prefix: prefix, MaxAttempts: maxAttempts}
+}
+
+// key returns the key of the code in the tenant of the context. It returns ErrInvalidTenant when the tenant is not valid, so that a tenant cannot use the keys of another one.
+func (r *VerificationCodeRepository) key(ctx context.Context, id string) (string, error) {
+	if len(r.TenantKey) == 0 {
+		return r.Prefix + id, nil
+	}
+	tenant, err := p.GetTenant(ctx, r.TenantKey)
+	if err != nil {
+		return "", err
+	}
+	return r.Prefix + tenant + "#" + id, nil
+}
+
+// Save replaces the code and resets the attempts.
+func (r *VerificationCodeRepository) Save(ctx context.Context, id string, passcode string, expireAt time.Time) (int64, error) {
+	key, err := r.key(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	_, err = r.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, passcodeField, passcode, expiredAtField, expireAt.UnixMilli(), attemptsField, 0)
+		pipe.PExpireAt(ctx, key, expireAt)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// Load counts the attempt, so that a code cannot be guessed by many concurrent requests before it is deleted.
+// It returns ErrTooManyRequests when the attempts exceed MaxAttempts, so that the use case does not answer that the code is expired.
+func (r *VerificationCodeRepository) Load(ctx context.Context, id string) (string, time.Time, error) {
+	key, err := r.key(ctx, id)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	values, err := loadScript.Run(ctx, r.Client, []string{key}).Slice()
+	if err != nil {
+		if err == redis.Nil {
+			return "", time.Time{}, nil
+		}
+		return "", time.Time{}, err
+	}
+	if len(values) < 3 {
+		return "", time.Time{}, nil
+	}
+	if attempts, ok := values[2].(int64); ok && r.MaxAttempts > 0 && attempts > r.MaxAttempts {
+		return "", time.Time{}, p.ErrTooManyRequests
+	}
+	passcode, _ := values[0].(string)
+	var expiredAt time.Time
+	if s, ok := values[1].(string); ok {
+		ms, er1 := strconv.ParseInt(s, 10, 64)
+		if er1 != nil {
+			return "", time.Time{}, er1
+		}
+		expiredAt = time.UnixMilli(ms)
+	}
+	return passcode, expiredAt, nil
+}
+
+// Attempts returns the number of times the code has been loaded.
+func (r *VerificationCodeRepository) Attempts(ctx context.Context, id string) (int64, error) {
+	key, err := r.key(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	attempts, err := r.Client.HGet(ctx, key, attemptsField).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return attempts, err
+}
+
+func (r *VerificationCodeRepository) Delete(ctx context.Context, id string) (int64, error) {
+	key, err := r.key(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	return r.Client.Del(ctx, key).Result()
+}
+
+// Consume deletes the code only if it is still the given one, so that a code can be used only once.
+func (r *VerificationCodeRepository) Consume(ctx context.Context, id string, passcode string) (int64, error) {
+	key, err := r.key(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	return consumeScript.Run(ctx, r.Client, []string{key}, passcode).Int64()
+}
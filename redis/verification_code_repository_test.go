@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	p "github.com/core-go/password"
+	"github.com/core-go/password/testkit"
+	"github.com/redis/go-redis/v9"
+)
+
+func newRepository(t *testing.T, maxAttempts int64) (*miniredis.Miniredis, *VerificationCodeRepository) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return server, NewVerificationCodeRepository(client, "", maxAttempts)
+}
+
+func TestVerificationCodeRepositoryContract(t *testing.T) {
+	testkit.RunVerificationCodeRepositoryContract(t, func(t *testing.T) p.VerificationCodeRepository {
+		_, r := newRepository(t, 0)
+		return r
+	})
+}
+
+func TestVerificationCodeRepositoryTTL(t *testing.T) {
+	server, r := newRepository(t, 0)
+	ctx := context.Background()
+	if _, err := r.Save(ctx, "u1", "c1", time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := server.TTL("passcode:u1"); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("TTL = %v, want at most a minute", ttl)
+	}
+	server.FastForward(2 * time.Minute)
+	code, _, err := r.Load(ctx, "u1")
+	if err != nil || len(code) > 0 {
+		t.Errorf("Load(u1) after the expiry = %q, %v; want no code", code, err)
+	}
+}
+
+func TestVerificationCodeRepositoryMaxAttempts(t *testing.T) {
+	_, r := newRepository(t, 2)
+	ctx := context.Background()
+	if _, err := r.Save(ctx, "u1", "c1", time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if code, _, err := r.Load(ctx, "u1"); err != nil || code != "c1" {
+			t.Fatalf("Load(u1) #%d = %q, %v", i+1, code, err)
+		}
+	}
+	if _, _, err := r.Load(ctx, "u1"); !errors.Is(err, p.ErrTooManyRequests) {
+		t.Errorf("Load(u1) after the max attempts = %v; want ErrTooManyRequests", err)
+	}
+	if attempts, err := r.Attempts(ctx, "u1"); err != nil || attempts != 3 {
+		t.Errorf("Attempts(u1) = %d, %v; want 3", attempts, err)
+	}
+	if _, err := r.Save(ctx, "u1", "c2", time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, err := r.Load(ctx, "u1"); err != nil || code != "c2" {
+		t.Errorf("Load(u1) after Save = %q, %v; want the attempts to be reset", code, err)
+	}
+}
+
+func TestVerificationCodeRepositoryConsume(t *testing.T) {
+	_, r := newRepository(t, 0)
+	ctx := context.Background()
+	if _, err := r.Save(ctx, "u1", "c1", time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := r.Consume(ctx, "u1", "c0"); err != nil || count != 0 {
+		t.Errorf("Consume(u1, c0) = %d, %v; want 0 for another code", count, err)
+	}
+	if count, err := r.Consume(ctx, "u1", "c1"); err != nil || count != 1 {
+		t.Errorf("Consume(u1, c1) = %d, %v; want 1", count, err)
+	}
+	if count, err := r.Consume(ctx, "u1", "c1"); err != nil || count != 0 {
+		t.Errorf("Consume(u1, c1) again = %d, %v; want 0, so that the code is used only once", count, err)
+	}
+}
+
+func TestVerificationCodeRepositoryTenant(t *testing.T) {
+	server, r := newRepository(t, 0)
+	r.TenantKey = "tenant"
+	acme := context.WithValue(context.Background(), "tenant", "acme")
+	other := context.WithValue(context.Background(), "tenant", "other")
+	if _, err := r.Save(acme, "u1", "c1", time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if !server.Exists("passcode:acme#u1") {
+		t.Errorf("keys = %v; want passcode:acme#u1", server.Keys())
+	}
+	if code, _, err := r.Load(other, "u1"); err != nil || len(code) > 0 {
+		t.Errorf("Load(u1) of another tenant = %q, %v; want no code", code, err)
+	}
+	if count, err := r.Consume(other, "u1", "c1"); err != nil || count != 0 {
+		t.Errorf("Consume(u1, c1) of another tenant = %d, %v; want 0", count, err)
+	}
+	if code, _, err := r.Load(acme, "u1"); err != nil || code != "c1" {
+		t.Errorf("Load(u1) = %q, %v; want c1", code, err)
+	}
+	invalid := context.WithValue(context.Background(), "tenant", "acme:u1")
+	if _, err := r.Save(invalid, "u1", "c2", time.Now().Add(time.Minute)); !errors.Is(err, p.ErrInvalidTenant) {
+		t.Errorf("Save with the tenant acme:u1 = %v; want ErrInvalidTenant", err)
+	}
+}
@@ -0,0 +1,111 @@
+package password
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type clientKey struct{}
+
+type client struct {
+	ip        string
+	userAgent string
+}
+
+// BuildContext returns the context of the request for the service, with the locale of the Accept-Language header and the client of the request.
+func BuildContext(r *http.Request, trustedProxies ...netip.Prefix) context.Context {
+	ctx := WithLocale(r.Context(), ParseAcceptLanguage(r.Header.Get("Accept-Language")))
+	return WithClient(ctx, ClientIP(r, trustedProxies...), r.UserAgent())
+}
+
+func WithClient(ctx context.Context, ip string, userAgent string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client{ip: ip, userAgent: userAgent})
+}
+
+// GetClient returns the IP address and the user agent of the client.
+func GetClient(ctx context.Context) (string, string) {
+	if c, ok := ctx.Value(clientKey{}).(client); ok {
+		return c.ip, c.userAgent
+	}
+	return "", ""
+}
+
+// ClientIP returns the remote address of the request. The X-Forwarded-For and X-Real-Ip headers are used only when the remote address is one of the trusted proxies.
+func ClientIP(r *http.Request, trustedProxies ...netip.Prefix) string {
+	return ForwardedIP(r.RemoteAddr, strings.Join(r.Header.Values("X-Forwarded-For"), ","), r.Header.Get("X-Real-Ip"), trustedProxies)
+}
+
+// ForwardedIP returns the address of the client of the remote address, which is a host or a host and a port.
+// When the remote address is one of the trusted proxies, it returns the right-most address of forwardedFor which is not a trusted proxy, or realIP if there is no forwardedFor.
+func ForwardedIP(remoteAddr string, forwardedFor string, realIP string, trustedProxies []netip.Prefix) string {
+	ip := remoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		ip = host
+	}
+	if !trusted(ip, trustedProxies) {
+		return ip
+	}
+	if len(strings.TrimSpace(forwardedFor)) == 0 {
+		if addr, err := netip.ParseAddr(strings.TrimSpace(realIP)); err == nil {
+			return addr.String()
+		}
+		return ip
+	}
+	hops := strings.Split(forwardedFor, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return ip
+		}
+		ip = addr.String()
+		if !trusted(ip, trustedProxies) {
+			return ip
+		}
+	}
+	return ip
+}
+
+// ParseTrustedProxies parses the CIDRs or the addresses of the trusted proxies, such as "10.0.0.0/8" or "127.0.0.1".
+func ParseTrustedProxies(proxies ...string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0)
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if len(proxy) == 0 {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func trusted(ip string, trustedProxies []netip.Prefix) bool {
+	if len(trustedProxies) == 0 {
+		return false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	p "github.com/core-go/password"
+)
+
+type AuditSink struct {
+	Database      *sql.DB
+	TableName     string
+	TypeName      string
+	UserIdName    string
+	ActorName     string
+	ClientIPName  string
+	UserAgentName string
+	ReasonName    string
+	TimeName      string
+	BuildParam    func(int) string
+}
+
+func NewDefaultAuditSink(db *sql.DB, tableName string) *AuditSink {
+	return NewAuditSink(db, tableName, "", "", "", "", "", "", "")
+}
+
+func NewAuditSink(db *sql.DB, tableName, typeName, userIdName, actorName, clientIPName, userAgentName, reasonName, timeName string) *AuditSink {
+	if len(typeName) == 0 {
+		typeName = "type"
+	}
+	if len(userIdName) == 0 {
+		userIdName = "userid"
+	}
+	if len(actorName) == 0 {
+		actorName = "actor"
+	}
+	if len(clientIPName) == 0 {
+		clientIPName = "clientip"
+	}
+	if len(userAgentName) == 0 {
+		userAgentName = "useragent"
+	}
+	if len(reasonName) == 0 {
+		reasonName = "reason"
+	}
+	if len(timeName) == 0 {
+		timeName = "time"
+	}
+	return &AuditSink{
+		Database:      db,
+		TableName:     strings.ToLower(tableName),
+		TypeName:      strings.ToLower(typeName),
+		UserIdName:    strings.ToLower(userIdName),
+		ActorName:     strings.ToLower(actorName),
+		ClientIPName:  strings.ToLower(clientIPName),
+		UserAgentName: strings.ToLower(userAgentName),
+		ReasonName:    strings.ToLower(reasonName),
+		TimeName:      strings.ToLower(timeName),
+		BuildParam:    getBuild(db),
+	}
+}
+
+func (s *AuditSink) Write(ctx context.Context, event p.AuditEvent) error {
+	query := fmt.Sprintf("insert into %s (%s, %s, %s, %s, %s, %s, %s) values (%s, %s, %s, %s, %s, %s, %s)",
+		s.TableName, s.TypeName, s.UserIdName, s.ActorName, s.ClientIPName, s.UserAgentName, s.ReasonName, s.TimeName,
+		s.BuildParam(1), s.BuildParam(2), s.BuildParam(3), s.BuildParam(4), s.BuildParam(5), s.BuildParam(6), s.BuildParam(7))
+	_, err := s.Database.ExecContext(ctx, query, event.Type, event.UserId, event.Actor, event.ClientIP, event.UserAgent, event.Reason, event.Time)
+	return err
+}
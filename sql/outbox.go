@@ -0,0 +1,84 @@
+package sql
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	p "github.com/core-go/password"
+)
+
+// Outbox is the table of the events, which are written in the same transaction as the password, and published later by OutboxRelay.
+type Outbox struct {
+	TableName       string
+	IdName          string
+	TypeName        string
+	UserIdName      string
+	DataName        string
+	CreatedTimeName string
+	BuildParam      func(int) string
+	Driver          string // the type of the driver, for the paging clause of OutboxRelay
+}
+
+func NewDefaultOutbox(db *sql.DB, tableName string) *Outbox {
+	return NewOutbox(db, tableName, "", "", "", "", "")
+}
+
+func NewOutbox(db *sql.DB, tableName, idName, typeName, userIdName, dataName, createdTimeName string) *Outbox {
+	if len(idName) == 0 {
+		idName = "id"
+	}
+	if len(typeName) == 0 {
+		typeName = "type"
+	}
+	if len(userIdName) == 0 {
+		userIdName = "userid"
+	}
+	if len(dataName) == 0 {
+		dataName = "data"
+	}
+	if len(createdTimeName) == 0 {
+		createdTimeName = "createdtime"
+	}
+	return &Outbox{
+		TableName:       strings.ToLower(tableName),
+		IdName:          strings.ToLower(idName),
+		TypeName:        strings.ToLower(typeName),
+		UserIdName:      strings.ToLower(userIdName),
+		DataName:        strings.ToLower(dataName),
+		CreatedTimeName: strings.ToLower(createdTimeName),
+		BuildParam:      getBuild(db),
+		Driver:          getDriver(db),
+	}
+}
+
+// Insert writes the event in the transaction; the data of the event is the JSON of the AuditEvent.
+func (o *Outbox) Insert(ctx context.Context, tx *sql.Tx, eventType string, userId string, actor string) error {
+	id, err := generateId()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	data, err := json.Marshal(p.AuditEvent{Type: eventType, UserId: userId, Actor: actor, Time: now})
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("insert into %s (%s, %s, %s, %s, %s) values (%s, %s, %s, %s, %s)",
+		o.TableName, o.IdName, o.TypeName, o.UserIdName, o.DataName, o.CreatedTimeName,
+		o.BuildParam(1), o.BuildParam(2), o.BuildParam(3), o.BuildParam(4), o.BuildParam(5))
+	_, err = tx.ExecContext(ctx, query, id, eventType, userId, string(data), now)
+	return err
+}
+
+func generateId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,108 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	p "github.com/core-go/password"
+)
+
+// OutboxRelay publishes the events of the outbox, and deletes each event after it is published.
+// The delivery is at least once: an event is published again if the relay stops before deleting it, so the consumers must be idempotent.
+// Only one relay should run for an outbox table.
+type OutboxRelay struct {
+	Database  *sql.DB
+	Outbox    *Outbox
+	Publisher p.Publisher
+	BatchSize int
+	Interval  time.Duration
+}
+
+func NewOutboxRelay(db *sql.DB, outbox *Outbox, publisher p.Publisher, options ...time.Duration) *OutboxRelay {
+	interval := 5 * time.Second
+	if len(options) >= 1 && options[0] > 0 {
+		interval = options[0]
+	}
+	return &OutboxRelay{Database: db, Outbox: outbox, Publisher: publisher, BatchSize: 100, Interval: interval}
+}
+
+type outboxEvent struct {
+	id        string
+	eventType string
+	userId    string
+	data      []byte
+}
+
+// RelayOnce publishes the oldest events, in the order they were written, and returns the number of published events.
+// It stops at the first failure, so that the events are not published out of order.
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	o := r.Outbox
+	columns := fmt.Sprintf("%s, %s, %s, %s", o.IdName, o.TypeName, o.UserIdName, o.DataName)
+	query := buildSelectFirst(o.Driver, columns, o.TableName, o.CreatedTimeName, r.BatchSize)
+	rows, er1 := r.Database.QueryContext(ctx, query)
+	if er1 != nil {
+		return 0, er1
+	}
+	events := make([]outboxEvent, 0)
+	for rows.Next() {
+		var e outboxEvent
+		if er2 := rows.Scan(&e.id, &e.eventType, &e.userId, &e.data); er2 != nil {
+			rows.Close()
+			return 0, er2
+		}
+		events = append(events, e)
+	}
+	rows.Close()
+	if er3 := rows.Err(); er3 != nil {
+		return 0, er3
+	}
+	deleteQuery := fmt.Sprintf("delete from %s where %s = %s", o.TableName, o.IdName, o.BuildParam(1))
+	for i, e := range events {
+		attributes := map[string]string{"id": e.id, "type": e.eventType, "userId": e.userId}
+		if er4 := r.Publisher.Publish(ctx, e.data, attributes); er4 != nil {
+			return i, er4
+		}
+		if _, er5 := r.Database.ExecContext(ctx, deleteQuery, e.id); er5 != nil {
+			return i + 1, er5
+		}
+	}
+	return len(events), nil
+}
+
+// buildSelectFirst builds the query of the first rows in the order, with the paging clause of the driver: top for mssql, fetch first for oracle, and limit for the others.
+func buildSelectFirst(driver string, columns string, table string, orderBy string, n int) string {
+	switch driver {
+	case driverMssql:
+		return fmt.Sprintf("select top %d %s from %s order by %s", n, columns, table, orderBy)
+	case driverOracle:
+		return fmt.Sprintf("select %s from %s order by %s fetch first %d rows only", columns, table, orderBy, n)
+	default:
+		return fmt.Sprintf("select %s from %s order by %s limit %d", columns, table, orderBy, n)
+	}
+}
+
+// Run relays the events every interval until the context is done.
+func (r *OutboxRelay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		for {
+			count, err := r.RelayOnce(ctx)
+			if err != nil {
+				log.Println(err)
+				break
+			}
+			if count < r.BatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	p "github.com/core-go/password"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openOutbox(t *testing.T) (*sql.DB, *PasswordRepository) {
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "outbox.db")+"?_journal_mode=WAL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, stmt := range []string{
+		"create table users (userid varchar(40) primary key, username varchar(100), email varchar(100))",
+		"create table passwords (userid varchar(40) primary key, password varchar(255))",
+		"create table outbox (id varchar(40) primary key, type varchar(40), userid varchar(40), data text, createdtime timestamp)",
+		"insert into users values ('u1', 'alice', 'alice@example.com')",
+		"insert into passwords values ('u1', 'h0')",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := NewPasswordRepository(db, "users", "passwords", "", "userId", "userid", "password", "email", "username", "", "", "", "", "", 5, nil)
+	r.Outbox = NewDefaultOutbox(db, "outbox")
+	return db, r
+}
+
+func TestOutboxEventType(t *testing.T) {
+	db, r := openOutbox(t)
+	ctx := context.Background()
+	if _, err := r.UpdateWithCurrentPassword(ctx, "u1", "h0", "h1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.UpdateWithCurrentPassword(p.WithEventType(ctx, p.EventResetCompleted), "u1", "h1", "h2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Update(ctx, "u1", "h3"); err != nil {
+		t.Fatal(err)
+	}
+	publisher := p.NewMemoryPublisher()
+	relay := NewOutboxRelay(db, r.Outbox, publisher)
+	count, err := relay.RelayOnce(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Fatalf("RelayOnce() = %d, want 3", count)
+	}
+	types := []string{p.EventChangeCompleted, p.EventResetCompleted, p.EventResetCompleted}
+	for i, m := range publisher.Messages() {
+		if m.Attributes["type"] != types[i] || m.Attributes["userId"] != "u1" {
+			t.Errorf("message %d has the attributes %v, want the type %s", i, m.Attributes, types[i])
+		}
+		var event p.AuditEvent
+		if err := json.Unmarshal(m.Data, &event); err != nil || event.Type != types[i] {
+			t.Errorf("message %d has the data %s", i, m.Data)
+		}
+	}
+}
+
+func TestOutboxRelayRedelivers(t *testing.T) {
+	db, r := openOutbox(t)
+	ctx := context.Background()
+	if _, err := r.UpdateWithCurrentPassword(ctx, "u1", "h0", "h1"); err != nil {
+		t.Fatal(err)
+	}
+	publisher := p.NewMemoryPublisher()
+	publisher.Error = errors.New("unavailable")
+	relay := NewOutboxRelay(db, r.Outbox, publisher)
+	if count, err := relay.RelayOnce(ctx); err == nil || count != 0 {
+		t.Fatalf("RelayOnce() = %d, %v, want the error of the publisher", count, err)
+	}
+	publisher.Error = nil
+	if count, err := relay.RelayOnce(ctx); err != nil || count != 1 {
+		t.Fatalf("RelayOnce() = %d, %v, want 1", count, err)
+	}
+	if count, err := relay.RelayOnce(ctx); err != nil || count != 0 {
+		t.Fatalf("RelayOnce() = %d, %v, want 0 after the event is deleted", count, err)
+	}
+	if len(publisher.Messages()) != 1 {
+		t.Errorf("published %d messages, want 1", len(publisher.Messages()))
+	}
+}
+
+func TestBuildSelectFirst(t *testing.T) {
+	cases := map[string]string{
+		driverMssql:             "select top 10 id from outbox order by createdtime",
+		driverOracle:            "select id from outbox order by createdtime fetch first 10 rows only",
+		driverPostgres:          "select id from outbox order by createdtime limit 10",
+		"*sqlite3.SQLiteDriver": "select id from outbox order by createdtime limit 10",
+	}
+	for driver, want := range cases {
+		if got := buildSelectFirst(driver, "id", "outbox", "createdtime", 10); got != want {
+			t.Errorf("buildSelectFirst(%s) = %q, want %q", driver, got, want)
+		}
+	}
+}
@@ -19,6 +19,8 @@ type PasswordRepository struct {
 	PasswordTableName string
 	HistoryTableName  string
 	Key               string // User Id from context
+	TenantKey         string // Tenant Id from context
+	TenantName        string // the tenant column; when it is set, the rows are filtered by the tenant of the context
 	IdName            string
 	PasswordName      string
 	ToAddressName     string
@@ -29,6 +31,7 @@ type PasswordRepository struct {
 	HistoryName       string
 	TimestampName     string
 	Max               int
+	Outbox            *Outbox // if set, the event is written to the outbox in the same transaction as the password
 	BuildParam        func(int) string
 	ToArray           func(interface{}) interface {
 		driver.Valuer
@@ -89,23 +92,20 @@ func NewPasswordRepository(db *sql.DB, userTableName, passwordTableName, history
 }
 
 func (r *PasswordRepository) GetUserId(ctx context.Context, userName string) (string, error) {
-	var userId []string
-	query := fmt.Sprintf("select distinct `%s` from %s where %s = %s", r.IdName, r.UserTableName, r.Username, r.BuildParam(0))
-	rows, err := r.Database.Query(query, userName)
+	var userId string
+	query := fmt.Sprintf("select distinct %s from %s where %s = %s", r.IdName, r.UserTableName, r.Username, r.BuildParam(1))
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName, "", 2, r.BuildParam)
+	rows, err := r.Database.QueryContext(ctx, query+cond, append([]interface{}{userName}, tenant...)...)
 	if err != nil {
 		return "", err
 	}
 	defer rows.Close()
 	for rows.Next() {
 		if err1 := rows.Scan(&userId); err1 != nil {
-			return "", err
+			return "", err1
 		}
 	}
-	if err != nil {
-		return "", err
-	}
-
-	return userId[0], nil
+	return userId, rows.Err()
 }
 
 func (r *PasswordRepository) GetUser(ctx context.Context, userNameOrEmail string) (string, string, string, string, error) {
@@ -113,11 +113,11 @@ func (r *PasswordRepository) GetUser(ctx context.Context, userNameOrEmail string
 	var query string
 	query1 := `SELECT us.%s, us.%s, us.%s, au.%s
 					FROM %s AS us INNER JOIN %s AS au ON us.%s = au.%s
-					WHERE us.%s = %s or us.%s = %s`
+					WHERE (us.%s = %s or us.%s = %s)`
 
 	query2 := `SELECT us.%s, us.%s, us.%s, us.%s
 					FROM %s AS us
-					WHERE us.%s = %s or us.%s = %s`
+					WHERE (us.%s = %s or us.%s = %s)`
 	if r.PasswordTableName != r.UserTableName {
 		query = fmt.Sprintf(query1, r.IdName, r.Username, r.ToAddressName, r.PasswordName, r.UserTableName, r.PasswordTableName, r.IdName, r.IdName, r.Username,
 			r.BuildParam(1),
@@ -131,7 +131,8 @@ func (r *PasswordRepository) GetUser(ctx context.Context, userNameOrEmail string
 			r.BuildParam(2),
 		)
 	}
-	rows, err := r.Database.Query(query, userNameOrEmail, userNameOrEmail)
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName, "us.", 3, r.BuildParam)
+	rows, err := r.Database.QueryContext(ctx, query+cond, append([]interface{}{userNameOrEmail, userNameOrEmail}, tenant...)...)
 	if err != nil {
 		return "", "", "", "", err
 	}
@@ -206,9 +207,11 @@ func (r *PasswordRepository) Update(ctx context.Context, userId string, newPassw
 		}
 	}
 
+	r.setTenant(ctx, pass)
 	var count int
 	query := fmt.Sprintf("select count(*) from %s where %s = %s", r.PasswordTableName, r.IdName, r.BuildParam(1))
-	rows, err0 := r.Database.Query(query, userId)
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName, "", 2, r.BuildParam)
+	rows, err0 := r.Database.QueryContext(ctx, query+cond, append([]interface{}{userId}, tenant...)...)
 	if err0 != nil {
 		return 0, err0
 	}
@@ -219,17 +222,21 @@ func (r *PasswordRepository) Update(ctx context.Context, userId string, newPassw
 		}
 		break
 	}
-	tx, err1 := r.Database.Begin()
+	tx, err1 := r.Database.BeginTx(ctx, nil)
 	if err1 != nil {
 		return 0, err1
 	}
 	if count > 0 {
-		query, values := BuildSave(pass, r.PasswordTableName, userId, r.IdName, r.BuildParam)
-		result1, err3 := tx.Exec(query, values...)
+		query, values := r.buildSave(ctx, pass, r.PasswordTableName, userId)
+		result1, err3 := tx.ExecContext(ctx, query, values...)
 		if err3 != nil {
 			tx.Rollback()
 			return 0, err3
 		}
+		if err3 = r.writeOutbox(ctx, tx, p.GetEventType(ctx, p.EventResetCompleted), userId); err3 != nil {
+			tx.Rollback()
+			return 0, err3
+		}
 		if err4 := tx.Commit(); err4 != nil {
 			tx.Rollback()
 			return 0, err4
@@ -239,11 +246,15 @@ func (r *PasswordRepository) Update(ctx context.Context, userId string, newPassw
 	}
 
 	query1, values1 := BuildInsert(pass, r.PasswordTableName, r.BuildParam)
-	result2, err3 := tx.Exec(query1, values1...)
+	result2, err3 := tx.ExecContext(ctx, query1, values1...)
 	if err3 != nil {
 		tx.Rollback()
 		return 0, err3
 	}
+	if err3 = r.writeOutbox(ctx, tx, p.GetEventType(ctx, p.EventResetCompleted), userId); err3 != nil {
+		tx.Rollback()
+		return 0, err3
+	}
 	if err4 := tx.Commit(); err4 != nil {
 		tx.Rollback()
 		return 0, err4
@@ -269,9 +280,11 @@ func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, user
 			pass[r.ChangedByName] = userId
 		}
 	}
+	r.setTenant(ctx, pass)
 	var count int
 	query := fmt.Sprintf("select count(*) from %s where %s = %s", r.PasswordTableName, r.IdName, r.BuildParam(1))
-	rows, err0 := r.Database.Query(query, userId)
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName, "", 2, r.BuildParam)
+	rows, err0 := r.Database.QueryContext(ctx, query+cond, append([]interface{}{userId}, tenant...)...)
 	if err0 != nil {
 		return 0, err0
 	}
@@ -284,26 +297,27 @@ func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, user
 	}
 	if len(r.HistoryTableName) > 0 {
 		history := make(map[string]interface{})
+		// appended is true when the replaced password is a new row of the history table, and false when it is in the array of the existing row
+		appended := true
 		if r.ToArray != nil {
 			query = fmt.Sprintf("select %s from %s where %s = %s", r.HistoryName, r.HistoryTableName, r.IdName, r.BuildParam(1))
-			rows, err0 = r.Database.Query(query, userId)
+			rows, err0 = r.Database.QueryContext(ctx, query+cond, append([]interface{}{userId}, tenant...)...)
 			if err0 != nil {
 				return 0, err0
 			}
 			defer rows.Close()
-			historyPass := make([]string, r.Max)
+			historyPass := make([]string, 0)
 			for rows.Next() {
+				appended = false
 				if err0 = rows.Scan(r.ToArray(&historyPass)); err0 != nil {
 					return 0, err0
 				}
 			}
-			var end int
-			if len(historyPass) == r.Max {
-				end = len(historyPass) - 1
-			} else {
-				end = len(historyPass)
+			// the history keeps the replaced passwords, the latest first, without the new one
+			historyPass = append([]string{currentPassword}, historyPass...)
+			if r.Max > 0 && len(historyPass) > r.Max {
+				historyPass = historyPass[:r.Max]
 			}
-			historyPass = append([]string{newPassword}, historyPass[0:end]...)
 			if r.HistoryTableName == r.PasswordTableName {
 				pass[r.HistoryName] = r.ToArray(historyPass)
 			} else {
@@ -323,22 +337,22 @@ func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, user
 				if len(r.HistoryName) > 0 {
 					history[r.HistoryName] = currentPassword
 				}
-				if len(r.HistoryName) > 0 {
-					history[r.HistoryName] = currentPassword
+				if len(r.TimestampName) > 0 {
+					history[r.TimestampName] = time.Now()
 				}
 			}
 		}
 		var result1 sql.Result
 		if r.HistoryTableName == r.PasswordTableName {
 			if count > 0 {
-				query, values := BuildSave(pass, r.PasswordTableName, userId, r.IdName, r.BuildParam)
-				result1, err0 = r.Database.Exec(query, values...)
+				query, values := r.buildSave(ctx, pass, r.PasswordTableName, userId)
+				result1, err0 = r.exec(ctx, userId, query, values...)
 				if err0 != nil {
 					return 0, err0
 				}
 			} else {
 				query, values := BuildInsert(pass, r.PasswordTableName, r.BuildParam)
-				result1, err0 = r.Database.Exec(query, values...)
+				result1, err0 = r.exec(ctx, userId, query, values...)
 				if err0 != nil {
 					return 0, err0
 				}
@@ -349,41 +363,46 @@ func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, user
 			}
 			return r1, nil
 		} else {
-			tx, err1 := r.Database.Begin()
+			tx, err1 := r.Database.BeginTx(ctx, nil)
 			if err1 != nil {
 				return 0, err1
 			}
 			if count > 0 {
-				query, values := BuildSave(pass, r.PasswordTableName, userId, r.IdName, r.BuildParam)
-				result1, err0 = tx.Exec(query, values...)
+				query, values := r.buildSave(ctx, pass, r.PasswordTableName, userId)
+				result1, err0 = tx.ExecContext(ctx, query, values...)
 				if err0 != nil {
 					tx.Rollback()
 					return 0, err0
 				}
 			} else {
 				query, values := BuildInsert(pass, r.PasswordTableName, r.BuildParam)
-				result1, err0 = tx.Exec(query, values...)
+				result1, err0 = tx.ExecContext(ctx, query, values...)
 				if err0 != nil {
 					tx.Rollback()
 					return 0, err0
 				}
 			}
 			var result2 sql.Result
-			if len(history) <= 0 {
+			if appended {
+				r.setTenant(ctx, history)
 				query, value := BuildInsertHistory(r.HistoryTableName, history, r.BuildParam)
-				result2, err0 = tx.Exec(query, value...)
+				result2, err0 = tx.ExecContext(ctx, query, value...)
 				if err0 != nil {
 					tx.Rollback()
 					return 0, err0
 				}
 			} else {
-				query, value := BuildSave(history, r.HistoryTableName, userId, r.IdName, r.BuildParam)
-				result2, err0 = tx.Exec(query, value...)
+				query, value := r.buildSave(ctx, history, r.HistoryTableName, userId)
+				result2, err0 = tx.ExecContext(ctx, query, value...)
 				if err0 != nil {
 					tx.Rollback()
 					return 0, err0
 				}
 			}
+			if err0 = r.writeOutbox(ctx, tx, p.GetEventType(ctx, p.EventChangeCompleted), userId); err0 != nil {
+				tx.Rollback()
+				return 0, err0
+			}
 			if err6 := tx.Commit(); err6 != nil {
 				tx.Rollback()
 				return 0, err6
@@ -402,8 +421,8 @@ func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, user
 		}
 	} else {
 		if count > 0 {
-			query, values := BuildSave(pass, r.PasswordTableName, userId, r.IdName, r.BuildParam)
-			result0, err3 := r.Database.Exec(query, values...)
+			query, values := r.buildSave(ctx, pass, r.PasswordTableName, userId)
+			result0, err3 := r.exec(ctx, userId, query, values...)
 			if err3 != nil {
 				return 0, err3
 			}
@@ -414,7 +433,7 @@ func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, user
 			return r1, nil
 		} else {
 			query, values := BuildInsert(pass, r.PasswordTableName, r.BuildParam)
-			result0, err := r.Database.Exec(query, values...)
+			result0, err := r.exec(ctx, userId, query, values...)
 			if err != nil {
 				return 0, err
 			}
@@ -427,19 +446,71 @@ func (r *PasswordRepository) UpdateWithCurrentPassword(ctx context.Context, user
 	}
 }
 
+// exec executes the statement of the change of the password, in a transaction with the outbox event if the outbox is set;
+// the type of the event is the one of the context, ChangeCompleted by default.
+func (r *PasswordRepository) exec(ctx context.Context, userId string, query string, values ...interface{}) (sql.Result, error) {
+	if r.Outbox == nil {
+		return r.Database.ExecContext(ctx, query, values...)
+	}
+	tx, err := r.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	result, err := tx.ExecContext(ctx, query, values...)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err = r.writeOutbox(ctx, tx, p.GetEventType(ctx, p.EventChangeCompleted), userId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// setTenant sets the tenant of the context to the row, if the table is multi-tenant.
+func (r *PasswordRepository) setTenant(ctx context.Context, model map[string]interface{}) {
+	if len(r.TenantName) > 0 {
+		model[r.TenantName] = getString(ctx, r.TenantKey)
+	}
+}
+
+// buildSave builds the update of the row of the user, in the tenant of the context.
+func (r *PasswordRepository) buildSave(ctx context.Context, model map[string]interface{}, table string, userId string) (string, []interface{}) {
+	query, values := BuildSave(model, table, userId, r.IdName, r.BuildParam)
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName, "", len(values)+1, r.BuildParam)
+	return query + cond, append(values, tenant...)
+}
+
+func (r *PasswordRepository) writeOutbox(ctx context.Context, tx *sql.Tx, eventType string, userId string) error {
+	if r.Outbox == nil {
+		return nil
+	}
+	actor := getString(ctx, r.Key)
+	if len(actor) == 0 {
+		actor = userId
+	}
+	return r.Outbox.Insert(ctx, tx, eventType, userId, actor)
+}
+
 func (r *PasswordRepository) GetHistory(ctx context.Context, userId string, max int) ([]string, error) {
 	if len(r.HistoryTableName) > 0 {
-		history := make([]string, max)
+		history := make([]string, 0)
 		arr := make(map[string]interface{})
+		cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName, "", 2, r.BuildParam)
 		query := ""
-		if len(r.TimestampName) > 0 && r.ToArray == nil {
-			query = `SELECT %s FROM %s WHERE %s = %s ORDER BY %s desc LIMIT %d OFFSET 1`
-			query = fmt.Sprintf(query, r.HistoryName, r.HistoryTableName, r.IdName, r.BuildParam(1), r.TimestampName, max)
+		if len(r.TimestampName) > 0 && r.ToArray == nil && r.HistoryTableName != r.PasswordTableName {
+			// the rows are the replaced passwords, so the latest is the first one; the rows after max are skipped below, for all the drivers
+			query = `SELECT %s FROM %s WHERE %s = %s%s ORDER BY %s desc`
+			query = fmt.Sprintf(query, r.HistoryName, r.HistoryTableName, r.IdName, r.BuildParam(1), cond, r.TimestampName)
 		} else {
-			query = `SELECT %s FROM %s WHERE %s = %s`
-			query = fmt.Sprintf(query, r.HistoryName, r.HistoryTableName, r.IdName, r.BuildParam(1))
+			query = `SELECT %s FROM %s WHERE %s = %s%s`
+			query = fmt.Sprintf(query, r.HistoryName, r.HistoryTableName, r.IdName, r.BuildParam(1), cond)
 		}
-		rows, err := r.Database.Query(query, userId)
+		rows, err := r.Database.QueryContext(ctx, query, append([]interface{}{userId}, tenant...)...)
 		if err != nil {
 			return history, err
 		}
@@ -451,9 +522,6 @@ func (r *PasswordRepository) GetHistory(ctx context.Context, userId string, max
 				if err1 := rows.Scan(r.ToArray(&history)); err1 != nil {
 					return history, err1
 				}
-				for len(history) > r.Max {
-					history = history[1:]
-				}
 			} else {
 				columns := make([]interface{}, len(cols))
 				columnPointers := make([]interface{}, len(cols))
@@ -477,10 +545,18 @@ func (r *PasswordRepository) GetHistory(ctx context.Context, userId string, max
 				if len(arr) == 0 {
 					return history, nil
 				}
-				history = append(history, string(arr[r.PasswordName].([]byte)))
+				switch v := arr[r.HistoryName].(type) {
+				case []byte:
+					history = append(history, string(v))
+				case string:
+					history = append(history, v)
+				}
 			}
 		}
-		return history, nil
+		if max > 0 && len(history) > max {
+			history = history[:max]
+		}
+		return history, rows.Err()
 	} else {
 		return []string{}, nil
 	}
@@ -563,14 +639,23 @@ func buildMsSqlParam(i int) string {
 func buildDollarParam(i int) string {
 	return "$" + strconv.Itoa(i)
 }
+
+const (
+	driverPostgres = "*pq.Driver"
+	driverOracle   = "*godror.drv"
+	driverMssql    = "*mssql.Driver"
+)
+
+func getDriver(db *sql.DB) string {
+	return reflect.TypeOf(db.Driver()).String()
+}
 func getBuild(db *sql.DB) func(i int) string {
-	driver := reflect.TypeOf(db.Driver()).String()
-	switch driver {
-	case "*pq.Driver":
+	switch getDriver(db) {
+	case driverPostgres:
 		return buildDollarParam
-	case "*godror.drv":
+	case driverOracle:
 		return buildOracleParam
-	case "*mssql.Driver":
+	case driverMssql:
 		return buildMsSqlParam
 	default:
 		return buildParam
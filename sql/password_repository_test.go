@@ -0,0 +1,121 @@
+package sql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/testkit"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openSqlite(t *testing.T, stmts ...string) *sql.DB {
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "password.db")+"?_journal_mode=WAL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func seed(t *testing.T, db *sql.DB, users []testkit.User) {
+	for _, u := range users {
+		if _, err := db.Exec("insert into users (userid, username, email) values (?, ?, ?)", u.Id, u.Username, u.Email); err != nil {
+			t.Fatal(err)
+		}
+		if len(u.Password) > 0 {
+			if _, err := db.Exec("insert into passwords (userid, password, failcount) values (?, ?, ?)", u.Id, u.Password, u.FailCount); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func inspect(t *testing.T, repository p.PasswordRepository, id string) (string, int) {
+	var changedBy string
+	var failCount int
+	if err := repository.(*PasswordRepository).Database.QueryRow("select changedby, failcount from passwords where userid = ?", id).Scan(&changedBy, &failCount); err != nil {
+		t.Fatal(err)
+	}
+	return changedBy, failCount
+}
+
+const (
+	createUsers     = "create table users (userid varchar(40) primary key, username varchar(100), email varchar(100))"
+	createPasswords = "create table passwords (userid varchar(40) primary key, password varchar(255), failcount integer, changedby varchar(40), changedtime timestamp, history text)"
+)
+
+func TestPasswordRepositoryContract(t *testing.T) {
+	testkit.RunPasswordRepositoryContract(t, testkit.PasswordRepositoryContract{
+		Key: "userId",
+		New: func(t *testing.T, users []testkit.User) p.PasswordRepository {
+			db := openSqlite(t, createUsers, createPasswords, "create table histories (userid varchar(40), history varchar(255), timestamp timestamp)")
+			seed(t, db, users)
+			return NewPasswordRepository(db, "users", "passwords", "histories", "userId", "userid", "password", "email", "username", "changedtime", "failcount", "changedby", "history", "timestamp", 5, nil)
+		},
+		Inspect: inspect,
+	})
+}
+
+func TestPasswordRepositoryContractWithArray(t *testing.T) {
+	testkit.RunPasswordRepositoryContract(t, testkit.PasswordRepositoryContract{
+		Key: "userId",
+		New: func(t *testing.T, users []testkit.User) p.PasswordRepository {
+			db := openSqlite(t, createUsers, createPasswords)
+			seed(t, db, users)
+			return NewPasswordRepository(db, "users", "passwords", "passwords", "userId", "userid", "password", "email", "username", "changedtime", "failcount", "changedby", "history", "", 5, toJSONArray)
+		},
+		Inspect: inspect,
+	})
+}
+
+func TestVerificationCodeRepositoryContract(t *testing.T) {
+	testkit.RunVerificationCodeRepositoryContract(t, func(t *testing.T) p.VerificationCodeRepository {
+		db := openSqlite(t, "create table codes (id varchar(40) primary key, passcode varchar(255), expiredat timestamp)")
+		return NewDefaultVerificationCodeRepository(db, "codes")
+	})
+}
+
+// jsonArray keeps the array of the history in a text column, like the array types of postgres.
+type jsonArray struct {
+	passwords *[]string
+}
+
+func toJSONArray(v interface{}) interface {
+	driver.Valuer
+	sql.Scanner
+} {
+	switch a := v.(type) {
+	case *[]string:
+		return jsonArray{passwords: a}
+	case []string:
+		return jsonArray{passwords: &a}
+	}
+	return jsonArray{}
+}
+
+func (a jsonArray) Value() (driver.Value, error) {
+	b, err := json.Marshal(*a.passwords)
+	return string(b), err
+}
+
+func (a jsonArray) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), a.passwords)
+	case []byte:
+		return json.Unmarshal(v, a.passwords)
+	}
+	return errors.New("the history is not a JSON array")
+}
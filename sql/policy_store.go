@@ -0,0 +1,93 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	p "github.com/core-go/password"
+)
+
+// PolicyStore loads the policies from a table; the expressions are kept in a column as a JSON array.
+type PolicyStore struct {
+	Database           *sql.DB
+	TableName          string
+	IdName             string
+	TenantName         string
+	RoleName           string
+	GroupName          string
+	PriorityName       string
+	ExpressionsName    string
+	DuplicateCountName string
+}
+
+func NewDefaultPolicyStore(db *sql.DB, tableName string) *PolicyStore {
+	return NewPolicyStore(db, tableName, "id", "tenant", "role", "groupname", "priority", "expressions", "duplicatecount")
+}
+
+func NewPolicyStore(db *sql.DB, tableName, idName, tenantName, roleName, groupName, priorityName, expressionsName, duplicateCountName string) *PolicyStore {
+	if len(idName) == 0 {
+		idName = "id"
+	}
+	if len(tenantName) == 0 {
+		tenantName = "tenant"
+	}
+	if len(roleName) == 0 {
+		roleName = "role"
+	}
+	if len(groupName) == 0 {
+		groupName = "groupname"
+	}
+	if len(priorityName) == 0 {
+		priorityName = "priority"
+	}
+	if len(expressionsName) == 0 {
+		expressionsName = "expressions"
+	}
+	if len(duplicateCountName) == 0 {
+		duplicateCountName = "duplicatecount"
+	}
+	return &PolicyStore{
+		Database:           db,
+		TableName:          strings.ToLower(tableName),
+		IdName:             strings.ToLower(idName),
+		TenantName:         strings.ToLower(tenantName),
+		RoleName:           strings.ToLower(roleName),
+		GroupName:          strings.ToLower(groupName),
+		PriorityName:       strings.ToLower(priorityName),
+		ExpressionsName:    strings.ToLower(expressionsName),
+		DuplicateCountName: strings.ToLower(duplicateCountName),
+	}
+}
+
+func (s *PolicyStore) Load(ctx context.Context) ([]p.Policy, error) {
+	policies := make([]p.Policy, 0)
+	query := fmt.Sprintf("select %s, %s, %s, %s, %s, %s, %s from %s", s.IdName, s.TenantName, s.RoleName, s.GroupName, s.PriorityName, s.ExpressionsName, s.DuplicateCountName, s.TableName)
+	rows, er1 := s.Database.QueryContext(ctx, query)
+	if er1 != nil {
+		return policies, er1
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var policy p.Policy
+		var tenant, role, group, expressions sql.NullString
+		var priority, duplicateCount sql.NullInt64
+		if er2 := rows.Scan(&policy.Id, &tenant, &role, &group, &priority, &expressions, &duplicateCount); er2 != nil {
+			return policies, er2
+		}
+		policy.Tenant = tenant.String
+		policy.Role = role.String
+		policy.Group = group.String
+		policy.Priority = int(priority.Int64)
+		policy.DuplicateCount = int(duplicateCount.Int64)
+		if len(expressions.String) > 0 {
+			if er3 := json.Unmarshal([]byte(expressions.String), &policy.Expressions); er3 != nil {
+				return policies, fmt.Errorf("invalid expressions of the policy %s: %v", policy.Id, er3)
+			}
+		}
+		policies = append(policies, policy)
+	}
+	return policies, rows.Err()
+}
@@ -0,0 +1,102 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+type RecoveryCodeRepository struct {
+	Database   *sql.DB
+	TableName  string
+	IdName     string
+	CodeName   string
+	TenantKey  string // Tenant Id from context
+	TenantName string // the tenant column; when it is set, the codes are kept by the tenant of the context
+	BuildParam func(int) string
+}
+
+func NewDefaultRecoveryCodeRepository(db *sql.DB, tableName string) *RecoveryCodeRepository {
+	return NewRecoveryCodeRepository(db, tableName, "userid", "code")
+}
+
+func NewRecoveryCodeRepository(db *sql.DB, tableName, idName, codeName string) *RecoveryCodeRepository {
+	if len(idName) == 0 {
+		idName = "userid"
+	}
+	if len(codeName) == 0 {
+		codeName = "code"
+	}
+	return &RecoveryCodeRepository{
+		Database:   db,
+		TableName:  strings.ToLower(tableName),
+		IdName:     strings.ToLower(idName),
+		CodeName:   strings.ToLower(codeName),
+		BuildParam: getBuild(db),
+	}
+}
+
+func (r *RecoveryCodeRepository) Save(ctx context.Context, id string, codes []string) (int64, error) {
+	tx, er0 := r.Database.BeginTx(ctx, nil)
+	if er0 != nil {
+		return 0, er0
+	}
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName, "", 2, r.BuildParam)
+	query := fmt.Sprintf("delete from %s where %s = %s", r.TableName, r.IdName, r.BuildParam(1))
+	if _, er1 := tx.ExecContext(ctx, query+cond, append([]interface{}{id}, tenant...)...); er1 != nil {
+		tx.Rollback()
+		return 0, er1
+	}
+	var count int64
+	insert := fmt.Sprintf("insert into %s (%s, %s) values (%s, %s)", r.TableName, r.IdName, r.CodeName, r.BuildParam(1), r.BuildParam(2))
+	if len(r.TenantName) > 0 {
+		insert = fmt.Sprintf("insert into %s (%s, %s, %s) values (%s, %s, %s)", r.TableName, r.IdName, r.CodeName, r.TenantName, r.BuildParam(1), r.BuildParam(2), r.BuildParam(3))
+	}
+	for _, code := range codes {
+		result, er2 := tx.ExecContext(ctx, insert, append([]interface{}{id, code}, tenant...)...)
+		if er2 != nil {
+			tx.Rollback()
+			return 0, er2
+		}
+		c, er3 := result.RowsAffected()
+		if er3 != nil {
+			tx.Rollback()
+			return 0, er3
+		}
+		count = count + c
+	}
+	if er4 := tx.Commit(); er4 != nil {
+		return 0, er4
+	}
+	return count, nil
+}
+
+func (r *RecoveryCodeRepository) Load(ctx context.Context, id string) ([]string, error) {
+	codes := make([]string, 0)
+	query := fmt.Sprintf("select %s from %s where %s = %s", r.CodeName, r.TableName, r.IdName, r.BuildParam(1))
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName, "", 2, r.BuildParam)
+	rows, er1 := r.Database.QueryContext(ctx, query+cond, append([]interface{}{id}, tenant...)...)
+	if er1 != nil {
+		return codes, er1
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var code string
+		if er2 := rows.Scan(&code); er2 != nil {
+			return codes, er2
+		}
+		codes = append(codes, code)
+	}
+	return codes, rows.Err()
+}
+
+func (r *RecoveryCodeRepository) Delete(ctx context.Context, id string, code string) (int64, error) {
+	query := fmt.Sprintf("delete from %s where %s = %s and %s = %s", r.TableName, r.IdName, r.BuildParam(1), r.CodeName, r.BuildParam(2))
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName, "", 3, r.BuildParam)
+	result, err := r.Database.ExecContext(ctx, query+cond, append([]interface{}{id, code}, tenant...)...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,15 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+)
+
+// tenantCondition returns the condition of the tenant column, with the parameter i, and the tenant of the context.
+// It returns an empty condition when tenantName is empty, which means the table is not multi-tenant.
+func tenantCondition(ctx context.Context, tenantKey string, tenantName string, alias string, i int, buildParam func(int) string) (string, []interface{}) {
+	if len(tenantName) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf(" and %s%s = %s", alias, tenantName, buildParam(i)), []interface{}{getString(ctx, tenantKey)}
+}
@@ -0,0 +1,106 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	p "github.com/core-go/password"
+)
+
+type VerificationCodeRepository struct {
+	Database      *sql.DB
+	TableName     string
+	IdName        string
+	PasscodeName  string
+	ExpiredAtName string
+	TenantKey     string // Tenant Id from context
+	TenantName    string // the tenant column; when it is set, the codes are kept by the tenant of the context
+	BuildParam    func(int) string
+}
+
+func NewVerificationCodeRepositoryByConfig(db *sql.DB, tableName string, c p.VerificationCodeSchemaConfig) *VerificationCodeRepository {
+	return NewVerificationCodeRepository(db, tableName, c.Id, c.Passcode, c.ExpiredAt)
+}
+
+func NewDefaultVerificationCodeRepository(db *sql.DB, tableName string) *VerificationCodeRepository {
+	return NewVerificationCodeRepository(db, tableName, "id", "passcode", "expiredat")
+}
+
+func NewVerificationCodeRepository(db *sql.DB, tableName, idName, passcodeName, expiredAtName string) *VerificationCodeRepository {
+	if len(idName) == 0 {
+		idName = "id"
+	}
+	if len(passcodeName) == 0 {
+		passcodeName = "passcode"
+	}
+	if len(expiredAtName) == 0 {
+		expiredAtName = "expiredat"
+	}
+	return &VerificationCodeRepository{
+		Database:      db,
+		TableName:     strings.ToLower(tableName),
+		IdName:        strings.ToLower(idName),
+		PasscodeName:  strings.ToLower(passcodeName),
+		ExpiredAtName: strings.ToLower(expiredAtName),
+		BuildParam:    getBuild(db),
+	}
+}
+
+// Save replaces the code of the id, by deleting and inserting in a transaction, which works for all the drivers.
+func (r *VerificationCodeRepository) Save(ctx context.Context, id string, passcode string, expireAt time.Time) (int64, error) {
+	tx, er0 := r.Database.BeginTx(ctx, nil)
+	if er0 != nil {
+		return 0, er0
+	}
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName, "", 2, r.BuildParam)
+	query := fmt.Sprintf("delete from %s where %s = %s", r.TableName, r.IdName, r.BuildParam(1))
+	if _, er1 := tx.ExecContext(ctx, query+cond, append([]interface{}{id}, tenant...)...); er1 != nil {
+		tx.Rollback()
+		return 0, er1
+	}
+	insert := fmt.Sprintf("insert into %s (%s, %s, %s) values (%s, %s, %s)", r.TableName, r.IdName, r.PasscodeName, r.ExpiredAtName, r.BuildParam(1), r.BuildParam(2), r.BuildParam(3))
+	values := []interface{}{id, passcode, expireAt}
+	if len(r.TenantName) > 0 {
+		insert = fmt.Sprintf("insert into %s (%s, %s, %s, %s) values (%s, %s, %s, %s)", r.TableName, r.IdName, r.PasscodeName, r.ExpiredAtName, r.TenantName, r.BuildParam(1), r.BuildParam(2), r.BuildParam(3), r.BuildParam(4))
+		values = append(values, tenant...)
+	}
+	result, er2 := tx.ExecContext(ctx, insert, values...)
+	if er2 != nil {
+		tx.Rollback()
+		return 0, er2
+	}
+	count, er3 := result.RowsAffected()
+	if er3 != nil {
+		tx.Rollback()
+		return 0, er3
+	}
+	if er4 := tx.Commit(); er4 != nil {
+		return 0, er4
+	}
+	return count, nil
+}
+
+func (r *VerificationCodeRepository) Load(ctx context.Context, id string) (string, time.Time, error) {
+	var passcode string
+	var expiredAt time.Time
+	query := fmt.Sprintf("select %s, %s from %s where %s = %s", r.PasscodeName, r.ExpiredAtName, r.TableName, r.IdName, r.BuildParam(1))
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName, "", 2, r.BuildParam)
+	err := r.Database.QueryRowContext(ctx, query+cond, append([]interface{}{id}, tenant...)...).Scan(&passcode, &expiredAt)
+	if err == sql.ErrNoRows {
+		return "", time.Time{}, nil
+	}
+	return passcode, expiredAt, err
+}
+
+func (r *VerificationCodeRepository) Delete(ctx context.Context, id string) (int64, error) {
+	query := fmt.Sprintf("delete from %s where %s = %s", r.TableName, r.IdName, r.BuildParam(1))
+	cond, tenant := tenantCondition(ctx, r.TenantKey, r.TenantName, "", 2, r.BuildParam)
+	result, err := r.Database.ExecContext(ctx, query+cond, append([]interface{}{id}, tenant...)...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,117 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const HeaderTenant = "X-Tenant-Id"
+
+// ErrInvalidTenant is returned when the tenant is not made of lowercase letters, digits, '_' and '-',
+// so that a tenant cannot escape its index, its key prefix or its rows in the repositories.
+var ErrInvalidTenant = errors.New("the tenant is not valid")
+
+var tenantPattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// ValidTenant returns true if the tenant is made of lowercase letters, digits, '_' and '-' only.
+func ValidTenant(tenant string) bool {
+	return tenantPattern.MatchString(tenant)
+}
+
+// TenantConfig holds the settings of a tenant, which override the ones of PasswordUseCase; the zero values keep the settings of PasswordUseCase.
+type TenantConfig struct {
+	Regexps               []regexp.Regexp
+	DuplicateCount        int
+	PasswordResetExpires  int
+	PasswordChangeExpires int
+	NoticeExpires         int
+	SendResetCode         func(ctx context.Context, to string, code string, expireAt time.Time, params interface{}) error
+	SendChangeCode        func(ctx context.Context, to string, code string, expireAt time.Time, params interface{}) error
+	Delivery              Deliverer
+	NoticeLink            func(ctx context.Context, username string, code string) string
+}
+
+// GetTenant returns the tenant of the context, which is put by WithTenant or by a middleware, with the key.
+// It returns ErrInvalidTenant if the tenant is not valid, which may happen when a middleware puts it.
+func GetTenant(ctx context.Context, key string) (string, error) {
+	tenant := getString(ctx, key)
+	if len(tenant) > 0 && !ValidTenant(tenant) {
+		return "", ErrInvalidTenant
+	}
+	return tenant, nil
+}
+
+// WithTenant adds the tenant of the request to the context with the key, when the key and the function are set.
+// It returns ErrInvalidTenant, and the context without the tenant, if the tenant of the request is not valid.
+func WithTenant(ctx context.Context, r *http.Request, key string, getTenant func(*http.Request) string) (context.Context, error) {
+	if len(key) == 0 || getTenant == nil {
+		return ctx, nil
+	}
+	tenant := getTenant(r)
+	if len(tenant) == 0 {
+		return ctx, nil
+	}
+	if !ValidTenant(tenant) {
+		return ctx, ErrInvalidTenant
+	}
+	return context.WithValue(ctx, key, tenant), nil
+}
+
+// TenantHeader returns the function which gets the tenant from the header of the request, such as X-Tenant-Id.
+// It should be used only behind a gateway which sets the header; otherwise, the tenant should be taken from the token of the user.
+func TenantHeader(header string) func(*http.Request) string {
+	return func(r *http.Request) string {
+		return r.Header.Get(header)
+	}
+}
+
+// tenant returns the use case with the settings of the tenant of the context, if any.
+func (s PasswordUseCase) tenant(ctx context.Context) PasswordUseCase {
+	if len(s.TenantKey) == 0 || len(s.Tenants) == 0 {
+		return s
+	}
+	c, ok := s.Tenants[getString(ctx, s.TenantKey)]
+	if !ok || c == nil {
+		return s
+	}
+	if c.Regexps != nil {
+		s.Regexps = c.Regexps
+	}
+	if c.DuplicateCount > 0 {
+		s.DuplicateCount = c.DuplicateCount
+	}
+	if c.PasswordResetExpires > 0 {
+		s.PasswordResetExpires = c.PasswordResetExpires
+	}
+	if c.PasswordChangeExpires > 0 {
+		s.PasswordChangeExpires = c.PasswordChangeExpires
+	}
+	if c.NoticeExpires > 0 {
+		s.NoticeExpires = c.NoticeExpires
+	}
+	if c.SendResetCode != nil {
+		s.SendResetCode = c.SendResetCode
+	}
+	if c.SendChangeCode != nil {
+		s.SendChangeCode = c.SendChangeCode
+	}
+	if c.Delivery != nil {
+		s.Delivery = c.Delivery
+	}
+	if c.NoticeLink != nil {
+		s.NoticeLink = c.NoticeLink
+	}
+	return s
+}
+
+// tenantKey returns the key in the tenant of the context, such as "tenant#key", so that the keys of the tenants do not collide, or the key itself when there is no tenant.
+func (s PasswordUseCase) tenantKey(ctx context.Context, key string) string {
+	tenant := getString(ctx, s.TenantKey)
+	if len(tenant) == 0 {
+		return key
+	}
+	return tenant + "#" + key
+}
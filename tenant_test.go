@@ -0,0 +1,123 @@
+package password_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	p "github.com/core-go/password"
+	"github.com/core-go/password/memory"
+)
+
+func TestValidTenant(t *testing.T) {
+	tests := []struct {
+		tenant string
+		want   bool
+	}{
+		{"acme", true},
+		{"acme-2_eu", true},
+		{"", false},
+		{"Acme", false},
+		{"acme#u1", false},
+		{"acme/../other", false},
+		{"acme u1", false},
+	}
+	for _, tt := range tests {
+		if got := p.ValidTenant(tt.tenant); got != tt.want {
+			t.Errorf("ValidTenant(%q) = %v; want %v", tt.tenant, got, tt.want)
+		}
+	}
+}
+
+func TestWithTenant(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+		err    error
+	}{
+		{"Valid", "acme", "acme", nil},
+		{"Missing", "", "", nil},
+		{"Invalid", "acme#other", "", p.ErrInvalidTenant},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/password/forgot", nil)
+			r.Header.Set(p.HeaderTenant, tt.header)
+			ctx, err := p.WithTenant(context.Background(), r, "tenant", p.TenantHeader(p.HeaderTenant))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("WithTenant() error = %v; want %v", err, tt.err)
+			}
+			if got, _ := p.GetTenant(ctx, "tenant"); got != tt.want {
+				t.Errorf("GetTenant() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+	if _, err := p.GetTenant(context.WithValue(context.Background(), "tenant", "Acme"), "tenant"); !errors.Is(err, p.ErrInvalidTenant) {
+		t.Errorf("GetTenant(Acme) = %v; want ErrInvalidTenant", err)
+	}
+}
+
+// keys records the keys of the rate limiter.
+type keys struct {
+	keys []string
+}
+
+func (k *keys) Allow(ctx context.Context, key string) (bool, error) {
+	k.keys = append(k.keys, key)
+	return true, nil
+}
+
+func TestTenantOverrides(t *testing.T) {
+	tests := []struct {
+		name   string
+		tenant string
+		// acme is true when the code is sent by the function of the tenant acme
+		acme bool
+		key  string
+	}{
+		{"TenantWithSettings", "acme", true, "forgot:acme#alice@example.com"},
+		{"TenantWithoutSettings", "other", false, "forgot:other#alice@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := memory.NewPasswordRepository("userId", 5,
+				memory.User{Id: "u1", Tenant: "acme", Username: "alice", Email: "alice@example.com", Password: "h:p0"},
+				memory.User{Id: "u1", Tenant: "other", Username: "alice", Email: "alice@example.com", Password: "h:p0"})
+			users.TenantKey = "tenant"
+			codes := memory.NewVerificationCodeRepository()
+			codes.TenantKey = "tenant"
+			var sent, acmeSent outbox
+			limiter := &keys{}
+			s := p.PasswordUseCase{
+				PasswordComparator:      plain{},
+				PasswordRepository:      users,
+				PasswordResetExpires:    60,
+				ResetPasscodeRepository: codes,
+				SendResetCode:           sent.send,
+				RateLimiter:             limiter,
+				TenantKey:               "tenant",
+				Tenants:                 map[string]*p.TenantConfig{"acme": {SendResetCode: acmeSent.send}},
+			}
+			ctx := context.WithValue(context.Background(), "tenant", tt.tenant)
+			ok, err := s.ForgotPassword(ctx, "alice@example.com")
+			if err != nil || !ok {
+				t.Fatalf("ForgotPassword() = %v, %v", ok, err)
+			}
+			if got := len(acmeSent.codes()) == 1 && len(sent.codes()) == 0; got != tt.acme {
+				t.Errorf("sent by the function of acme = %v; want %v", got, tt.acme)
+			}
+			if !reflect.DeepEqual(limiter.keys, []string{tt.key}) {
+				t.Errorf("rate limiter keys = %v; want [%s], so that the tenants do not share the limits", limiter.keys, tt.key)
+			}
+			if code, _, _ := codes.Load(ctx, "u1"); len(code) == 0 {
+				t.Errorf("no reset code of u1 in the tenant %s", tt.tenant)
+			}
+			if code, _, _ := codes.Load(context.Background(), "u1"); len(code) > 0 {
+				t.Errorf("reset code of u1 without a tenant = %q; want the code to be kept by tenant", code)
+			}
+		})
+	}
+}
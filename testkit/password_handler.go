@@ -0,0 +1,190 @@
+package testkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	p "github.com/core-go/password"
+)
+
+// Handle sends a request to the routes of a handler, and returns the status, the content type and the body of the response.
+type Handle func(t *testing.T, method string, path string, body string) (int, string, []byte)
+
+type HandlerContract struct {
+	// New returns the routes of the handler of the service with the options, registered by RegisterRoutes under "/password".
+	New func(t *testing.T, service p.PasswordService, options ...p.HandlerOption) Handle
+}
+
+// ServeHTTP returns the Handle of a net/http handler, such as a chi or gorilla/mux router, with httptest.
+func ServeHTTP(handler http.Handler) Handle {
+	return func(t *testing.T, method string, path string, body string) (int, string, []byte) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w.Code, w.Header().Get("Content-Type"), w.Body.Bytes()
+	}
+}
+
+// Send sends a request to the server by the client, for the adapters which are tested with a listener.
+func Send(t *testing.T, client *http.Client, method string, url string, body string) (int, string, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, res.Header.Get("Content-Type"), b
+}
+
+type handlerCase struct {
+	name   string
+	method string
+	path   string
+	body   string
+	// legacy is the status and the JSON body without WithProblem; the body is not checked when it is empty.
+	legacyStatus int
+	legacy       string
+	// problem is the status, and the kind of the problem with WithProblem, or the JSON body if kind is empty.
+	problemStatus int
+	kind          string
+	problem       string
+}
+
+var handlerCases = []handlerCase{
+	{"ChangeSucceeded", "POST", "/password/change", `{"username":"alice","currentPassword":"h0","password":"h1"}`, 200, `1`, 200, "", `{"status":1}`},
+	{"ChangeReused", "POST", "/password/change", `{"username":"alice","currentPassword":"h0","password":"reused"}`, 200, `-1`, 409, p.ProblemDuplicate, ""},
+	{"ChangeForbidden", "POST", "/password/change", `{"username":"bob","currentPassword":"h0","password":"h1"}`, 403, "", 403, p.ProblemForbidden, ""},
+	{"ChangeBadRequest", "POST", "/password/change", `{`, 400, "", 400, p.ProblemBadRequest, ""},
+	{"ForgotByPath", "GET", "/password/forgot/alice@example.com", "", 200, `true`, 200, "", `true`},
+	{"ForgotByObject", "POST", "/password/forgot", `{"contact":"alice@example.com"}`, 200, `true`, 200, "", `true`},
+	{"ForgotByString", "POST", "/password/forgot", `"alice@example.com"`, 200, `true`, 200, "", `true`},
+	{"ForgotUnknown", "POST", "/password/forgot", `unknown@example.com`, 200, `false`, 200, "", `false`},
+	{"ForgotTooManyRequests", "POST", "/password/forgot", `limited@example.com`, 200, `false`, 429, p.ProblemTooManyRequests, ""},
+	{"ResetSucceeded", "POST", "/password/reset", `{"username":"alice","passcode":"123456","password":"h1"}`, 200, `1`, 200, "", `{"status":1}`},
+	{"ResetExpired", "POST", "/password/reset", `{"username":"alice","passcode":"expired","password":"h1"}`, 200, `0`, 410, p.ProblemExpired, ""},
+	{"ResetInvalid", "POST", "/password/reset", `{"username":"alice","passcode":"000000","password":"h1"}`, 200, `0`, 401, p.ProblemInvalid, ""},
+	{"DenySucceeded", "POST", "/password/deny", `{"username":"alice","passcode":"token"}`, 200, `1`, 200, "", `{"status":1}`},
+	{"DenyInvalid", "POST", "/password/deny", `{"username":"alice","passcode":"other"}`, 200, `0`, 401, p.ProblemInvalid, ""},
+}
+
+// RunHandlerContract runs the cases which every adapter of HandlerCore must pass, with and without WithProblem:
+//   - ChangePassword, ResetPassword and DenyChange decode the body and answer the result of the service
+//   - ForgotPassword takes the contact from the path of a GET, or from the body of a POST
+//   - without WithProblem, the results are answered with 200 and the bare status, the errors of the service with 200 too, except ErrForbidden
+//   - with WithProblem, the failures are answered with their HTTP status and an application/problem+json body
+func RunHandlerContract(t *testing.T, c HandlerContract) {
+	for _, problem := range []bool{false, true} {
+		mode := "Legacy"
+		var options []p.HandlerOption
+		if problem {
+			mode = "Problem"
+			options = append(options, p.WithProblem())
+		}
+		for _, hc := range handlerCases {
+			hc := hc
+			t.Run(mode+"/"+hc.name, func(t *testing.T) {
+				handle := c.New(t, handlerService{}, options...)
+				status, contentType, body := handle(t, hc.method, hc.path, hc.body)
+				if !problem {
+					if status != hc.legacyStatus {
+						t.Fatalf("%s %s = %d %s; want %d", hc.method, hc.path, status, body, hc.legacyStatus)
+					}
+					if len(hc.legacy) > 0 {
+						expectJSON(t, body, hc.legacy)
+					}
+					return
+				}
+				if status != hc.problemStatus {
+					t.Fatalf("%s %s = %d %s; want %d", hc.method, hc.path, status, body, hc.problemStatus)
+				}
+				if len(hc.kind) == 0 {
+					expectJSON(t, body, hc.problem)
+					return
+				}
+				if !strings.HasPrefix(contentType, p.ContentTypeProblem) {
+					t.Errorf("content type = %q; want %q", contentType, p.ContentTypeProblem)
+				}
+				var pb p.Problem
+				if err := json.Unmarshal(body, &pb); err != nil {
+					t.Fatalf("cannot decode the problem %s: %v", body, err)
+				}
+				if pb.Type != "/problems/"+hc.kind || pb.Status != hc.problemStatus {
+					t.Errorf("problem = %+v; want the type /problems/%s and the status %d", pb, hc.kind, hc.problemStatus)
+				}
+			})
+		}
+	}
+}
+
+func expectJSON(t *testing.T, body []byte, want string) {
+	t.Helper()
+	var got, expected interface{}
+	if err := json.Unmarshal(bytes.TrimSpace(body), &got); err != nil {
+		t.Fatalf("cannot decode the body %q: %v", body, err)
+	}
+	if err := json.Unmarshal([]byte(want), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("body = %s; want %s", body, want)
+	}
+}
+
+// handlerService answers by the inputs of the cases: the user is alice, the reset code 123456 and the deny token "token".
+type handlerService struct{}
+
+func (s handlerService) ForgotPassword(ctx context.Context, contact string) (bool, error) {
+	if contact == "limited@example.com" {
+		return false, p.ErrTooManyRequests
+	}
+	return contact == "alice@example.com", nil
+}
+func (s handlerService) ResetPassword(ctx context.Context, pass p.PasswordReset) (int32, error) {
+	result, err := s.ResetPasswordWithResult(ctx, pass)
+	return result.Status, err
+}
+func (s handlerService) ChangePassword(ctx context.Context, pass p.PasswordChange) (int32, error) {
+	result, err := s.ChangePasswordWithResult(ctx, pass)
+	return result.Status, err
+}
+func (s handlerService) ChangePasswordWithResult(ctx context.Context, pass p.PasswordChange) (p.PasswordResult, error) {
+	if pass.Username != "alice" {
+		return p.PasswordResult{}, p.ErrForbidden
+	}
+	if pass.Password == "reused" {
+		return p.PasswordResult{Status: -1}, nil
+	}
+	return p.PasswordResult{Status: 1}, nil
+}
+func (s handlerService) ResetPasswordWithResult(ctx context.Context, pass p.PasswordReset) (p.PasswordResult, error) {
+	switch {
+	case pass.Username != "alice":
+		return p.PasswordResult{}, nil
+	case pass.Passcode == "expired":
+		return p.PasswordResult{Code: p.MessageExpired}, nil
+	case pass.Passcode == "123456":
+		return p.PasswordResult{Status: 1}, nil
+	default:
+		return p.PasswordResult{}, nil
+	}
+}
+func (s handlerService) DenyChange(ctx context.Context, deny p.PasswordDeny) (p.PasswordResult, error) {
+	if deny.Username == "alice" && deny.Passcode == "token" {
+		return p.PasswordResult{Status: 1}, nil
+	}
+	return p.PasswordResult{}, nil
+}
@@ -0,0 +1,181 @@
+package testkit
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	p "github.com/core-go/password"
+)
+
+// User is a user to seed. When Password is empty, the user has no password row yet.
+type User struct {
+	Id        string
+	Username  string
+	Email     string
+	Password  string
+	FailCount int
+}
+
+type PasswordRepositoryContract struct {
+	// New returns a repository with the users and no history. It is called once for each case.
+	New func(t *testing.T, users []User) p.PasswordRepository
+	// Key is the context key of the user id, which the repository uses for ChangedBy.
+	Key string
+	// Inspect returns ChangedBy and FailCount of the password row of the user.
+	// The cases of ChangedBy and FailCount are skipped when it is nil.
+	Inspect func(t *testing.T, repository p.PasswordRepository, id string) (string, int)
+}
+
+var users = []User{
+	{Id: "u1", Username: "alice", Email: "alice@example.com", Password: "h0", FailCount: 3},
+	{Id: "u2", Username: "bob", Email: "bob@example.com"},
+}
+
+// RunPasswordRepositoryContract runs the cases which every PasswordRepository must pass:
+//   - GetUser finds the user by username and by email, and returns an empty id and no error for a missing user
+//   - Update creates the password row if there is none, updates it otherwise, and resets FailCount
+//   - UpdateWithCurrentPassword keeps the replaced password in the history
+//   - GetHistory returns the previous passwords, without the current one, the latest first, at most max
+//   - ChangedBy is the user id of the context Key, or the id of the user whose password is changed
+func RunPasswordRepositoryContract(t *testing.T, c PasswordRepositoryContract) {
+	ctx := context.Background()
+	t.Run("GetUserByUsername", func(t *testing.T) {
+		r := c.New(t, users)
+		id, username, email, password, err := r.GetUser(ctx, "alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != "u1" || username != "alice" || email != "alice@example.com" || password != "h0" {
+			t.Errorf("GetUser(alice) = %q, %q, %q, %q", id, username, email, password)
+		}
+	})
+	t.Run("GetUserByEmail", func(t *testing.T) {
+		r := c.New(t, users)
+		id, username, _, password, err := r.GetUser(ctx, "alice@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != "u1" || username != "alice" || password != "h0" {
+			t.Errorf("GetUser(alice@example.com) = %q, %q, %q", id, username, password)
+		}
+	})
+	t.Run("GetUserMissing", func(t *testing.T) {
+		r := c.New(t, users)
+		id, _, _, _, err := r.GetUser(ctx, "nobody")
+		if err != nil || len(id) > 0 {
+			t.Errorf("GetUser(nobody) = %q, %v; want an empty id and no error", id, err)
+		}
+	})
+	t.Run("GetUserId", func(t *testing.T) {
+		r := c.New(t, users)
+		id, err := r.GetUserId(ctx, "bob")
+		if err != nil || id != "u2" {
+			t.Errorf("GetUserId(bob) = %q, %v", id, err)
+		}
+	})
+	t.Run("UpdateCreatesPassword", func(t *testing.T) {
+		r := c.New(t, users)
+		count, err := r.Update(ctx, "u2", "h1")
+		if err != nil || count <= 0 {
+			t.Fatalf("Update(u2) = %d, %v", count, err)
+		}
+		expectPassword(t, r, "bob", "h1")
+	})
+	t.Run("UpdateUpdatesPassword", func(t *testing.T) {
+		r := c.New(t, users)
+		count, err := r.Update(ctx, "u1", "h1")
+		if err != nil || count <= 0 {
+			t.Fatalf("Update(u1) = %d, %v", count, err)
+		}
+		expectPassword(t, r, "alice", "h1")
+	})
+	t.Run("UpdateWithCurrentPasswordAppendsHistory", func(t *testing.T) {
+		r := c.New(t, users)
+		change(t, r, "u1", "h0", "h1", "h2", "h3")
+		expectPassword(t, r, "alice", "h3")
+		history, err := r.GetHistory(ctx, "u1", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"h2", "h1", "h0"}; !reflect.DeepEqual(history, want) {
+			t.Errorf("GetHistory(u1, 10) = %v; want %v", history, want)
+		}
+	})
+	t.Run("GetHistoryMax", func(t *testing.T) {
+		r := c.New(t, users)
+		change(t, r, "u1", "h0", "h1", "h2", "h3")
+		history, err := r.GetHistory(ctx, "u1", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"h2", "h1"}; !reflect.DeepEqual(history, want) {
+			t.Errorf("GetHistory(u1, 2) = %v; want %v", history, want)
+		}
+	})
+	t.Run("GetHistoryEmpty", func(t *testing.T) {
+		r := c.New(t, users)
+		history, err := r.GetHistory(ctx, "u1", 5)
+		if err != nil || len(history) > 0 {
+			t.Errorf("GetHistory(u1, 5) = %v, %v; want no history", history, err)
+		}
+	})
+	t.Run("ChangedByFromContext", func(t *testing.T) {
+		if c.Inspect == nil || len(c.Key) == 0 {
+			t.Skip("no Inspect or Key")
+		}
+		r := c.New(t, users)
+		if _, err := r.Update(context.WithValue(ctx, c.Key, "admin"), "u1", "h1"); err != nil {
+			t.Fatal(err)
+		}
+		if changedBy, _ := c.Inspect(t, r, "u1"); changedBy != "admin" {
+			t.Errorf("ChangedBy = %q; want admin", changedBy)
+		}
+	})
+	t.Run("ChangedByUser", func(t *testing.T) {
+		if c.Inspect == nil {
+			t.Skip("no Inspect")
+		}
+		r := c.New(t, users)
+		if _, err := r.Update(ctx, "u1", "h1"); err != nil {
+			t.Fatal(err)
+		}
+		if changedBy, _ := c.Inspect(t, r, "u1"); changedBy != "u1" {
+			t.Errorf("ChangedBy = %q; want u1", changedBy)
+		}
+	})
+	t.Run("FailCountReset", func(t *testing.T) {
+		if c.Inspect == nil {
+			t.Skip("no Inspect")
+		}
+		r := c.New(t, users)
+		if _, err := r.UpdateWithCurrentPassword(ctx, "u1", "h0", "h1"); err != nil {
+			t.Fatal(err)
+		}
+		if _, failCount := c.Inspect(t, r, "u1"); failCount != 0 {
+			t.Errorf("FailCount = %d; want 0", failCount)
+		}
+	})
+}
+
+func expectPassword(t *testing.T, r p.PasswordRepository, username string, want string) {
+	t.Helper()
+	_, _, _, password, err := r.GetUser(context.Background(), username)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if password != want {
+		t.Errorf("password of %s = %q; want %q", username, password, want)
+	}
+}
+
+// change changes the password of the user from the first password to each of the next ones in turn.
+func change(t *testing.T, r p.PasswordRepository, id string, passwords ...string) {
+	t.Helper()
+	for i := 1; i < len(passwords); i++ {
+		count, err := r.UpdateWithCurrentPassword(context.Background(), id, passwords[i-1], passwords[i])
+		if err != nil || count <= 0 {
+			t.Fatalf("UpdateWithCurrentPassword(%s, %s) = %d, %v", id, passwords[i], count, err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package testkit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	p "github.com/core-go/password"
+)
+
+// RunVerificationCodeRepositoryContract runs the cases which every VerificationCodeRepository must pass.
+// The expiry is compared to the second, since some backends do not keep the nanoseconds.
+// newRepository is called once for each case, and must return an empty repository. Consume is checked when the repository is a CodeConsumer.
+func RunVerificationCodeRepositoryContract(t *testing.T, newRepository func(t *testing.T) p.VerificationCodeRepository) {
+	ctx := context.Background()
+	expireAt := time.Now().Add(5 * time.Minute)
+	t.Run("SaveAndLoad", func(t *testing.T) {
+		r := newRepository(t)
+		if count, err := r.Save(ctx, "u1", "c1", expireAt); err != nil || count <= 0 {
+			t.Fatalf("Save(u1) = %d, %v", count, err)
+		}
+		expectCode(t, r, "u1", "c1", expireAt)
+	})
+	t.Run("SaveReplaces", func(t *testing.T) {
+		r := newRepository(t)
+		if _, err := r.Save(ctx, "u1", "c1", expireAt); err != nil {
+			t.Fatal(err)
+		}
+		later := expireAt.Add(time.Minute)
+		if count, err := r.Save(ctx, "u1", "c2", later); err != nil || count <= 0 {
+			t.Fatalf("Save(u1) = %d, %v", count, err)
+		}
+		expectCode(t, r, "u1", "c2", later)
+	})
+	t.Run("LoadMissing", func(t *testing.T) {
+		r := newRepository(t)
+		code, _, err := r.Load(ctx, "nobody")
+		if err != nil || len(code) > 0 {
+			t.Errorf("Load(nobody) = %q, %v; want an empty code and no error", code, err)
+		}
+	})
+	t.Run("Delete", func(t *testing.T) {
+		r := newRepository(t)
+		if _, err := r.Save(ctx, "u1", "c1", expireAt); err != nil {
+			t.Fatal(err)
+		}
+		if count, err := r.Delete(ctx, "u1"); err != nil || count <= 0 {
+			t.Fatalf("Delete(u1) = %d, %v", count, err)
+		}
+		code, _, err := r.Load(ctx, "u1")
+		if err != nil || len(code) > 0 {
+			t.Errorf("Load(u1) after Delete = %q, %v", code, err)
+		}
+	})
+	t.Run("DeleteMissing", func(t *testing.T) {
+		r := newRepository(t)
+		if count, err := r.Delete(ctx, "nobody"); err != nil || count > 0 {
+			t.Errorf("Delete(nobody) = %d, %v; want 0 and no error", count, err)
+		}
+	})
+	t.Run("Consume", func(t *testing.T) {
+		r := newRepository(t)
+		consumer, ok := r.(p.CodeConsumer)
+		if !ok {
+			t.Skip("the repository is not a CodeConsumer")
+		}
+		if _, err := r.Save(ctx, "u1", "c1", expireAt); err != nil {
+			t.Fatal(err)
+		}
+		if count, err := consumer.Consume(ctx, "u1", "c2"); err != nil || count > 0 {
+			t.Fatalf("Consume(u1, c2) = %d, %v; want 0 for another code", count, err)
+		}
+		if count, err := consumer.Consume(ctx, "u1", "c1"); err != nil || count <= 0 {
+			t.Fatalf("Consume(u1, c1) = %d, %v", count, err)
+		}
+		if count, err := consumer.Consume(ctx, "u1", "c1"); err != nil || count > 0 {
+			t.Errorf("Consume(u1, c1) again = %d, %v; want 0, since a code can be used only once", count, err)
+		}
+	})
+}
+
+func expectCode(t *testing.T, r p.VerificationCodeRepository, id string, want string, wantExpireAt time.Time) {
+	t.Helper()
+	code, expireAt, err := r.Load(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != want {
+		t.Errorf("Load(%s) = %q; want %q", id, code, want)
+	}
+	if d := expireAt.Sub(wantExpireAt); d > time.Second || d < -time.Second {
+		t.Errorf("expiry of %s = %v; want %v", id, expireAt, wantExpireAt)
+	}
+}
@@ -10,3 +10,44 @@ type VerificationCodeRepository interface {
 	Load(ctx context.Context, id string) (string, time.Time, error)
 	Delete(ctx context.Context, id string) (int64, error)
 }
+
+// CodeConsumer is implemented by the VerificationCodeRepository which can delete the code only if it is still the given one, atomically.
+// When the repository implements it, a valid code is consumed before the password is changed, so that it cannot be used twice.
+type CodeConsumer interface {
+	Consume(ctx context.Context, id string, passcode string) (int64, error)
+}
+
+type codeCheckKey struct{}
+
+// CodeCheck records whether the service has checked a passcode, such as a reset code, a code of the second factor or a recovery code,
+// so that the metrics count the failed codes only, and not the wrong current passwords or the unknown users.
+type CodeCheck struct {
+	Checked bool
+	parent  *CodeCheck
+}
+
+// WithCodeCheck returns the context for the service, and the CodeCheck which records whether the service checks a passcode with it.
+// The CodeCheck of the context, if any, is its parent, and records the checks too, so that the decorators of the service can be stacked.
+func WithCodeCheck(ctx context.Context) (context.Context, *CodeCheck) {
+	c := &CodeCheck{}
+	c.parent, _ = ctx.Value(codeCheckKey{}).(*CodeCheck)
+	return context.WithValue(ctx, codeCheckKey{}, c), c
+}
+
+func checkingCode(ctx context.Context) {
+	c, _ := ctx.Value(codeCheckKey{}).(*CodeCheck)
+	for ; c != nil; c = c.parent {
+		c.Checked = true
+	}
+}
+
+// consumeCode deletes the code synchronously if the repository is a CodeConsumer, and returns false if the code was already used or replaced.
+// Otherwise, it deletes the code in the background, as before.
+func consumeCode(ctx context.Context, repository VerificationCodeRepository, id string, passcode string) (bool, error) {
+	if consumer, ok := repository.(CodeConsumer); ok {
+		count, err := consumer.Consume(ctx, id, passcode)
+		return count > 0 && err == nil, err
+	}
+	deleteCode(ctx, repository, id)
+	return true, nil
+}
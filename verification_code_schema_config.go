@@ -0,0 +1,7 @@
+package password
+
+type VerificationCodeSchemaConfig struct {
+	Id        string `mapstructure:"id"`
+	Passcode  string `mapstructure:"passcode"`
+	ExpiredAt string `mapstructure:"expired_at"`
+}
@@ -0,0 +1,144 @@
+package password
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	HeaderSignature = "X-Signature"
+	HeaderTimestamp = "X-Timestamp"
+)
+
+// WebhookNotifier is an AuditSink, which posts the events to the URLs after the operations.
+// The body is the JSON of the AuditEvent. The X-Signature header is "sha256=" and the hex HMAC-SHA256 of the X-Timestamp header, ".", and the body, with the secret.
+// Each delivery is retried with exponential backoff; the deliveries which keep failing are passed to DeadLetter.
+type WebhookNotifier struct {
+	Client     *http.Client
+	URLs       []string
+	Secret     []byte
+	Events     map[string]bool
+	Retries    int
+	Backoff    time.Duration
+	DeadLetter func(ctx context.Context, url string, event AuditEvent, err error)
+	wait       sync.WaitGroup
+}
+
+func NewWebhookNotifier(secret string, urls []string, options ...func(ctx context.Context, url string, event AuditEvent, err error)) *WebhookNotifier {
+	var deadLetter func(ctx context.Context, url string, event AuditEvent, err error)
+	if len(options) >= 1 {
+		deadLetter = options[0]
+	}
+	events := map[string]bool{
+		EventChangeCompleted: true,
+		EventResetRequested:  true,
+		EventResetCompleted:  true,
+		EventLockedOut:       true,
+	}
+	return &WebhookNotifier{
+		Client:     &http.Client{Timeout: 10 * time.Second},
+		URLs:       urls,
+		Secret:     []byte(secret),
+		Events:     events,
+		Retries:    5,
+		Backoff:    time.Second,
+		DeadLetter: deadLetter,
+	}
+}
+
+// Write does not wait for the deliveries, so that the operation is not delayed by the webhooks.
+func (n *WebhookNotifier) Write(ctx context.Context, event AuditEvent) error {
+	if n.Events != nil && !n.Events[event.Type] {
+		return nil
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	for _, url := range n.URLs {
+		n.wait.Add(1)
+		go func(url string) {
+			defer n.wait.Done()
+			n.deliver(url, event, body)
+		}(url)
+	}
+	return nil
+}
+
+// Wait waits for the pending deliveries, before the application stops.
+func (n *WebhookNotifier) Wait() {
+	n.wait.Wait()
+}
+
+func (n *WebhookNotifier) deliver(url string, event AuditEvent, body []byte) {
+	ctx := context.Background()
+	backoff := n.Backoff
+	var err error
+	for i := 0; i <= n.Retries; i++ {
+		if i > 0 {
+			time.Sleep(backoff)
+			backoff = backoff * 2
+		}
+		if err = n.Post(ctx, url, body); err == nil {
+			return
+		}
+	}
+	if n.DeadLetter != nil {
+		n.DeadLetter(ctx, url, event, err)
+	}
+}
+
+func (n *WebhookNotifier) Post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(n.Secret, timestamp, body))
+	res, err := n.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s responded with status %d", url, res.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of the timestamp, ".", and the body; the receivers use it to verify the signature.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// AuditSinks writes the events to all the sinks, such as a file and the webhooks, and returns the first error.
+type AuditSinks []AuditSink
+
+func NewAuditSinks(sinks ...AuditSink) AuditSinks {
+	return AuditSinks(sinks)
+}
+
+func (s AuditSinks) Write(ctx context.Context, event AuditEvent) error {
+	var err error
+	for _, sink := range s {
+		if er1 := sink.Write(ctx, event); er1 != nil && err == nil {
+			err = er1
+		}
+	}
+	return err
+}
@@ -0,0 +1,123 @@
+package password_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	p "github.com/core-go/password"
+)
+
+// webhook is a receiver which responds with the statuses in order, then with 200, and records the requests.
+type webhook struct {
+	mutex    sync.Mutex
+	statuses []int
+	bodies   [][]byte
+	headers  []http.Header
+	times    []time.Time
+}
+
+func (w *webhook) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.bodies = append(w.bodies, body)
+	w.headers = append(w.headers, req.Header.Clone())
+	w.times = append(w.times, time.Now())
+	status := http.StatusOK
+	if len(w.statuses) > 0 {
+		status, w.statuses = w.statuses[0], w.statuses[1:]
+	}
+	res.WriteHeader(status)
+}
+
+type deadLetter struct {
+	url   string
+	event p.AuditEvent
+	err   error
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    string
+		statuses []int
+		requests int
+		dead     bool
+	}{
+		{"Delivered", p.EventResetCompleted, nil, 1, false},
+		{"Retried", p.EventLockedOut, []int{503, 502}, 3, false},
+		{"DeadLetter", p.EventChangeCompleted, []int{500, 500, 500}, 3, true},
+		{"NotSubscribed", p.EventPolicyRejected, nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := &webhook{statuses: tt.statuses}
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+			var dead []deadLetter
+			n := p.NewWebhookNotifier("secret", []string{server.URL}, func(ctx context.Context, url string, event p.AuditEvent, err error) {
+				dead = append(dead, deadLetter{url: url, event: event, err: err})
+			})
+			n.Retries = 2
+			n.Backoff = 10 * time.Millisecond
+			event := p.AuditEvent{Type: tt.event, UserId: "u1", Time: time.Now()}
+			if err := n.Write(context.Background(), event); err != nil {
+				t.Fatal(err)
+			}
+			n.Wait()
+
+			if len(receiver.bodies) != tt.requests {
+				t.Fatalf("%d requests; want %d", len(receiver.bodies), tt.requests)
+			}
+			for i, body := range receiver.bodies {
+				h := receiver.headers[i]
+				mac := hmac.New(sha256.New, []byte("secret"))
+				mac.Write([]byte(h.Get(p.HeaderTimestamp) + "." + string(body)))
+				if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); h.Get(p.HeaderSignature) != want {
+					t.Errorf("%s = %q; want %q", p.HeaderSignature, h.Get(p.HeaderSignature), want)
+				}
+				var got p.AuditEvent
+				if err := json.Unmarshal(body, &got); err != nil || got.Type != tt.event || got.UserId != "u1" {
+					t.Errorf("body = %s, %v; want the event", body, err)
+				}
+			}
+			// the backoff doubles: 10ms, then 20ms
+			for i := 1; i < len(receiver.times); i++ {
+				if wait, min := receiver.times[i].Sub(receiver.times[i-1]), n.Backoff<<uint(i-1); wait < min {
+					t.Errorf("retry %d after %v; want at least %v", i, wait, min)
+				}
+			}
+			if !tt.dead {
+				if len(dead) != 0 {
+					t.Errorf("dead letters = %v; want none", dead)
+				}
+				return
+			}
+			if len(dead) != 1 || dead[0].url != server.URL || dead[0].event.Type != tt.event || dead[0].err == nil || !strings.Contains(dead[0].err.Error(), "500") {
+				t.Errorf("dead letters = %+v; want one with the status 500", dead)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	// the HMAC-SHA256 of "1700000000.{}" with the key "secret"
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1700000000.{}"))
+	if got, want := p.Sign([]byte("secret"), "1700000000", []byte("{}")), hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("Sign() = %s; want %s", got, want)
+	}
+	if p.Sign([]byte("secret"), "1700000000", []byte("{}")) == p.Sign([]byte("other"), "1700000000", []byte("{}")) {
+		t.Error("Sign() does not depend on the secret")
+	}
+}